@@ -14,8 +14,13 @@ import (
 	"backend/internal/database"
 	"backend/internal/handler"
 	"backend/internal/middleware"
+	"backend/internal/pubsub"
+	"backend/internal/redis"
 	"backend/internal/repository"
+	"backend/internal/seen"
 	"backend/internal/service"
+	"backend/internal/timeline"
+	"backend/internal/viewcount"
 
 	"github.com/labstack/echo/v4"
 	echoMiddleware "github.com/labstack/echo/v4/middleware"
@@ -32,29 +37,103 @@ func main() {
 		log.Fatalf("failed to connect to postgres: %v", err)
 	}
 
+	var rdb *redis.Client
+	if cfg.StreamBroker == "redis" || cfg.ViewStore == "redis" || cfg.TimelineStore == "redis" || cfg.SeenStore == "redis" {
+		rdb, err = redis.InitRedis(cfg)
+		if err != nil {
+			log.Fatalf("failed to connect to redis: %v", err)
+		}
+		defer rdb.Close()
+	}
+
+	var broker pubsub.Broker
+	switch cfg.StreamBroker {
+	case "redis":
+		broker = pubsub.NewRedisBroker(rdb)
+	default:
+		broker = pubsub.NewMemoryBroker()
+	}
+	defer broker.Close()
+
+	var viewStore viewcount.Store
+	switch cfg.ViewStore {
+	case "redis":
+		viewStore = viewcount.NewRedisStore(rdb, service.ViewDedupWindow)
+	default:
+		viewStore = viewcount.NewMemoryStore(service.ViewDedupWindow)
+	}
+
+	var timelineStore timeline.Store
+	switch cfg.TimelineStore {
+	case "redis":
+		timelineStore = timeline.NewRedisStore(rdb, service.TimelineMaxLen)
+	default:
+		timelineStore = timeline.NewPostgresStore(db, service.TimelineMaxLen)
+	}
+
+	var seenStore seen.Store
+	switch cfg.SeenStore {
+	case "redis":
+		seenStore = seen.NewRedisStore(rdb, service.SeenPostsTTL, service.SeenPostsMax)
+	default:
+		seenStore = seen.NewMemoryStore(service.SeenPostsTTL, service.SeenPostsMax)
+	}
+
 	userRepo := repository.NewUserRepository(db)
 	postRepo := repository.NewPostRepository(db)
 	commentRepo := repository.NewCommentRepository(db)
 	commentLikeRepo := repository.NewCommentLikeRepository(db)
 	feedRepo := repository.NewFeedRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	messageRepo := repository.NewMessageRepository(db)
+	bookmarkRepo := repository.NewBookmarkRepository(db)
+	analyticsRepo := repository.NewAnalyticsRepository(db)
+	pollRepo := repository.NewPollRepository(db)
+	storyRepo := repository.NewStoryRepository(db)
+	exploreRepo := repository.NewExploreRepository(db)
+	postViews := service.NewPostViewBuilder(postRepo, bookmarkRepo, pollRepo)
+	streamSvc := service.NewStreamService(broker, userRepo, postRepo)
+	analyticsSvc := service.NewAnalyticsService(analyticsRepo, postRepo)
+	viewSvc := service.NewViewService(viewStore, postRepo, analyticsSvc)
+	notificationSvc := service.NewNotificationService(notificationRepo, postRepo, commentRepo, streamSvc)
 	authSvc := service.NewAuthService(userRepo, cfg.JWTSecret)
-	userSvc := service.NewUserService(userRepo)
-	commentLikeSvc := service.NewCommentLikeService(commentLikeRepo)
-	commentSvc := service.NewCommentService(commentRepo, commentLikeRepo)
-	commentTreeSvc := service.NewCommentTreeService(commentRepo, commentLikeRepo)
+	timelineSvc := service.NewTimelineService(timelineStore, feedRepo, userRepo)
+	userSvc := service.NewUserService(userRepo, notificationSvc, analyticsSvc, timelineSvc)
+	commentLikeSvc := service.NewCommentLikeService(commentLikeRepo, commentRepo, postRepo, notificationSvc, streamSvc)
+	commentSvc := service.NewCommentService(commentRepo, commentLikeRepo, postRepo, notificationSvc, streamSvc, analyticsSvc, userRepo)
 
 	fileSvc := service.NewFileService("uploads", "/uploads/", 10*1024*1024, []string{"jpg", "jpeg", "png", "gif", "mp4"})
-	postSvc := service.NewPostService(postRepo, commentSvc, commentTreeSvc, fileSvc)
+	// вложения личных сообщений лежат вне uploads, который nginx раздаёт
+	// без авторизации, и отдаются только участникам беседы
+	messageFileSvc := service.NewFileService("private/messages", "/api/conversations/", 10*1024*1024, []string{"jpg", "jpeg", "png", "gif", "mp4"})
+	postSvc := service.NewPostService(postRepo, commentSvc, fileSvc, notificationSvc, streamSvc, postViews, viewSvc, analyticsSvc, timelineSvc)
+
+	postScheduler := service.NewPostScheduler(postRepo, streamSvc, timelineSvc)
+	pollSvc := service.NewPollService(pollRepo, postRepo)
 
-	feedSvc := service.NewFeedService(feedRepo)
+	feedSvc := service.NewFeedService(feedRepo, timelineSvc, seenStore, postViews, service.NewFeedScorer(cfg.FeedScorer), []byte(cfg.JWTSecret))
+	exploreSvc := service.NewExploreService(exploreRepo, postViews)
+	bookmarkSvc := service.NewBookmarkService(bookmarkRepo, postRepo, postViews, analyticsSvc)
+	messageSvc := service.NewMessageService(messageRepo, userRepo, messageFileSvc, streamSvc)
+	storySvc := service.NewStoryService(storyRepo, userRepo, fileSvc, messageSvc)
+	storyCleaner := service.NewStoryCleaner(storyRepo, fileSvc)
 	authHandler := handler.NewAuthHandler(authSvc)
 	userHandler := handler.NewUserHandler(userSvc)
 	postHandler := handler.NewPostHandler(postSvc, fileSvc)
 	commentHandler := handler.NewCommentHandler(commentSvc)
 	commentLikeHandler := handler.NewCommentLikeHandler(commentLikeSvc)
 	feedHandler := handler.NewFeedHandler(feedSvc)
+	exploreHandler := handler.NewExploreHandler(exploreSvc)
 	fileHandler := handler.NewFileHandler(fileSvc)
 	authCheckHandler := handler.NewAuthCheckHandler(userRepo)
+	notificationHandler := handler.NewNotificationHandler(notificationSvc)
+	streamHandler := handler.NewStreamHandler(streamSvc)
+	messageHandler := handler.NewMessageHandler(messageSvc)
+	bookmarkHandler := handler.NewBookmarkHandler(bookmarkSvc)
+	viewHandler := handler.NewViewHandler(viewSvc)
+	insightsHandler := handler.NewInsightsHandler(analyticsSvc)
+	pollHandler := handler.NewPollHandler(pollSvc)
+	storyHandler := handler.NewStoryHandler(storySvc)
 
 	e := echo.New()
 	e.Use(echoMiddleware.Logger())
@@ -67,6 +146,10 @@ func main() {
 	feedGroup.Use(middleware.JWT(cfg.JWTSecret))
 	feedGroup.GET("", feedHandler.Get)
 
+	exploreGroup := api.Group("/explore")
+	exploreGroup.Use(middleware.JWT(cfg.JWTSecret))
+	exploreGroup.GET("", exploreHandler.Get)
+
 	authGroup := api.Group("/auth")
 	authGroup.POST("/register", authHandler.Register)
 	authGroup.POST("/login", authHandler.Login)
@@ -80,9 +163,21 @@ func main() {
 	userGroup.GET("/me", userHandler.GetProfile)
 	userGroup.PATCH("/me", userHandler.Update)
 	userGroup.DELETE("/me", userHandler.Delete)
+	userGroup.GET("/me/insights", insightsHandler.Profile)
+	userGroup.GET("/me/close-friends", userHandler.CloseFriends)
+	userGroup.POST("/me/close-friends/:id", userHandler.AddCloseFriend)
+	userGroup.DELETE("/me/close-friends/:id", userHandler.RemoveCloseFriend)
+	userGroup.GET("/me/bookmarks", bookmarkHandler.List)
+	userGroup.GET("/me/collections", bookmarkHandler.ListCollections)
+	userGroup.POST("/me/collections", bookmarkHandler.CreateCollection)
+	userGroup.PATCH("/me/collections/:id", bookmarkHandler.RenameCollection)
+	userGroup.DELETE("/me/collections/:id", bookmarkHandler.DeleteCollection)
 	userGroup.GET("/search", userHandler.Search)
+	userGroup.GET("/blocked", userHandler.Blocked)
 	userGroup.POST("/:id/follow", userHandler.Follow)
 	userGroup.DELETE("/:id/follow", userHandler.Unfollow)
+	userGroup.POST("/:id/block", userHandler.Block)
+	userGroup.DELETE("/:id/block", userHandler.Unblock)
 	userGroup.GET("/:id/followers", userHandler.Followers)
 	userGroup.GET("/:id/following", userHandler.Following)
 	userGroup.POST("/avatar", fileHandler.Upload)
@@ -93,20 +188,92 @@ func main() {
 	postGroup.Use(middleware.JWT(cfg.JWTSecret))
 	postGroup.POST("", postHandler.Create)
 	postGroup.GET("/me", postHandler.MyPosts)
+	postGroup.GET("/drafts", postHandler.Drafts)
 	postGroup.GET("/user/:id", postHandler.UserPosts)
+	postGroup.POST("/impressions", viewHandler.Impressions)
 	postGroup.GET("/:id", postHandler.Get)
 	postGroup.PATCH("/:id", postHandler.Update)
 	postGroup.DELETE("/:id", postHandler.Delete)
+	postGroup.GET("/:id/insights", insightsHandler.Post)
+	postGroup.GET("/:id/revisions", postHandler.Revisions)
+	postGroup.POST("/:id/schedule", postHandler.Schedule)
+	postGroup.DELETE("/:id/schedule", postHandler.Unschedule)
+	postGroup.POST("/:id/publish", postHandler.Publish)
+	postGroup.POST("/:id/poll/vote", pollHandler.Vote)
+	postGroup.DELETE("/:id/poll/vote", pollHandler.Retract)
+	postGroup.GET("/:id/poll/voters", pollHandler.Voters)
 	postGroup.POST("/:id/files", postHandler.AddFiles)
 	postGroup.POST("/:id/like", postHandler.Like)
 	postGroup.DELETE("/:id/like", postHandler.Unlike)
+	postGroup.POST("/:id/reaction", postHandler.React)
+	postGroup.DELETE("/:id/reaction", postHandler.Unreact)
+	postGroup.GET("/:id/likes", postHandler.Likers)
+	postGroup.POST("/:id/bookmark", bookmarkHandler.Bookmark)
+	postGroup.POST("/:id/repost", postHandler.Repost)
+	postGroup.DELETE("/:id/repost", postHandler.Unrepost)
+	postGroup.DELETE("/:id/bookmark", bookmarkHandler.Unbookmark)
 	postGroup.GET("/:id/comments", commentHandler.GetTree)
 	postGroup.POST("/:id/comments", commentHandler.Add)
+	postGroup.POST("/:id/pinned-comment", commentHandler.Pin)
+	postGroup.DELETE("/:id/pinned-comment", commentHandler.Unpin)
+	postGroup.PATCH("/:id/comment-settings", commentHandler.Settings)
+	postGroup.PATCH("/comments/:comment_id", commentHandler.Edit)
+	postGroup.DELETE("/comments/:comment_id", commentHandler.Delete)
+	postGroup.GET("/comments/:comment_id/revisions", commentHandler.Revisions)
+	postGroup.GET("/comments/:comment_id/replies", commentHandler.Replies)
+	postGroup.POST("/comments/:comment_id/hide", commentHandler.Hide)
+	postGroup.DELETE("/comments/:comment_id/hide", commentHandler.Unhide)
 	postGroup.POST("/comments/:comment_id/like", commentLikeHandler.Like)
 	postGroup.DELETE("/comments/:comment_id/like", commentLikeHandler.Unlike)
+	postGroup.POST("/comments/:comment_id/reaction", commentLikeHandler.React)
+	postGroup.DELETE("/comments/:comment_id/reaction", commentLikeHandler.Unreact)
+	postGroup.GET("/comments/:comment_id/likes", commentLikeHandler.Likers)
+
+	storyGroup := api.Group("/stories")
+	storyGroup.Use(middleware.JWT(cfg.JWTSecret))
+	storyGroup.POST("", storyHandler.Create)
+	storyGroup.GET("/tray", storyHandler.Tray)
+	storyGroup.GET("/user/:id", storyHandler.UserStories)
+	storyGroup.DELETE("/:id", storyHandler.Delete)
+	storyGroup.POST("/:id/view", storyHandler.View)
+	storyGroup.GET("/:id/viewers", storyHandler.Viewers)
+	storyGroup.POST("/:id/reply", storyHandler.Reply)
+
+	notificationGroup := api.Group("/notifications")
+	notificationGroup.Use(middleware.JWT(cfg.JWTSecret))
+	notificationGroup.GET("", notificationHandler.List)
+	notificationGroup.GET("/unread-count", notificationHandler.UnreadCount)
+	notificationGroup.POST("/read-all", notificationHandler.MarkAllRead)
+	notificationGroup.POST("/:id/read", notificationHandler.MarkRead)
+
+	conversationGroup := api.Group("/conversations")
+	conversationGroup.Use(middleware.JWT(cfg.JWTSecret))
+	conversationGroup.GET("", messageHandler.ListConversations)
+	conversationGroup.POST("", messageHandler.CreateConversation)
+	conversationGroup.GET("/:id", messageHandler.GetConversation)
+	conversationGroup.GET("/:id/messages", messageHandler.ListMessages)
+	conversationGroup.POST("/:id/messages", messageHandler.Send)
+	conversationGroup.POST("/:id/read", messageHandler.MarkRead)
+	conversationGroup.DELETE("/:id/messages/:message_id", messageHandler.DeleteMessage)
+	conversationGroup.GET("/:id/files/:name", messageHandler.File)
+
+	streamGroup := api.Group("/stream")
+	streamGroup.Use(middleware.TokenFromQuery("access_token"))
+	streamGroup.Use(middleware.JWT(cfg.JWTSecret))
+	streamGroup.GET("", streamHandler.Stream)
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	go viewSvc.Run(ctx)
+	go analyticsSvc.Run(ctx)
+	go postScheduler.Run(ctx)
+	go storyCleaner.Run(ctx)
+	go timelineSvc.Run(ctx)
+	go exploreSvc.Run(ctx)
+	if store, ok := seenStore.(*seen.MemoryStore); ok {
+		go store.Run(ctx)
+	}
+
 	serverErr := make(chan error, 1)
 	go func() {
 		serverErr <- e.Start(":" + cfg.AppPort)
@@ -124,4 +291,17 @@ func main() {
 			log.Printf("server shutdown failed: %v", err)
 		}
 	}
+
+	// сбрасываем накопленные просмотры и дописываем ленты перед выходом
+	flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := timelineSvc.Flush(flushCtx); err != nil {
+		log.Printf("timeline flush failed: %v", err)
+	}
+	if err := viewSvc.Flush(flushCtx); err != nil {
+		log.Printf("views flush failed: %v", err)
+	}
+	if err := analyticsSvc.Flush(flushCtx); err != nil {
+		log.Printf("analytics flush failed: %v", err)
+	}
 }
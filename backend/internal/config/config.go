@@ -9,6 +9,22 @@ type Config struct {
 	DatabaseDSN string
 	JWTSecret   string
 	RedisAddr   string
+
+	// StreamBroker selects the realtime pub/sub backend: "memory" for a single
+	// replica, "redis" to fan events out across replicas.
+	StreamBroker string
+	// ViewStore selects where post views are buffered before being flushed to
+	// Postgres: "memory" or "redis".
+	ViewStore string
+	// FeedScorer selects how the feed is ranked: "weighted" or "recency".
+	// Switching it is how scorers are compared.
+	FeedScorer string
+	// TimelineStore selects where home timelines are materialized:
+	// "postgres" or "redis".
+	TimelineStore string
+	// SeenStore selects where the posts served to each user are remembered:
+	// "memory" or "redis".
+	SeenStore string
 }
 
 func Load() (*Config, error) {
@@ -17,6 +33,12 @@ func Load() (*Config, error) {
 		DatabaseDSN: getEnv("DATABASE_DSN", "postgres://postgres:postgres@db:5432/backend?sslmode=disable"),
 		JWTSecret:   getEnv("JWT_SECRET", "supersecretkey"),
 		RedisAddr:   getEnv("REDIS_ADDR", "redis:6379"),
+
+		StreamBroker:  getEnv("STREAM_BROKER", "memory"),
+		ViewStore:     getEnv("VIEW_STORE", "memory"),
+		FeedScorer:    getEnv("FEED_SCORER", "weighted"),
+		TimelineStore: getEnv("TIMELINE_STORE", "postgres"),
+		SeenStore:     getEnv("SEEN_STORE", "memory"),
 	}, nil
 }
 
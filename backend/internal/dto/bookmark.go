@@ -0,0 +1,30 @@
+package dto
+
+import "time"
+
+type BookmarkRequest struct {
+	CollectionID *uint `json:"collection_id"`
+}
+
+type BookmarkResponse struct {
+	PostID       uint  `json:"post_id"`
+	Bookmarked   bool  `json:"bookmarked"`
+	CollectionID *uint `json:"collection_id,omitempty"`
+}
+
+type BookmarkListResponse struct {
+	Posts      []PostResponse `json:"posts"`
+	NextCursor *string        `json:"next_cursor,omitempty"`
+	HasMore    bool           `json:"has_more"`
+}
+
+type CollectionRequest struct {
+	Name string `json:"name"`
+}
+
+type CollectionDTO struct {
+	ID             uint      `json:"id"`
+	Name           string    `json:"name"`
+	BookmarksCount int64     `json:"bookmarks_count"`
+	CreatedAt      time.Time `json:"created_at"`
+}
@@ -7,17 +7,62 @@ type AddCommentRequest struct {
 	ParentID *uint  `json:"parent_id,omitempty"`
 }
 
+type EditCommentRequest struct {
+	Text string `json:"text"`
+}
+
+type PinCommentRequest struct {
+	CommentID uint `json:"comment_id"`
+}
+
+type CommentSettingsRequest struct {
+	CommentPolicy string `json:"comment_policy"`
+}
+
+type CommentDeletedEvent struct {
+	PostID    uint `json:"post_id"`
+	CommentID uint `json:"comment_id"`
+	// Placeholder: the comment had replies and stays as "[deleted]".
+	Placeholder bool `json:"placeholder"`
+}
+
 type CommentDTO struct {
-	ID        uint         `json:"id"`
-	PostID    uint         `json:"post_id"`
-	UserID    uint         `json:"user_id"`
-	ParentID  *uint        `json:"parent_id,omitempty"`
-	Text      string       `json:"text"`
-	Likes     int          `json:"likes"`
-	IsLiked   bool         `json:"is_liked"`
-	User      UserShortDTO `json:"user"`
-	Replies   []CommentDTO `json:"replies,omitempty"`
-	CreatedAt time.Time    `json:"created_at"`
+	ID         uint           `json:"id"`
+	PostID     uint           `json:"post_id"`
+	UserID     uint           `json:"user_id"`
+	ParentID   *uint          `json:"parent_id,omitempty"`
+	Text       string         `json:"text"`
+	Likes      int            `json:"likes"`
+	IsLiked    bool           `json:"is_liked"`
+	Reactions  map[string]int `json:"reactions"`
+	MyReaction *string        `json:"my_reaction,omitempty"`
+	User       UserShortDTO   `json:"user"`
+	CreatedAt  time.Time      `json:"created_at"`
+	EditedAt   *time.Time     `json:"edited_at,omitempty"`
+	IsDeleted  bool           `json:"is_deleted"`
+	IsPinned   bool           `json:"is_pinned"`
+	// IsHidden is only reported to the post author.
+	IsHidden bool `json:"is_hidden"`
+
+	// Replies holds the first direct replies; RepliesCursor loads the rest
+	// from /comments/:comment_id/replies.
+	Replies       []CommentDTO `json:"replies,omitempty"`
+	RepliesCount  int          `json:"replies_count"`
+	RepliesCursor *string      `json:"replies_cursor,omitempty"`
+}
+
+type CommentRevisionDTO struct {
+	ID        uint   `json:"id"`
+	Text      string `json:"text"`
+	CreatedAt string `json:"created_at"`
+}
+
+// CommentRevisionsResponse lists versions newest first; an unedited comment
+// has no revisions.
+type CommentRevisionsResponse struct {
+	CommentID uint                 `json:"comment_id"`
+	EditedAt  *string              `json:"edited_at,omitempty"`
+	Revisions []CommentRevisionDTO `json:"revisions"`
 }
 
 type UserShortDTO struct {
@@ -27,30 +72,36 @@ type UserShortDTO struct {
 }
 
 type CommentListResponse struct {
-	Comments []CommentDTO `json:"comments"`
-	Page     int          `json:"page"`
-	PageSize int          `json:"page_size"`
-	Total    int64        `json:"total"`
-}
+	Comments   []CommentDTO `json:"comments"`
+	NextCursor *string      `json:"next_cursor,omitempty"`
+	HasMore    bool         `json:"has_more"`
 
-type CommentTree struct {
-	ID        uint          `json:"id"`
-	PostID    uint          `json:"post_id"`
-	UserID    uint          `json:"user_id"`
-	ParentID  *uint         `json:"parent_id"`
-	Text      string        `json:"text"`
-	Likes     int           `json:"likes"`
-	IsLiked   bool          `json:"is_liked"`
-	CreatedAt time.Time     `json:"created_at"`
-	User      UserShortDTO  `json:"user"`
-	Replies   []CommentTree `json:"replies"`
+	CommentPolicy string `json:"comment_policy"`
+	CanComment    bool   `json:"can_comment"`
 }
 
 type PostWithCommentsResponse struct {
-	ID          uint           `json:"id"`
-	Description *string        `json:"description"`
-	Files       []FileResponse `json:"files"`
-	LikesCount  int            `json:"likes_count"`
-	IsLiked     bool           `json:"is_liked"`
-	Comments    []CommentTree  `json:"comments"`
+	ID             uint           `json:"id"`
+	Description    *string        `json:"description"`
+	Files          []FileResponse `json:"files"`
+	LikesCount     int            `json:"likes_count"`
+	IsLiked        bool           `json:"is_liked"`
+	Reactions      map[string]int `json:"reactions"`
+	MyReaction     *string        `json:"my_reaction,omitempty"`
+	IsBookmarked   bool           `json:"is_bookmarked"`
+	RepostsCount   int            `json:"reposts_count"`
+	IsReposted     bool           `json:"is_reposted"`
+	ViewsCount     int            `json:"views_count"`
+	EditedAt       *string        `json:"edited_at,omitempty"`
+	Status         string         `json:"status"`
+	PublishAt      *string        `json:"publish_at,omitempty"`
+	Visibility     string         `json:"visibility"`
+	Comments       []CommentDTO   `json:"comments"`
+	CommentsCursor *string        `json:"comments_cursor,omitempty"`
+	CommentPolicy  string         `json:"comment_policy"`
+	CanComment     bool           `json:"can_comment"`
+
+	QuotedPost       *QuotedPostDTO `json:"quoted_post,omitempty"`
+	QuoteUnavailable bool           `json:"quote_unavailable,omitempty"`
+	Poll             *PollDTO       `json:"poll,omitempty"`
 }
@@ -11,8 +11,28 @@ type FeedPostItem struct {
 	CreatedAt   string         `json:"created_at"`
 }
 
+// FeedReasonDTO says why a recommended post is in the feed: Type is
+// liked_by_following, same_major, same_grade or trending, and Detail is the
+// followee's nickname or the shared major or grade.
+type FeedReasonDTO struct {
+	Type   string `json:"type"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// FeedResponse is a page of the feed. CaughtUp is set on the page where the
+// following posts published since the previous visit run out, for the "You're
+// all caught up" marker.
 type FeedResponse struct {
 	Posts      []PostResponse `json:"posts"`
 	NextCursor *string        `json:"next_cursor,omitempty"`
 	HasMore    bool           `json:"has_more"`
+	CaughtUp   bool           `json:"caught_up"`
+}
+
+// ExploreResponse is a page of trending posts; Tab is empty for all of them.
+type ExploreResponse struct {
+	Tab        string         `json:"tab,omitempty"`
+	Posts      []PostResponse `json:"posts"`
+	NextCursor *string        `json:"next_cursor,omitempty"`
+	HasMore    bool           `json:"has_more"`
 }
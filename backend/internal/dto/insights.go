@@ -0,0 +1,43 @@
+package dto
+
+import "time"
+
+type InsightsCounters struct {
+	Views         int64 `json:"views"`
+	UniqueViewers int64 `json:"unique_viewers"`
+	Likes         int64 `json:"likes"`
+	Comments      int64 `json:"comments"`
+	Bookmarks     int64 `json:"bookmarks"`
+	FollowsGained int64 `json:"follows_gained"`
+}
+
+type InsightsPoint struct {
+	Time time.Time `json:"time"`
+	InsightsCounters
+}
+
+type PostInsightsResponse struct {
+	PostID   uint             `json:"post_id"`
+	Interval string           `json:"interval"`
+	Since    time.Time        `json:"since"`
+	Totals   InsightsCounters `json:"totals"`
+	Series   []InsightsPoint  `json:"series"`
+}
+
+type TopPostDTO struct {
+	PostID   uint  `json:"post_id"`
+	Views    int64 `json:"views"`
+	Likes    int64 `json:"likes"`
+	Comments int64 `json:"comments"`
+}
+
+// ProfileInsightsResponse sums up all of the author's posts. Totals
+// unique_viewers counts distinct people, so it can be less than the sum of
+// the series.
+type ProfileInsightsResponse struct {
+	Interval string           `json:"interval"`
+	Since    time.Time        `json:"since"`
+	Totals   InsightsCounters `json:"totals"`
+	Series   []InsightsPoint  `json:"series"`
+	TopPosts []TopPostDTO     `json:"top_posts"`
+}
@@ -0,0 +1,67 @@
+package dto
+
+import "time"
+
+type CreateConversationRequest struct {
+	UserIDs []uint  `json:"user_ids"`
+	Title   *string `json:"title"`
+}
+
+type SendMessageRequest struct {
+	Text string `json:"text" form:"text"`
+}
+
+type MarkReadRequest struct {
+	// MessageID is the newest message the client has shown; 0 means "all".
+	MessageID uint `json:"message_id"`
+}
+
+type ConversationMemberDTO struct {
+	User              UserShortDTO `json:"user"`
+	LastReadMessageID uint         `json:"last_read_message_id"`
+}
+
+type ConversationDTO struct {
+	ID            uint                    `json:"id"`
+	IsGroup       bool                    `json:"is_group"`
+	Title         *string                 `json:"title,omitempty"`
+	Members       []ConversationMemberDTO `json:"members"`
+	LastMessage   *MessageDTO             `json:"last_message,omitempty"`
+	UnreadCount   int64                   `json:"unread_count"`
+	LastMessageAt time.Time               `json:"last_message_at"`
+	CreatedAt     time.Time               `json:"created_at"`
+}
+
+type ConversationListResponse struct {
+	Conversations []ConversationDTO `json:"conversations"`
+	NextCursor    *string           `json:"next_cursor,omitempty"`
+	HasMore       bool              `json:"has_more"`
+}
+
+type MessageDTO struct {
+	ID             uint           `json:"id"`
+	ConversationID uint           `json:"conversation_id"`
+	Sender         UserShortDTO   `json:"sender"`
+	Text           string         `json:"text"`
+	Files          []FileResponse `json:"files"`
+	StoryID        *uint          `json:"story_id,omitempty"`
+	IsDeleted      bool           `json:"is_deleted"`
+	CreatedAt      time.Time      `json:"created_at"`
+}
+
+type MessageListResponse struct {
+	Messages   []MessageDTO `json:"messages"`
+	NextCursor *string      `json:"next_cursor,omitempty"`
+	HasMore    bool         `json:"has_more"`
+}
+
+type MessageReadEvent struct {
+	ConversationID uint `json:"conversation_id"`
+	UserID         uint `json:"user_id"`
+	MessageID      uint `json:"message_id"`
+}
+
+type MessageDeletedEvent struct {
+	ConversationID uint `json:"conversation_id"`
+	MessageID      uint `json:"message_id"`
+}
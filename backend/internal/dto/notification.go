@@ -0,0 +1,23 @@
+package dto
+
+import "time"
+
+type NotificationDTO struct {
+	ID          uint         `json:"id"`
+	Type        string       `json:"type"`
+	Actor       UserShortDTO `json:"actor"`
+	ActorsCount int          `json:"actors_count"`
+	PostID      *uint        `json:"post_id,omitempty"`
+	CommentID   *uint        `json:"comment_id,omitempty"`
+	Message     string       `json:"message"`
+	IsRead      bool         `json:"is_read"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+type NotificationListResponse struct {
+	Notifications []NotificationDTO `json:"notifications"`
+	UnreadCount   int64             `json:"unread_count"`
+	NextCursor    *string           `json:"next_cursor,omitempty"`
+	HasMore       bool              `json:"has_more"`
+}
@@ -0,0 +1,37 @@
+package dto
+
+// CreatePollRequest is attached to a new post. In multipart requests it is
+// sent JSON-encoded in the "poll" field.
+type CreatePollRequest struct {
+	Options   []string `json:"options"`
+	Multiple  bool     `json:"multiple"`
+	Anonymous bool     `json:"anonymous"`
+	EndsAt    *string  `json:"ends_at"`
+}
+
+type PollVoteRequest struct {
+	OptionIDs []uint `json:"option_ids"`
+}
+
+type PollOptionDTO struct {
+	ID    uint   `json:"id"`
+	Text  string `json:"text"`
+	Votes int    `json:"votes"`
+}
+
+type PollDTO struct {
+	ID          uint            `json:"id"`
+	Multiple    bool            `json:"multiple"`
+	Anonymous   bool            `json:"anonymous"`
+	EndsAt      *string         `json:"ends_at,omitempty"`
+	Closed      bool            `json:"closed"`
+	TotalVoters int             `json:"total_voters"`
+	Options     []PollOptionDTO `json:"options"`
+	MyVotes     []uint          `json:"my_votes"`
+}
+
+type PollVotersResponse struct {
+	Voters     []UserShortDTO `json:"voters"`
+	NextCursor *string        `json:"next_cursor,omitempty"`
+	HasMore    bool           `json:"has_more"`
+}
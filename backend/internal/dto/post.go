@@ -1,32 +1,105 @@
 package dto
 
 type CreatePostRequest struct {
-	Description *string `json:"description"`
+	Description  *string `json:"description"`
+	QuotedPostID *uint   `json:"quoted_post_id"`
+	Status       string  `json:"status"`
+	PublishAt    *string `json:"publish_at"`
+	Visibility   string  `json:"visibility"`
+
+	Poll *CreatePollRequest `json:"poll"`
+}
+
+// SchedulePostRequest sets when a draft gets published (RFC3339).
+type SchedulePostRequest struct {
+	PublishAt string `json:"publish_at"`
 }
 
 type UpdatePostRequest struct {
 	Description *string `json:"description"`
+	Visibility  *string `json:"visibility"`
 }
 
 type PostResponse struct {
+	ID           uint           `json:"id"`
+	UserID       uint           `json:"user_id"`
+	User         PostAuthorDTO  `json:"user"`
+	Description  *string        `json:"description,omitempty"`
+	Files        []FileResponse `json:"files"`
+	LikesCount   int            `json:"likes_count"`
+	Comments     int            `json:"comments"`
+	IsLiked      bool           `json:"is_liked"`
+	Reactions    map[string]int `json:"reactions"`
+	MyReaction   *string        `json:"my_reaction,omitempty"`
+	IsBookmarked bool           `json:"is_bookmarked"`
+	RepostsCount int            `json:"reposts_count"`
+	IsReposted   bool           `json:"is_reposted"`
+	ViewsCount   int            `json:"views_count"`
+	CreatedAt    string         `json:"created_at"`
+	EditedAt     *string        `json:"edited_at,omitempty"`
+	Status       string         `json:"status"`
+	PublishAt    *string        `json:"publish_at,omitempty"`
+	Visibility   string         `json:"visibility"`
+
+	// RepostedBy is set on feed items that got there through a repost,
+	// Reason on recommended ones.
+	RepostedBy       *PostAuthorDTO `json:"reposted_by,omitempty"`
+	Reason           *FeedReasonDTO `json:"reason,omitempty"`
+	QuotedPost       *QuotedPostDTO `json:"quoted_post,omitempty"`
+	QuoteUnavailable bool           `json:"quote_unavailable,omitempty"`
+	Poll             *PollDTO       `json:"poll,omitempty"`
+}
+
+// QuotedPostDTO is the embedded original of a quote post.
+type QuotedPostDTO struct {
 	ID          uint           `json:"id"`
-	UserID      uint           `json:"user_id"`
 	User        PostAuthorDTO  `json:"user"`
 	Description *string        `json:"description,omitempty"`
 	Files       []FileResponse `json:"files"`
-	LikesCount  int            `json:"likes_count"`
-	Comments    int            `json:"comments"`
-	IsLiked     bool           `json:"is_liked"`
 	CreatedAt   string         `json:"created_at"`
 }
 
+// ImpressionsRequest reports posts that were shown to the user in a feed.
+type ImpressionsRequest struct {
+	PostIDs []uint `json:"post_ids"`
+}
+
+type PostRevisionDTO struct {
+	ID          uint    `json:"id"`
+	Description *string `json:"description,omitempty"`
+	CreatedAt   string  `json:"created_at"`
+}
+
+// PostRevisionsResponse lists versions newest first; an unedited post has no
+// revisions.
+type PostRevisionsResponse struct {
+	PostID    uint              `json:"post_id"`
+	EditedAt  *string           `json:"edited_at,omitempty"`
+	Revisions []PostRevisionDTO `json:"revisions"`
+}
+
+type RepostResponse struct {
+	PostID       uint `json:"post_id"`
+	Reposted     bool `json:"reposted"`
+	RepostsCount int  `json:"reposts_count"`
+}
+
 type FileResponse struct {
 	ID  uint   `json:"id"`
 	URL string `json:"url"`
 }
 
+// CreatePostRequestMultipart: status is "draft", "scheduled" (with
+// publish_at) or empty to publish right away.
 type CreatePostRequestMultipart struct {
-	Description *string `form:"description"`
+	Description  *string `form:"description"`
+	QuotedPostID *uint   `form:"quoted_post_id"`
+	Status       string  `form:"status"`
+	PublishAt    *string `form:"publish_at"`
+	Visibility   string  `form:"visibility"`
+
+	// Poll is a JSON-encoded CreatePollRequest.
+	Poll string `form:"poll"`
 }
 
 // PostAuthorDTO contains only the fields the feed needs to render author info.
@@ -0,0 +1,26 @@
+package dto
+
+import "time"
+
+type ReactionRequest struct {
+	Reaction string `json:"reaction"`
+}
+
+// ReactionResponse is returned after reacting to a post or comment.
+type ReactionResponse struct {
+	Reactions  map[string]int `json:"reactions"`
+	MyReaction *string        `json:"my_reaction,omitempty"`
+}
+
+type LikerDTO struct {
+	User        UserShortDTO `json:"user"`
+	Reaction    string       `json:"reaction"`
+	IsFollowing bool         `json:"is_following"`
+	LikedAt     time.Time    `json:"liked_at"`
+}
+
+type LikersResponse struct {
+	Likers     []LikerDTO `json:"likers"`
+	NextCursor *string    `json:"next_cursor,omitempty"`
+	HasMore    bool       `json:"has_more"`
+}
@@ -0,0 +1,54 @@
+package dto
+
+import "time"
+
+type CreateStoryRequestMultipart struct {
+	Caption string `form:"caption"`
+}
+
+type StoryReplyRequest struct {
+	Text string `json:"text"`
+}
+
+type StoryDTO struct {
+	ID           uint         `json:"id"`
+	User         UserShortDTO `json:"user"`
+	MediaURL     string       `json:"media_url"`
+	MediaType    string       `json:"media_type"`
+	Caption      string       `json:"caption"`
+	Seen         bool         `json:"seen"`
+	ViewersCount *int         `json:"viewers_count,omitempty"`
+	ExpiresAt    time.Time    `json:"expires_at"`
+	CreatedAt    time.Time    `json:"created_at"`
+}
+
+type StoryTrayItemDTO struct {
+	User         UserShortDTO `json:"user"`
+	StoriesCount int          `json:"stories_count"`
+	HasUnseen    bool         `json:"has_unseen"`
+	LatestAt     time.Time    `json:"latest_at"`
+}
+
+type StoryTrayResponse struct {
+	// Own is the caller's live stories, shown before the tray.
+	Own   []StoryDTO         `json:"own"`
+	Users []StoryTrayItemDTO `json:"users"`
+}
+
+type StoryViewerDTO struct {
+	User     UserShortDTO `json:"user"`
+	ViewedAt time.Time    `json:"viewed_at"`
+}
+
+type StoryViewersResponse struct {
+	Viewers    []StoryViewerDTO `json:"viewers"`
+	NextCursor *string          `json:"next_cursor,omitempty"`
+	HasMore    bool             `json:"has_more"`
+}
+
+// StoryReplyResponse: a reply is delivered as a direct message; when the
+// author's DM settings don't allow one it is refused with 403.
+type StoryReplyResponse struct {
+	DeliveredAs string      `json:"delivered_as"`
+	Message     *MessageDTO `json:"message,omitempty"`
+}
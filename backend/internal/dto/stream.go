@@ -0,0 +1,19 @@
+package dto
+
+type PostLikesEvent struct {
+	PostID     uint           `json:"post_id"`
+	LikesCount int            `json:"likes_count"`
+	Reactions  map[string]int `json:"reactions"`
+}
+
+type CommentLikesEvent struct {
+	PostID     uint           `json:"post_id"`
+	CommentID  uint           `json:"comment_id"`
+	LikesCount int            `json:"likes_count"`
+	Reactions  map[string]int `json:"reactions"`
+}
+
+type FeedItemEvent struct {
+	PostID uint `json:"post_id"`
+	UserID uint `json:"user_id"`
+}
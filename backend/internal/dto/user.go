@@ -8,6 +8,8 @@ type UpdateUserDTO struct {
 	Major       *string `json:"major"`
 	City        *string `json:"city"`
 	Description *string `json:"description"`
+
+	DMFollowingOnly *bool `json:"dm_following_only"`
 }
 
 type UserResponse struct {
@@ -23,6 +25,8 @@ type UserResponse struct {
 	City        *string `json:"city"`
 	Description *string `json:"description"`
 
+	DMFollowingOnly bool `json:"dm_following_only"`
+
 	PostsCount     int `json:"posts_count"`
 	FollowersCount int `json:"followers_count"`
 	FollowingCount int `json:"following_count"`
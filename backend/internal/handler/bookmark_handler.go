@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"backend/internal/dto"
+	"backend/internal/repository"
+	"backend/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type BookmarkHandler struct {
+	svc service.BookmarkService
+}
+
+func NewBookmarkHandler(s service.BookmarkService) *BookmarkHandler {
+	return &BookmarkHandler{svc: s}
+}
+
+func bookmarkErrorStatus(err error) int {
+	if errors.Is(err, repository.ErrNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
+
+func (h *BookmarkHandler) Bookmark(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	postID, err := parseIDParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid post id")
+	}
+
+	// тело необязательное: без него пост просто сохраняется без коллекции
+	var req dto.BookmarkRequest
+	if c.Request().ContentLength > 0 {
+		if err := bindJSON(c, &req); err != nil {
+			return respondError(c, http.StatusBadRequest, err.Error())
+		}
+	}
+
+	resp, err := h.svc.Bookmark(userID, postID, req)
+	if err != nil {
+		return respondError(c, bookmarkErrorStatus(err), err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, resp)
+}
+
+func (h *BookmarkHandler) Unbookmark(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	postID, err := parseIDParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid post id")
+	}
+
+	resp, err := h.svc.Unbookmark(userID, postID)
+	if err != nil {
+		return respondError(c, bookmarkErrorStatus(err), err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, resp)
+}
+
+func (h *BookmarkHandler) List(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+
+	var collectionID *uint
+	if raw := c.QueryParam("collection_id"); raw != "" {
+		v, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return respondError(c, http.StatusBadRequest, "invalid collection_id")
+		}
+		id := uint(v)
+		collectionID = &id
+	}
+
+	resp, err := h.svc.List(userID, collectionID, limit, c.QueryParam("cursor"))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCursor) || errors.Is(err, repository.ErrNotFound) {
+			return respondError(c, bookmarkErrorStatus(err), err.Error())
+		}
+		return respondError(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, resp)
+}
+
+func (h *BookmarkHandler) ListCollections(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	resp, err := h.svc.ListCollections(userID)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, resp)
+}
+
+func (h *BookmarkHandler) CreateCollection(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	var req dto.CollectionRequest
+	if err := bindJSON(c, &req); err != nil {
+		return respondError(c, http.StatusBadRequest, err.Error())
+	}
+
+	resp, err := h.svc.CreateCollection(userID, req)
+	if err != nil {
+		return respondError(c, bookmarkErrorStatus(err), err.Error())
+	}
+
+	return respondJSON(c, http.StatusCreated, resp)
+}
+
+func (h *BookmarkHandler) RenameCollection(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid id")
+	}
+
+	var req dto.CollectionRequest
+	if err := bindJSON(c, &req); err != nil {
+		return respondError(c, http.StatusBadRequest, err.Error())
+	}
+
+	resp, err := h.svc.RenameCollection(userID, id, req)
+	if err != nil {
+		return respondError(c, bookmarkErrorStatus(err), err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, resp)
+}
+
+func (h *BookmarkHandler) DeleteCollection(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid id")
+	}
+
+	if err := h.svc.DeleteCollection(userID, id); err != nil {
+		return respondError(c, bookmarkErrorStatus(err), err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, echo.Map{"message": "collection deleted"})
+}
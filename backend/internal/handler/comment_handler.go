@@ -1,9 +1,12 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"backend/internal/dto"
+	"backend/internal/repository"
 	"backend/internal/service"
 
 	"github.com/labstack/echo/v4"
@@ -34,11 +37,15 @@ func (h *CommentHandler) Add(c echo.Context) error {
 		return respondError(c, http.StatusBadRequest, err.Error())
 	}
 
-	if err := h.svc.AddComment(postID, userID, req); err != nil {
-		return respondError(c, http.StatusBadRequest, err.Error())
+	comment, err := h.svc.AddComment(postID, userID, req)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return respondError(c, http.StatusNotFound, "post not found")
+		}
+		return respondError(c, commentErrorStatus(err), err.Error())
 	}
 
-	return respondJSON(c, http.StatusOK, echo.Map{"message": "comment added"})
+	return respondJSON(c, http.StatusCreated, comment)
 }
 
 func (h *CommentHandler) GetTree(c echo.Context) error {
@@ -49,13 +56,216 @@ func (h *CommentHandler) GetTree(c echo.Context) error {
 		return respondError(c, http.StatusBadRequest, "invalid id")
 	}
 
-	page := 1
-	limit := 10
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	boost, _ := strconv.ParseBool(c.QueryParam("boost"))
+
+	resp, err := h.svc.ListThreads(postID, userID, c.QueryParam("sort"), boost, limit, c.QueryParam("cursor"))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCursor) || errors.Is(err, service.ErrInvalidCommentSort) {
+			return respondError(c, http.StatusBadRequest, err.Error())
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			return respondError(c, http.StatusNotFound, "post not found")
+		}
+		return respondError(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, resp)
+}
+
+func (h *CommentHandler) Replies(c echo.Context) error {
+	userID, _ := GetUserIDFromContext(c)
+
+	commentID, err := parseIDParam(c, "comment_id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid id")
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
 
-	tree, err := h.svc.GetCommentsTree(postID, userID, page, limit)
+	resp, err := h.svc.ListReplies(commentID, userID, limit, c.QueryParam("cursor"))
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidCursor) {
+			return respondError(c, http.StatusBadRequest, err.Error())
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			return respondError(c, http.StatusNotFound, "comment not found")
+		}
 		return respondError(c, http.StatusInternalServerError, err.Error())
 	}
 
-	return respondJSON(c, http.StatusOK, tree)
+	return respondJSON(c, http.StatusOK, resp)
+}
+
+func commentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, repository.ErrNotFound),
+		errors.Is(err, service.ErrParentNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrCommentEmpty),
+		errors.Is(err, service.ErrCommentTooLong),
+		errors.Is(err, service.ErrParentOtherPost),
+		errors.Is(err, service.ErrParentDeleted):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, service.ErrForbidden),
+		errors.Is(err, service.ErrCommentsOff),
+		errors.Is(err, service.ErrCommentsFollowersOnly):
+		return http.StatusForbidden
+	}
+	return http.StatusBadRequest
+}
+
+func (h *CommentHandler) Edit(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	commentID, err := parseIDParam(c, "comment_id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid id")
+	}
+
+	var req dto.EditCommentRequest
+	if err := bindJSON(c, &req); err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid json")
+	}
+
+	comment, err := h.svc.EditComment(commentID, userID, req)
+	if err != nil {
+		return respondError(c, commentErrorStatus(err), err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, comment)
+}
+
+func (h *CommentHandler) Revisions(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	commentID, err := parseIDParam(c, "comment_id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid id")
+	}
+
+	resp, err := h.svc.GetRevisions(commentID, userID)
+	if err != nil {
+		return respondError(c, commentErrorStatus(err), err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, resp)
+}
+
+func (h *CommentHandler) Delete(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	commentID, err := parseIDParam(c, "comment_id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid id")
+	}
+
+	if err := h.svc.DeleteComment(commentID, userID); err != nil {
+		return respondError(c, commentErrorStatus(err), err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, echo.Map{"message": "deleted"})
+}
+
+func (h *CommentHandler) Pin(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	postID, err := parseIDParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid id")
+	}
+
+	var req dto.PinCommentRequest
+	if err := bindJSON(c, &req); err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid json")
+	}
+
+	if err := h.svc.PinComment(postID, userID, req.CommentID); err != nil {
+		return respondError(c, commentErrorStatus(err), err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, echo.Map{"message": "pinned"})
+}
+
+func (h *CommentHandler) Unpin(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	postID, err := parseIDParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid id")
+	}
+
+	if err := h.svc.UnpinComment(postID, userID); err != nil {
+		return respondError(c, commentErrorStatus(err), err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, echo.Map{"message": "unpinned"})
+}
+
+func (h *CommentHandler) Settings(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	postID, err := parseIDParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid id")
+	}
+
+	var req dto.CommentSettingsRequest
+	if err := bindJSON(c, &req); err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid json")
+	}
+
+	if err := h.svc.SetCommentPolicy(postID, userID, req); err != nil {
+		return respondError(c, commentErrorStatus(err), err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, echo.Map{"message": "updated"})
+}
+
+func (h *CommentHandler) Hide(c echo.Context) error {
+	return h.setHidden(c, true)
+}
+
+func (h *CommentHandler) Unhide(c echo.Context) error {
+	return h.setHidden(c, false)
+}
+
+func (h *CommentHandler) setHidden(c echo.Context, hidden bool) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	commentID, err := parseIDParam(c, "comment_id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid id")
+	}
+
+	if err := h.svc.SetCommentHidden(commentID, userID, hidden); err != nil {
+		return respondError(c, commentErrorStatus(err), err.Error())
+	}
+
+	msg := "hidden"
+	if !hidden {
+		msg = "unhidden"
+	}
+	return respondJSON(c, http.StatusOK, echo.Map{"message": msg})
 }
@@ -1,8 +1,12 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
+	"backend/internal/dto"
+	"backend/internal/repository"
 	"backend/internal/service"
 
 	"github.com/labstack/echo/v4"
@@ -29,6 +33,9 @@ func (h *CommentLikeHandler) Like(c echo.Context) error {
 
 	resp, err := h.svc.Like(commentID, userID)
 	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return respondError(c, http.StatusNotFound, "comment not found")
+		}
 		return respondError(c, http.StatusBadRequest, err.Error())
 	}
 
@@ -48,6 +55,84 @@ func (h *CommentLikeHandler) Unlike(c echo.Context) error {
 
 	resp, err := h.svc.Unlike(commentID, userID)
 	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return respondError(c, http.StatusNotFound, "comment not found")
+		}
+		return respondError(c, http.StatusBadRequest, err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, resp)
+}
+
+// React sets or changes the caller's reaction: {"reaction": "love"}.
+func (h *CommentLikeHandler) React(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	commentID, err := parseIDParam(c, "comment_id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid id")
+	}
+
+	var req dto.ReactionRequest
+	if err := bindJSON(c, &req); err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid json")
+	}
+
+	resp, err := h.svc.React(commentID, userID, req)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return respondError(c, http.StatusNotFound, "comment not found")
+		}
+		return respondError(c, http.StatusBadRequest, err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, resp)
+}
+
+func (h *CommentLikeHandler) Unreact(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	commentID, err := parseIDParam(c, "comment_id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid id")
+	}
+
+	resp, err := h.svc.Unreact(commentID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return respondError(c, http.StatusNotFound, "comment not found")
+		}
+		return respondError(c, http.StatusBadRequest, err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, resp)
+}
+
+// Likers lists who reacted to the comment, paginated with ?cursor=&limit=.
+func (h *CommentLikeHandler) Likers(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	commentID, err := parseIDParam(c, "comment_id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid id")
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+
+	resp, err := h.svc.Likers(commentID, userID, limit, c.QueryParam("cursor"))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return respondError(c, http.StatusNotFound, "comment not found")
+		}
 		return respondError(c, http.StatusBadRequest, err.Error())
 	}
 
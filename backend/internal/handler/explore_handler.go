@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"backend/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ExploreHandler struct {
+	svc service.ExploreService
+}
+
+func NewExploreHandler(s service.ExploreService) *ExploreHandler {
+	return &ExploreHandler{svc: s}
+}
+
+func (h *ExploreHandler) Get(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+
+	resp, err := h.svc.Get(userID, c.QueryParam("tab"), limit, c.QueryParam("cursor"))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCursor) || errors.Is(err, service.ErrInvalidExploreTab) {
+			return respondError(c, http.StatusBadRequest, err.Error())
+		}
+		return respondError(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, resp)
+}
@@ -1,9 +1,9 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
-	"time"
 
 	"backend/internal/service"
 
@@ -26,17 +26,13 @@ func (h *FeedHandler) Get(c echo.Context) error {
 
 	limit, _ := strconv.Atoi(c.QueryParam("limit"))
 
-	cursorStr := c.QueryParam("cursor")
-	var cursor *time.Time
-	if cursorStr != "" {
-		t, err := time.Parse(time.RFC3339, cursorStr)
-		if err == nil {
-			cursor = &t
-		}
-	}
-
-	resp, err := h.svc.GetFeed(userID, limit, cursor)
+	resp, err := h.svc.GetFeed(userID, c.QueryParam("mode"), c.QueryParam("filter"), limit, c.QueryParam("cursor"))
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidCursor) ||
+			errors.Is(err, service.ErrInvalidFeedMode) ||
+			errors.Is(err, service.ErrInvalidFeedFilter) {
+			return respondError(c, http.StatusBadRequest, err.Error())
+		}
 		return respondError(c, http.StatusInternalServerError, err.Error())
 	}
 
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"backend/internal/repository"
+	"backend/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type InsightsHandler struct {
+	svc service.AnalyticsService
+}
+
+func NewInsightsHandler(s service.AnalyticsService) *InsightsHandler {
+	return &InsightsHandler{svc: s}
+}
+
+func insightsErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrInvalidInsightsRange):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// parseInsightsQuery reads ?interval=hour|day&days=N.
+func parseInsightsQuery(c echo.Context) (string, int, bool) {
+	days := 0
+	if v := c.QueryParam("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return "", 0, false
+		}
+		days = n
+	}
+	return c.QueryParam("interval"), days, true
+}
+
+func (h *InsightsHandler) Post(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	postID, err := parseIDParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid post id")
+	}
+
+	interval, days, ok := parseInsightsQuery(c)
+	if !ok {
+		return respondError(c, http.StatusBadRequest, "invalid days")
+	}
+
+	resp, err := h.svc.PostInsights(postID, userID, interval, days)
+	if err != nil {
+		return respondError(c, insightsErrorStatus(err), err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, resp)
+}
+
+func (h *InsightsHandler) Profile(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	interval, days, ok := parseInsightsQuery(c)
+	if !ok {
+		return respondError(c, http.StatusBadRequest, "invalid days")
+	}
+
+	resp, err := h.svc.ProfileInsights(userID, interval, days)
+	if err != nil {
+		return respondError(c, insightsErrorStatus(err), err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, resp)
+}
@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"backend/internal/dto"
+	"backend/internal/repository"
+	"backend/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type MessageHandler struct {
+	svc service.MessageService
+}
+
+func NewMessageHandler(s service.MessageService) *MessageHandler {
+	return &MessageHandler{svc: s}
+}
+
+func messageErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrForbidden), errors.Is(err, service.ErrDMNotAllowed):
+		return http.StatusForbidden
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+func (h *MessageHandler) CreateConversation(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	var req dto.CreateConversationRequest
+	if err := bindJSON(c, &req); err != nil {
+		return respondError(c, http.StatusBadRequest, err.Error())
+	}
+
+	resp, err := h.svc.CreateConversation(userID, req)
+	if err != nil {
+		return respondError(c, messageErrorStatus(err), err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, resp)
+}
+
+func (h *MessageHandler) ListConversations(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+
+	resp, err := h.svc.ListConversations(userID, limit, c.QueryParam("cursor"))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCursor) {
+			return respondError(c, http.StatusBadRequest, err.Error())
+		}
+		return respondError(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, resp)
+}
+
+func (h *MessageHandler) GetConversation(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid id")
+	}
+
+	resp, err := h.svc.GetConversation(id, userID)
+	if err != nil {
+		return respondError(c, messageErrorStatus(err), err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, resp)
+}
+
+func (h *MessageHandler) Send(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid id")
+	}
+
+	var req dto.SendMessageRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid request body")
+	}
+
+	// вложения приходят только в multipart, JSON-сообщения — без файлов
+	var files []*multipart.FileHeader
+	if form, err := c.MultipartForm(); err == nil {
+		files = form.File["files"]
+	}
+
+	msg, err := h.svc.SendMessage(id, userID, req, files)
+	if err != nil {
+		return respondError(c, messageErrorStatus(err), err.Error())
+	}
+
+	return respondJSON(c, http.StatusCreated, msg)
+}
+
+func (h *MessageHandler) ListMessages(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid id")
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+
+	resp, err := h.svc.ListMessages(id, userID, limit, c.QueryParam("cursor"))
+	if err != nil {
+		return respondError(c, messageErrorStatus(err), err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, resp)
+}
+
+func (h *MessageHandler) MarkRead(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid id")
+	}
+
+	var req dto.MarkReadRequest
+	if err := bindJSON(c, &req); err != nil {
+		return respondError(c, http.StatusBadRequest, err.Error())
+	}
+
+	if err := h.svc.MarkRead(id, userID, req.MessageID); err != nil {
+		return respondError(c, messageErrorStatus(err), err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, echo.Map{"message": "marked as read"})
+}
+
+// DeleteMessage deletes for the caller only unless ?for=everyone is passed.
+func (h *MessageHandler) DeleteMessage(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid id")
+	}
+	messageID, err := parseIDParam(c, "message_id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid message id")
+	}
+
+	var forEveryone bool
+	switch c.QueryParam("for") {
+	case "", "me":
+	case "everyone":
+		forEveryone = true
+	default:
+		return respondError(c, http.StatusBadRequest, "for must be me or everyone")
+	}
+
+	if err := h.svc.DeleteMessage(id, messageID, userID, forEveryone); err != nil {
+		return respondError(c, messageErrorStatus(err), err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, echo.Map{"message": "deleted"})
+}
+
+// File serves a message attachment to conversation members.
+func (h *MessageHandler) File(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid id")
+	}
+
+	path, err := h.svc.FilePath(id, userID, c.Param("name"))
+	if err != nil {
+		return respondError(c, messageErrorStatus(err), err.Error())
+	}
+
+	c.Response().Header().Set("Cache-Control", "private, max-age=86400")
+	return c.File(path)
+}
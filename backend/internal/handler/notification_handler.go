@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"backend/internal/repository"
+	"backend/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type NotificationHandler struct {
+	svc service.NotificationService
+}
+
+func NewNotificationHandler(s service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{svc: s}
+}
+
+func (h *NotificationHandler) List(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+
+	resp, err := h.svc.List(userID, limit, c.QueryParam("cursor"))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCursor) {
+			return respondError(c, http.StatusBadRequest, err.Error())
+		}
+		return respondError(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, resp)
+}
+
+func (h *NotificationHandler) UnreadCount(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	cnt, err := h.svc.UnreadCount(userID)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, echo.Map{"unread_count": cnt})
+}
+
+func (h *NotificationHandler) MarkRead(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid id")
+	}
+
+	if err := h.svc.MarkRead(userID, id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return respondError(c, http.StatusNotFound, "notification not found")
+		}
+		return respondError(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, echo.Map{"message": "marked as read"})
+}
+
+func (h *NotificationHandler) MarkAllRead(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	if err := h.svc.MarkAllRead(userID); err != nil {
+		return respondError(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, echo.Map{"message": "all marked as read"})
+}
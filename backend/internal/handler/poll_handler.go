@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"backend/internal/dto"
+	"backend/internal/repository"
+	"backend/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type PollHandler struct {
+	svc service.PollService
+}
+
+func NewPollHandler(s service.PollService) *PollHandler {
+	return &PollHandler{svc: s}
+}
+
+func pollErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrPollClosed):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrPollAnonymous):
+		return http.StatusForbidden
+	}
+	return http.StatusBadRequest
+}
+
+func (h *PollHandler) Vote(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	postID, err := parseIDParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid post id")
+	}
+
+	var req dto.PollVoteRequest
+	if err := bindJSON(c, &req); err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid json")
+	}
+
+	poll, err := h.svc.Vote(postID, userID, req)
+	if err != nil {
+		return respondError(c, pollErrorStatus(err), err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, poll)
+}
+
+func (h *PollHandler) Retract(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	postID, err := parseIDParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid post id")
+	}
+
+	poll, err := h.svc.Retract(postID, userID)
+	if err != nil {
+		return respondError(c, pollErrorStatus(err), err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, poll)
+}
+
+// Voters lists who picked ?option_id=, paginated with ?cursor=&limit=.
+func (h *PollHandler) Voters(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	postID, err := parseIDParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid post id")
+	}
+
+	optionID, err := strconv.ParseUint(c.QueryParam("option_id"), 10, 64)
+	if err != nil || optionID == 0 {
+		return respondError(c, http.StatusBadRequest, "invalid option_id")
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+
+	resp, err := h.svc.Voters(postID, uint(optionID), userID, limit, c.QueryParam("cursor"))
+	if err != nil {
+		return respondError(c, pollErrorStatus(err), err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, resp)
+}
@@ -1,9 +1,12 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"backend/internal/dto"
+	"backend/internal/repository"
 	"backend/internal/service"
 
 	"github.com/labstack/echo/v4"
@@ -114,7 +117,7 @@ func (h *PostHandler) Like(c echo.Context) error {
 	}
 
 	if err := h.postSvc.LikePost(postID, userID); err != nil {
-		return respondError(c, http.StatusBadRequest, err.Error())
+		return respondError(c, postErrorStatus(err), err.Error())
 	}
 
 	return respondJSON(c, http.StatusOK, echo.Map{"message": "liked"})
@@ -132,12 +135,78 @@ func (h *PostHandler) Unlike(c echo.Context) error {
 	}
 
 	if err := h.postSvc.UnlikePost(postID, userID); err != nil {
-		return respondError(c, http.StatusBadRequest, err.Error())
+		return respondError(c, postErrorStatus(err), err.Error())
 	}
 
 	return respondJSON(c, http.StatusOK, echo.Map{"message": "unliked"})
 }
 
+// React sets or changes the caller's reaction: {"reaction": "love"}.
+func (h *PostHandler) React(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	postID, err := parseIDParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid post id")
+	}
+
+	var req dto.ReactionRequest
+	if err := bindJSON(c, &req); err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid json")
+	}
+
+	resp, err := h.postSvc.React(postID, userID, req)
+	if err != nil {
+		return respondError(c, postErrorStatus(err), err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, resp)
+}
+
+func (h *PostHandler) Unreact(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	postID, err := parseIDParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid post id")
+	}
+
+	resp, err := h.postSvc.Unreact(postID, userID)
+	if err != nil {
+		return respondError(c, postErrorStatus(err), err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, resp)
+}
+
+// Likers lists who reacted to the post, paginated with ?cursor=&limit=.
+func (h *PostHandler) Likers(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	postID, err := parseIDParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid post id")
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+
+	resp, err := h.postSvc.GetLikers(postID, userID, limit, c.QueryParam("cursor"))
+	if err != nil {
+		return respondError(c, postErrorStatus(err), err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, resp)
+}
+
 func (h *PostHandler) AddFiles(c echo.Context) error {
 	postID, err := parseIDParam(c, "id")
 	if err != nil {
@@ -195,3 +264,148 @@ func (h *PostHandler) MyPosts(c echo.Context) error {
 
 	return respondJSON(c, http.StatusOK, posts)
 }
+
+func (h *PostHandler) Revisions(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	postID, err := parseIDParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid post id")
+	}
+
+	resp, err := h.postSvc.GetRevisions(postID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return respondError(c, http.StatusNotFound, "post not found")
+		}
+		return respondError(c, http.StatusBadRequest, err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, resp)
+}
+
+func (h *PostHandler) Repost(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	postID, err := parseIDParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid post id")
+	}
+
+	resp, err := h.postSvc.Repost(postID, userID)
+	if err != nil {
+		return respondError(c, postErrorStatus(err), err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, resp)
+}
+
+func (h *PostHandler) Unrepost(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	postID, err := parseIDParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid post id")
+	}
+
+	resp, err := h.postSvc.Unrepost(postID, userID)
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, resp)
+}
+
+func postErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrForbidden), errors.Is(err, service.ErrNotShareable):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrPostPublished):
+		return http.StatusConflict
+	}
+	return http.StatusBadRequest
+}
+
+func (h *PostHandler) Drafts(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	posts, err := h.postSvc.GetDrafts(userID)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, posts)
+}
+
+func (h *PostHandler) Schedule(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	postID, err := parseIDParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid post id")
+	}
+
+	var req dto.SchedulePostRequest
+	if err := bindJSON(c, &req); err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid json")
+	}
+
+	if err := h.postSvc.Schedule(postID, userID, req); err != nil {
+		return respondError(c, postErrorStatus(err), err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, echo.Map{"message": "scheduled"})
+}
+
+func (h *PostHandler) Unschedule(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	postID, err := parseIDParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid post id")
+	}
+
+	if err := h.postSvc.Unschedule(postID, userID); err != nil {
+		return respondError(c, postErrorStatus(err), err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, echo.Map{"message": "moved to drafts"})
+}
+
+func (h *PostHandler) Publish(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	postID, err := parseIDParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid post id")
+	}
+
+	if err := h.postSvc.Publish(postID, userID); err != nil {
+		return respondError(c, postErrorStatus(err), err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, echo.Map{"message": "published"})
+}
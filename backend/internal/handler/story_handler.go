@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"backend/internal/dto"
+	"backend/internal/repository"
+	"backend/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type StoryHandler struct {
+	svc service.StoryService
+}
+
+func NewStoryHandler(s service.StoryService) *StoryHandler {
+	return &StoryHandler{svc: s}
+}
+
+func storyErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrForbidden), errors.Is(err, service.ErrDMNotAllowed):
+		return http.StatusForbidden
+	}
+	return http.StatusBadRequest
+}
+
+// Create takes a single "file" plus an optional "caption".
+func (h *StoryHandler) Create(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	var req dto.CreateStoryRequestMultipart
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid form data")
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "file is required")
+	}
+
+	story, err := h.svc.Create(userID, req, file)
+	if err != nil {
+		return respondError(c, storyErrorStatus(err), err.Error())
+	}
+
+	return respondJSON(c, http.StatusCreated, story)
+}
+
+func (h *StoryHandler) Delete(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	storyID, err := parseIDParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid story id")
+	}
+
+	if err := h.svc.Delete(storyID, userID); err != nil {
+		return respondError(c, storyErrorStatus(err), err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, echo.Map{"message": "deleted"})
+}
+
+func (h *StoryHandler) Tray(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	resp, err := h.svc.Tray(userID)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, resp)
+}
+
+func (h *StoryHandler) UserStories(c echo.Context) error {
+	viewerID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	targetID, err := parseIDParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid user id")
+	}
+
+	stories, err := h.svc.UserStories(targetID, viewerID)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, stories)
+}
+
+func (h *StoryHandler) View(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	storyID, err := parseIDParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid story id")
+	}
+
+	if err := h.svc.View(storyID, userID); err != nil {
+		return respondError(c, storyErrorStatus(err), err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, echo.Map{"message": "viewed"})
+}
+
+// Viewers is paginated with ?cursor=&limit= and only open to the author.
+func (h *StoryHandler) Viewers(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	storyID, err := parseIDParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid story id")
+	}
+
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+
+	resp, err := h.svc.Viewers(storyID, userID, limit, c.QueryParam("cursor"))
+	if err != nil {
+		return respondError(c, storyErrorStatus(err), err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, resp)
+}
+
+func (h *StoryHandler) Reply(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	storyID, err := parseIDParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid story id")
+	}
+
+	var req dto.StoryReplyRequest
+	if err := bindJSON(c, &req); err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid json")
+	}
+
+	resp, err := h.svc.Reply(storyID, userID, req)
+	if err != nil {
+		return respondError(c, storyErrorStatus(err), err.Error())
+	}
+
+	return respondJSON(c, http.StatusCreated, resp)
+}
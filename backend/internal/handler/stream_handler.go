@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"backend/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+const streamHeartbeat = 25 * time.Second
+
+type StreamHandler struct {
+	svc service.StreamService
+}
+
+func NewStreamHandler(s service.StreamService) *StreamHandler {
+	return &StreamHandler{svc: s}
+}
+
+// Stream is a Server-Sent Events endpoint. Posts open on the client are passed
+// as repeated post_id params; a reconnecting client resumes via Last-Event-ID
+// (or last_event_id for EventSource polyfills).
+func (h *StreamHandler) Stream(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	var postIDs []uint
+	for _, raw := range c.QueryParams()["post_id"] {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil || id == 0 {
+			return respondError(c, http.StatusBadRequest, "invalid post_id")
+		}
+		postIDs = append(postIDs, uint(id))
+	}
+
+	lastEventID := c.Request().Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.QueryParam("last_event_id")
+	}
+
+	ctx := c.Request().Context()
+	events, err := h.svc.Subscribe(ctx, userID, postIDs, lastEventID)
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err.Error())
+	}
+
+	w := c.Response()
+	w.Header().Set(echo.HeaderContentType, "text/event-stream")
+	w.Header().Set(echo.HeaderCacheControl, "no-cache")
+	w.Header().Set(echo.HeaderConnection, "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := fmt.Fprint(w, "retry: 3000\n\n"); err != nil {
+		return nil
+	}
+	w.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return nil
+			}
+			w.Flush()
+		case ev, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if _, err := fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, ev.Data); err != nil {
+				return nil
+			}
+			w.Flush()
+		}
+	}
+}
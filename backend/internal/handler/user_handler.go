@@ -139,6 +139,9 @@ func (h *UserHandler) Follow(c echo.Context) error {
 	}
 
 	if err := h.svc.Follow(userID, targetID); err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			return respondError(c, http.StatusForbidden, err.Error())
+		}
 		return respondError(c, http.StatusBadRequest, err.Error())
 	}
 
@@ -203,3 +206,110 @@ func (h *UserHandler) Following(c echo.Context) error {
 
 	return respondJSON(c, http.StatusOK, users)
 }
+
+func (h *UserHandler) Block(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	targetID, err := parseIDParam(c, "id")
+	if err != nil {
+		httpErr := err.(*echo.HTTPError)
+		return respondError(c, httpErr.Code, httpErr.Message.(string))
+	}
+
+	if err := h.svc.Block(userID, targetID); err != nil {
+		return respondError(c, http.StatusBadRequest, err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, echo.Map{"message": "blocked"})
+}
+
+func (h *UserHandler) Unblock(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	targetID, err := parseIDParam(c, "id")
+	if err != nil {
+		httpErr := err.(*echo.HTTPError)
+		return respondError(c, httpErr.Code, httpErr.Message.(string))
+	}
+
+	if err := h.svc.Unblock(userID, targetID); err != nil {
+		return respondError(c, http.StatusBadRequest, err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, echo.Map{"message": "unblocked"})
+}
+
+func (h *UserHandler) Blocked(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	users, err := h.svc.GetBlocked(userID)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, users)
+}
+
+func (h *UserHandler) AddCloseFriend(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	friendID, err := parseIDParam(c, "id")
+	if err != nil {
+		httpErr := err.(*echo.HTTPError)
+		return respondError(c, httpErr.Code, httpErr.Message.(string))
+	}
+
+	if err := h.svc.AddCloseFriend(userID, friendID); err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			return respondError(c, http.StatusForbidden, err.Error())
+		}
+		return respondError(c, http.StatusBadRequest, err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, echo.Map{"message": "added to close friends"})
+}
+
+func (h *UserHandler) RemoveCloseFriend(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	friendID, err := parseIDParam(c, "id")
+	if err != nil {
+		httpErr := err.(*echo.HTTPError)
+		return respondError(c, httpErr.Code, httpErr.Message.(string))
+	}
+
+	if err := h.svc.RemoveCloseFriend(userID, friendID); err != nil {
+		return respondError(c, http.StatusBadRequest, err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, echo.Map{"message": "removed from close friends"})
+}
+
+func (h *UserHandler) CloseFriends(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	users, err := h.svc.GetCloseFriends(userID)
+	if err != nil {
+		return respondError(c, http.StatusInternalServerError, err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, users)
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"net/http"
+
+	"backend/internal/dto"
+	"backend/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ViewHandler struct {
+	svc service.ViewService
+}
+
+func NewViewHandler(s service.ViewService) *ViewHandler {
+	return &ViewHandler{svc: s}
+}
+
+// Impressions accepts a batch of post ids the client rendered in a feed.
+func (h *ViewHandler) Impressions(c echo.Context) error {
+	userID, ok := requireAuth(c)
+	if !ok {
+		return nil
+	}
+
+	var req dto.ImpressionsRequest
+	if err := bindJSON(c, &req); err != nil {
+		return respondError(c, http.StatusBadRequest, "invalid json")
+	}
+
+	if err := h.svc.RecordImpressions(userID, req.PostIDs); err != nil {
+		return respondError(c, http.StatusBadRequest, err.Error())
+	}
+
+	return respondJSON(c, http.StatusOK, echo.Map{"message": "recorded"})
+}
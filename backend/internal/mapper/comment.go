@@ -1,30 +1,62 @@
 package mapper
 
 import (
+	"time"
+
 	"backend/internal/dto"
 	"backend/internal/model"
 )
 
-func MapCommentToDTO(c model.Comment, isLiked bool, likesCount int) dto.CommentDTO {
-	var replies []dto.CommentDTO
-	for _, r := range c.Replies {
-		replies = append(replies, MapCommentToDTO(r, false, len(r.Likes)))
+// DeletedCommentText replaces the text of soft-deleted comments.
+const DeletedCommentText = "[deleted]"
+
+// RedactDeletedComment strips the text and author from a soft-deleted
+// comment so that only the placeholder is shown.
+func RedactDeletedComment(c *model.Comment) {
+	if c.DeletedAt == nil {
+		return
 	}
+	c.Text = DeletedCommentText
+	c.UserID = 0
+	c.User = model.User{}
+}
+
+func MapCommentToDTO(c model.Comment, isLiked bool, likesCount int) dto.CommentDTO {
+	RedactDeletedComment(&c)
+	reactions, _ := MapCommentReactions(c.Likes, 0)
 
 	return dto.CommentDTO{
-		ID:       c.ID,
-		PostID:   c.PostID,
-		UserID:   c.UserID,
-		ParentID: c.ParentID,
-		Text:     c.Text,
-		Likes:    likesCount,
-		IsLiked:  isLiked,
+		ID:        c.ID,
+		PostID:    c.PostID,
+		UserID:    c.UserID,
+		ParentID:  c.ParentID,
+		Text:      c.Text,
+		Likes:     likesCount,
+		IsLiked:   isLiked,
+		Reactions: reactions,
 		User: dto.UserShortDTO{
 			ID:       c.User.ID,
 			Nickname: c.User.Nickname,
 			Avatar:   c.User.AvatarURL,
 		},
-		Replies:   replies,
 		CreatedAt: c.CreatedAt,
+		EditedAt:  c.EditedAt,
+		IsDeleted: c.DeletedAt != nil,
+	}
+}
+
+func MapCommentRevisions(c *model.Comment, revs []model.CommentRevision) dto.CommentRevisionsResponse {
+	res := dto.CommentRevisionsResponse{
+		CommentID: c.ID,
+		EditedAt:  FormatTimePtr(c.EditedAt),
+		Revisions: make([]dto.CommentRevisionDTO, 0, len(revs)),
+	}
+	for _, r := range revs {
+		res.Revisions = append(res.Revisions, dto.CommentRevisionDTO{
+			ID:        r.ID,
+			Text:      r.Text,
+			CreatedAt: r.CreatedAt.Format(time.RFC3339),
+		})
 	}
+	return res
 }
@@ -0,0 +1,67 @@
+package mapper
+
+import (
+	"backend/internal/dto"
+	"backend/internal/model"
+)
+
+func MapMessageToDTO(m model.Message) dto.MessageDTO {
+	files := make([]dto.FileResponse, 0, len(m.Files))
+	for _, f := range m.Files {
+		files = append(files, dto.FileResponse{ID: f.ID, URL: f.URL})
+	}
+
+	return dto.MessageDTO{
+		ID:             m.ID,
+		ConversationID: m.ConversationID,
+		Sender: dto.UserShortDTO{
+			ID:       m.Sender.ID,
+			Nickname: m.Sender.Nickname,
+			Avatar:   m.Sender.AvatarURL,
+		},
+		Text:      m.Text,
+		Files:     files,
+		StoryID:   m.StoryID,
+		IsDeleted: m.DeletedForEveryone,
+		CreatedAt: m.CreatedAt,
+	}
+}
+
+func MapMessagesToDTO(msgs []model.Message) []dto.MessageDTO {
+	res := make([]dto.MessageDTO, 0, len(msgs))
+	for _, m := range msgs {
+		res = append(res, MapMessageToDTO(m))
+	}
+	return res
+}
+
+func MapConversationToDTO(c model.Conversation, last *model.Message, unread int64) dto.ConversationDTO {
+	members := make([]dto.ConversationMemberDTO, 0, len(c.Members))
+	for _, m := range c.Members {
+		members = append(members, dto.ConversationMemberDTO{
+			User: dto.UserShortDTO{
+				ID:       m.User.ID,
+				Nickname: m.User.Nickname,
+				Avatar:   m.User.AvatarURL,
+			},
+			LastReadMessageID: m.LastReadMessageID,
+		})
+	}
+
+	var lastDTO *dto.MessageDTO
+	if last != nil {
+		d := MapMessageToDTO(*last)
+		lastDTO = &d
+	}
+
+	return dto.ConversationDTO{
+		ID:            c.ID,
+		IsGroup:       c.IsGroup,
+		Title:         c.Title,
+		Members:       members,
+		LastMessage:   lastDTO,
+		UnreadCount:   unread,
+		LastMessageAt: c.LastMessageAt,
+		CreatedAt:     c.CreatedAt,
+	}
+}
@@ -0,0 +1,67 @@
+package mapper
+
+import (
+	"fmt"
+
+	"backend/internal/dto"
+	"backend/internal/model"
+)
+
+var notificationVerbs = map[string]string{
+	model.NotificationPostLike:    "liked your post",
+	model.NotificationComment:     "commented on your post",
+	model.NotificationReply:       "replied to your comment",
+	model.NotificationCommentLike: "liked your comment",
+	model.NotificationFollow:      "started following you",
+	model.NotificationRepost:      "reposted your post",
+	model.NotificationStoryReply:  "replied to your story",
+}
+
+func MapNotificationToDTO(n model.Notification) dto.NotificationDTO {
+	return dto.NotificationDTO{
+		ID:   n.ID,
+		Type: n.Type,
+		Actor: dto.UserShortDTO{
+			ID:       n.Actor.ID,
+			Nickname: n.Actor.Nickname,
+			Avatar:   n.Actor.AvatarURL,
+		},
+		ActorsCount: n.ActorsCount,
+		PostID:      n.PostID,
+		CommentID:   n.CommentID,
+		Message:     notificationMessage(n),
+		IsRead:      n.IsRead,
+		CreatedAt:   n.CreatedAt,
+		UpdatedAt:   n.UpdatedAt,
+	}
+}
+
+func MapNotificationsToDTO(items []model.Notification) []dto.NotificationDTO {
+	res := make([]dto.NotificationDTO, 0, len(items))
+	for _, n := range items {
+		res = append(res, MapNotificationToDTO(n))
+	}
+	return res
+}
+
+// notificationMessage renders "Aida and 12 others liked your post".
+func notificationMessage(n model.Notification) string {
+	verb, ok := notificationVerbs[n.Type]
+	if !ok {
+		verb = n.Type
+	}
+
+	name := n.Actor.Nickname
+	if n.Actor.FirstName != nil && *n.Actor.FirstName != "" {
+		name = *n.Actor.FirstName
+	}
+
+	switch others := n.ActorsCount - 1; {
+	case others <= 0:
+		return fmt.Sprintf("%s %s", name, verb)
+	case others == 1:
+		return fmt.Sprintf("%s and 1 other %s", name, verb)
+	default:
+		return fmt.Sprintf("%s and %d others %s", name, others, verb)
+	}
+}
@@ -6,44 +6,148 @@ import (
 	"time"
 )
 
-func MapPostsToDTO(posts []model.Post, userID uint) []dto.PostResponse {
+// PostViewerData holds the counts and per-viewer state that aren't preloaded
+// on the posts themselves; every map is keyed by post ID.
+type PostViewerData struct {
+	Bookmarked    map[uint]bool
+	RepostCounts  map[uint]int
+	Reposted      map[uint]bool
+	Reactions     map[uint]map[string]int
+	MyReactions   map[uint]string
+	CommentCounts map[uint]int
+
+	// Quoted holds the originals of quote posts that the viewer may see.
+	Quoted map[uint]model.Post
+
+	Polls       map[uint]model.Poll // by post ID
+	PollVotes   map[uint]int        // by option ID
+	PollVoters  map[uint]int        // by poll ID
+	PollChoices map[uint][]uint     // viewer's options by poll ID
+}
+
+func MapPostsToDTO(posts []model.Post, userID uint, viewer PostViewerData) []dto.PostResponse {
 	result := make([]dto.PostResponse, 0, len(posts))
 
 	for _, p := range posts {
-		files := make([]dto.FileResponse, 0, len(p.Files))
-		for _, f := range p.Files {
-			files = append(files, dto.FileResponse{
-				ID:  f.ID,
-				URL: f.URL,
-			})
+		reactions := viewer.Reactions[p.ID]
+		if reactions == nil {
+			reactions = map[string]int{}
+		}
+		var myReaction *string
+		if r, ok := viewer.MyReactions[p.ID]; ok {
+			myReaction = &r
+		}
+
+		resp := dto.PostResponse{
+			ID:           p.ID,
+			UserID:       p.UserID,
+			User:         MapPostAuthor(p.User),
+			Description:  p.Description,
+			Files:        mapFiles(p.Files),
+			LikesCount:   ReactionsTotal(reactions),
+			Comments:     viewer.CommentCounts[p.ID],
+			IsLiked:      myReaction != nil,
+			Reactions:    reactions,
+			MyReaction:   myReaction,
+			IsBookmarked: viewer.Bookmarked[p.ID],
+			RepostsCount: viewer.RepostCounts[p.ID],
+			IsReposted:   viewer.Reposted[p.ID],
+			ViewsCount:   p.ViewsCount,
+			CreatedAt:    p.CreatedAt.Format(time.RFC3339),
+			EditedAt:     FormatTimePtr(p.EditedAt),
+			Status:       p.Status,
+			PublishAt:    FormatTimePtr(p.PublishAt),
+			Visibility:   p.Visibility,
 		}
 
-		isLiked := false
-		for _, l := range p.Likes {
-			if l.UserID == userID {
-				isLiked = true
-				break
+		if p.QuotedPostID != nil {
+			if q, ok := viewer.Quoted[*p.QuotedPostID]; ok {
+				resp.QuotedPost = &dto.QuotedPostDTO{
+					ID:          q.ID,
+					User:        MapPostAuthor(q.User),
+					Description: q.Description,
+					Files:       mapFiles(q.Files),
+					CreatedAt:   q.CreatedAt.Format(time.RFC3339),
+				}
+			} else {
+				resp.QuoteUnavailable = true
 			}
 		}
 
-		author := dto.PostAuthorDTO{
-			ID:        p.User.ID,
-			Nickname:  p.User.Nickname,
-			AvatarURL: p.User.AvatarURL,
+		if poll, ok := viewer.Polls[p.ID]; ok {
+			resp.Poll = MapPoll(poll, viewer.PollVotes, viewer.PollVoters[poll.ID], viewer.PollChoices[poll.ID])
 		}
 
-		result = append(result, dto.PostResponse{
-			ID:          p.ID,
-			UserID:      p.UserID,
-			User:        author,
-			Description: p.Description,
-			Files:       files,
-			LikesCount:  len(p.Likes),
-			Comments:    len(p.Comments),
-			IsLiked:     isLiked,
-			CreatedAt:   p.CreatedAt.Format(time.RFC3339),
-		})
+		result = append(result, resp)
 	}
 
 	return result
 }
+
+func MapPoll(poll model.Poll, votes map[uint]int, voters int, choices []uint) *dto.PollDTO {
+	res := &dto.PollDTO{
+		ID:          poll.ID,
+		Multiple:    poll.Multiple,
+		Anonymous:   poll.Anonymous,
+		EndsAt:      FormatTimePtr(poll.EndsAt),
+		Closed:      poll.EndsAt != nil && !time.Now().Before(*poll.EndsAt),
+		TotalVoters: voters,
+		Options:     make([]dto.PollOptionDTO, 0, len(poll.Options)),
+		MyVotes:     choices,
+	}
+	if res.MyVotes == nil {
+		res.MyVotes = []uint{}
+	}
+	for _, o := range poll.Options {
+		res.Options = append(res.Options, dto.PollOptionDTO{
+			ID:    o.ID,
+			Text:  o.Text,
+			Votes: votes[o.ID],
+		})
+	}
+	return res
+}
+
+func MapPostAuthor(u model.User) dto.PostAuthorDTO {
+	return dto.PostAuthorDTO{
+		ID:        u.ID,
+		Nickname:  u.Nickname,
+		AvatarURL: u.AvatarURL,
+	}
+}
+
+// FormatTimePtr formats an optional timestamp as RFC3339.
+func FormatTimePtr(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.Format(time.RFC3339)
+	return &s
+}
+
+func MapPostRevisions(post *model.Post, revs []model.PostRevision) dto.PostRevisionsResponse {
+	res := dto.PostRevisionsResponse{
+		PostID:    post.ID,
+		EditedAt:  FormatTimePtr(post.EditedAt),
+		Revisions: make([]dto.PostRevisionDTO, 0, len(revs)),
+	}
+	for _, r := range revs {
+		res.Revisions = append(res.Revisions, dto.PostRevisionDTO{
+			ID:          r.ID,
+			Description: r.Description,
+			CreatedAt:   r.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return res
+}
+
+func mapFiles(files []model.File) []dto.FileResponse {
+	res := make([]dto.FileResponse, 0, len(files))
+	for _, f := range files {
+		res = append(res, dto.FileResponse{
+			ID:  f.ID,
+			URL: f.URL,
+		})
+	}
+	return res
+}
@@ -0,0 +1,26 @@
+package mapper
+
+import "backend/internal/model"
+
+func MapCommentReactions(likes []model.CommentLike, viewerID uint) (map[string]int, *string) {
+	counts := make(map[string]int)
+	var mine *string
+	for _, l := range likes {
+		counts[l.Reaction]++
+		if l.UserID == viewerID {
+			r := l.Reaction
+			mine = &r
+		}
+	}
+	return counts, mine
+}
+
+// ReactionsTotal is the number of reactions of all kinds, which is what
+// likes_count means for older clients.
+func ReactionsTotal(counts map[string]int) int {
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	return total
+}
@@ -0,0 +1,25 @@
+package mapper
+
+import (
+	"backend/internal/dto"
+	"backend/internal/model"
+)
+
+// MapStoryToDTO: viewers is nil unless the caller is the author.
+func MapStoryToDTO(s model.Story, seen bool, viewers *int) dto.StoryDTO {
+	return dto.StoryDTO{
+		ID: s.ID,
+		User: dto.UserShortDTO{
+			ID:       s.User.ID,
+			Nickname: s.User.Nickname,
+			Avatar:   s.User.AvatarURL,
+		},
+		MediaURL:     s.MediaURL,
+		MediaType:    s.MediaType,
+		Caption:      s.Caption,
+		Seen:         seen,
+		ViewersCount: viewers,
+		ExpiresAt:    s.ExpiresAt,
+		CreatedAt:    s.CreatedAt,
+	}
+}
@@ -33,6 +33,8 @@ func MapUserToResponseWithCounts(u *model.User, postsCount, followersCount, foll
 		City:        u.City,
 		Description: u.Description,
 
+		DMFollowingOnly: u.DMFollowingOnly,
+
 		PostsCount:     int(postsCount),
 		FollowersCount: int(followersCount),
 		FollowingCount: int(followingCount),
@@ -61,6 +63,9 @@ func ApplyUpdateUserDTO(u *model.User, dto dto.UpdateUserDTO) {
 	if dto.Description != nil {
 		u.Description = dto.Description
 	}
+	if dto.DMFollowingOnly != nil {
+		u.DMFollowingOnly = *dto.DMFollowingOnly
+	}
 }
 
 func MapUsersToShortDTO(users []model.User) []dto.UserShortDTO {
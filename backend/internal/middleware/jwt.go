@@ -92,3 +92,25 @@ func JWT(secret string) echo.MiddlewareFunc {
 		}
 	}
 }
+
+// TokenFromQuery lets clients that can't set headers (EventSource) pass the
+// access token as a query parameter. It must run before JWT. The parameter
+// is moved into the header and dropped from the request, so the request
+// logger, which reads the URI after the handler, never sees the token.
+func TokenFromQuery(param string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			query := req.URL.Query()
+			if token := query.Get(param); token != "" {
+				if req.Header.Get("Authorization") == "" {
+					req.Header.Set("Authorization", "Bearer "+token)
+				}
+				query.Del(param)
+				req.URL.RawQuery = query.Encode()
+				req.RequestURI = req.URL.RequestURI()
+			}
+			return next(c)
+		}
+	}
+}
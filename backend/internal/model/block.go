@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+type Block struct {
+	ID uint `gorm:"primaryKey"`
+
+	UserID uint `gorm:"index;not null"`
+	User   User `gorm:"foreignKey:UserID"`
+
+	BlockedID uint `gorm:"index;not null"`
+	Blocked   User `gorm:"foreignKey:BlockedID"`
+
+	CreatedAt time.Time
+}
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+type Bookmark struct {
+	ID     uint `gorm:"primaryKey"`
+	UserID uint `gorm:"index;not null"`
+	User   User `gorm:"foreignKey:UserID"`
+
+	PostID uint `gorm:"index;not null"`
+	Post   Post `gorm:"foreignKey:PostID"`
+
+	CollectionID *uint
+	Collection   *BookmarkCollection `gorm:"foreignKey:CollectionID"`
+
+	CreatedAt time.Time
+}
+
+// BookmarkCollection is a named private folder of bookmarks.
+type BookmarkCollection struct {
+	ID     uint   `gorm:"primaryKey"`
+	UserID uint   `gorm:"index;not null"`
+	Name   string `gorm:"not null"`
+
+	CreatedAt time.Time
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// CloseFriend puts FriendID on UserID's close friends list.
+type CloseFriend struct {
+	ID uint `gorm:"primaryKey"`
+
+	UserID uint `gorm:"index;not null"`
+	User   User `gorm:"foreignKey:UserID"`
+
+	FriendID uint `gorm:"index;not null"`
+	Friend   User `gorm:"foreignKey:FriendID"`
+
+	CreatedAt time.Time
+}
@@ -21,4 +21,11 @@ type Comment struct {
 	Likes []CommentLike `gorm:"foreignKey:CommentID"`
 
 	CreatedAt time.Time
+	EditedAt  *time.Time
+	// DeletedAt is set on comments removed while they had replies; they
+	// stay in the thread as a "[deleted]" placeholder.
+	DeletedAt *time.Time
+	// HiddenAt is set when the post author hides the comment; then only its
+	// writer and the post author see it.
+	HiddenAt *time.Time
 }
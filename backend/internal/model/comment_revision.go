@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// CommentRevision is one saved version of a comment's text. CreatedAt is
+// when that version was written.
+type CommentRevision struct {
+	ID        uint `gorm:"primaryKey"`
+	CommentID uint `gorm:"index;not null"`
+
+	Text string `gorm:"type:text;not null"`
+
+	CreatedAt time.Time
+}
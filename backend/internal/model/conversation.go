@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+type Conversation struct {
+	ID        uint `gorm:"primaryKey"`
+	IsGroup   bool `gorm:"not null;default:false"`
+	Title     *string
+	CreatedBy *uint
+	DirectKey *string
+
+	Members []ConversationMember `gorm:"foreignKey:ConversationID"`
+
+	LastMessageAt time.Time
+	CreatedAt     time.Time
+}
+
+type ConversationMember struct {
+	ID             uint `gorm:"primaryKey"`
+	ConversationID uint `gorm:"index;not null"`
+
+	UserID uint `gorm:"index;not null"`
+	User   User `gorm:"foreignKey:UserID"`
+
+	LastReadMessageID uint `gorm:"not null;default:0"`
+	JoinedAt          time.Time
+}
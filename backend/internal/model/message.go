@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+type Message struct {
+	ID             uint `gorm:"primaryKey"`
+	ConversationID uint `gorm:"index;not null"`
+
+	SenderID uint `gorm:"not null"`
+	Sender   User `gorm:"foreignKey:SenderID"`
+
+	Text               string `gorm:"type:text;not null;default:''"`
+	DeletedForEveryone bool   `gorm:"not null;default:false"`
+
+	// StoryID is set on replies to a story.
+	StoryID *uint
+
+	Files []MessageFile `gorm:"foreignKey:MessageID"`
+
+	CreatedAt time.Time
+}
+
+type MessageFile struct {
+	ID        uint   `gorm:"primaryKey"`
+	MessageID uint   `gorm:"index;not null"`
+	URL       string `gorm:"not null"`
+}
+
+// MessageDeletion hides a message for a single member ("delete for me").
+type MessageDeletion struct {
+	ID        uint `gorm:"primaryKey"`
+	MessageID uint `gorm:"index;not null"`
+	UserID    uint `gorm:"not null"`
+}
@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+const (
+	NotificationPostLike    = "post_like"
+	NotificationComment     = "comment"
+	NotificationReply       = "reply"
+	NotificationCommentLike = "comment_like"
+	NotificationFollow      = "follow"
+	NotificationRepost      = "repost"
+	NotificationStoryReply  = "story_reply"
+)
+
+// Notification is an aggregated group: all actors that did the same thing
+// to the same target while the group was unread end up in one row.
+type Notification struct {
+	ID     uint `gorm:"primaryKey"`
+	UserID uint `gorm:"index;not null"`
+	User   User `gorm:"foreignKey:UserID"`
+
+	// ActorID is the most recent actor of the group.
+	ActorID uint `gorm:"not null"`
+	Actor   User `gorm:"foreignKey:ActorID"`
+
+	Type      string `gorm:"not null"`
+	PostID    *uint
+	CommentID *uint
+
+	ActorsCount int  `gorm:"not null;default:0"`
+	IsRead      bool `gorm:"not null;default:false"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type NotificationActor struct {
+	ID             uint `gorm:"primaryKey"`
+	NotificationID uint `gorm:"index;not null"`
+	ActorID        uint `gorm:"not null"`
+	CreatedAt      time.Time
+}
@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+type Poll struct {
+	ID     uint `gorm:"primaryKey"`
+	PostID uint `gorm:"uniqueIndex;not null"`
+
+	Multiple  bool `gorm:"not null;default:false"`
+	Anonymous bool `gorm:"not null;default:false"`
+	EndsAt    *time.Time
+
+	Options []PollOption `gorm:"foreignKey:PollID"`
+
+	CreatedAt time.Time
+}
+
+type PollOption struct {
+	ID       uint   `gorm:"primaryKey"`
+	PollID   uint   `gorm:"index;not null"`
+	Position int    `gorm:"not null"`
+	Text     string `gorm:"not null"`
+}
+
+// PollVote is a user's ballot; there is at most one per poll and user.
+type PollVote struct {
+	ID     uint `gorm:"primaryKey"`
+	PollID uint `gorm:"index;not null"`
+	UserID uint `gorm:"index;not null"`
+	User   User `gorm:"foreignKey:UserID"`
+
+	Options []PollVoteOption `gorm:"foreignKey:VoteID"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type PollVoteOption struct {
+	VoteID   uint `gorm:"primaryKey"`
+	OptionID uint `gorm:"primaryKey"`
+}
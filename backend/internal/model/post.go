@@ -2,6 +2,25 @@ package model
 
 import "time"
 
+const (
+	PostStatusDraft     = "draft"
+	PostStatusScheduled = "scheduled"
+	PostStatusPublished = "published"
+)
+
+const (
+	PostVisibilityPublic       = "public"
+	PostVisibilityFollowers    = "followers"
+	PostVisibilityCloseFriends = "close_friends"
+	PostVisibilityOnlyMe       = "only_me"
+)
+
+const (
+	CommentPolicyEveryone  = "everyone"
+	CommentPolicyFollowers = "followers"
+	CommentPolicyOff       = "off"
+)
+
 type Post struct {
 	ID     uint `gorm:"primaryKey"`
 	UserID uint `gorm:"index;not null"`
@@ -10,9 +29,27 @@ type Post struct {
 	Description *string
 	ViewsCount  int `gorm:"default:0"`
 
+	// Status is draft, scheduled or published; only published posts are
+	// shown to anyone but the author. PublishAt is set for scheduled posts.
+	Status    string `gorm:"not null;default:published"`
+	PublishAt *time.Time
+
+	// Visibility is the audience; it is enforced in the repositories.
+	Visibility string `gorm:"not null;default:public"`
+
+	// QuotedPostID is kept when the quoted post is deleted, so the quote can
+	// say the original is unavailable.
+	QuotedPostID *uint `gorm:"index"`
+
+	// CommentPolicy says who may comment. PinnedCommentID is the root
+	// comment the author keeps on top of the thread.
+	CommentPolicy   string `gorm:"not null;default:everyone"`
+	PinnedCommentID *uint
+
 	Files    []File     `gorm:"foreignKey:PostID"`
 	Likes    []PostLike `gorm:"foreignKey:PostID"`
 	Comments []Comment  `gorm:"foreignKey:PostID"`
 
 	CreatedAt time.Time
+	EditedAt  *time.Time
 }
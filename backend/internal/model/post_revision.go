@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// PostRevision is one saved version of a post's description. CreatedAt is
+// when that version was written.
+type PostRevision struct {
+	ID     uint `gorm:"primaryKey"`
+	PostID uint `gorm:"index;not null"`
+
+	Description *string
+
+	CreatedAt time.Time
+}
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// PostStat is an hourly rollup of engagement on a post.
+type PostStat struct {
+	PostID uint      `gorm:"primaryKey"`
+	Bucket time.Time `gorm:"primaryKey"`
+
+	Views         int `gorm:"not null;default:0"`
+	Likes         int `gorm:"not null;default:0"`
+	Comments      int `gorm:"not null;default:0"`
+	Bookmarks     int `gorm:"not null;default:0"`
+	FollowsGained int `gorm:"not null;default:0"`
+}
+
+// PostViewer records that a user has seen a post at least once.
+type PostViewer struct {
+	PostID uint `gorm:"primaryKey"`
+	UserID uint `gorm:"primaryKey"`
+
+	FirstSeenAt time.Time `gorm:"not null"`
+	LastSeenAt  time.Time `gorm:"not null"`
+}
@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 type PostLike struct {
 	ID     uint `gorm:"primaryKey"`
 	PostID uint `gorm:"index;not null"`
@@ -7,4 +9,7 @@ type PostLike struct {
 
 	UserID uint `gorm:"index;not null"`
 	User   User `gorm:"foreignKey:UserID"`
+
+	Reaction  string `gorm:"not null;default:like"`
+	CreatedAt time.Time
 }
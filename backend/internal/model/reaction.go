@@ -0,0 +1,12 @@
+package model
+
+// Reactions a user can leave on a post or comment. A plain like is the
+// "like" reaction, so rows in post_likes and comment_likes are reactions.
+const (
+	ReactionLike  = "like"  // 👍
+	ReactionLove  = "love"  // ❤️
+	ReactionHaha  = "haha"  // 😂
+	ReactionWow   = "wow"   // 😮
+	ReactionSad   = "sad"   // 😢
+	ReactionAngry = "angry" // 😡
+)
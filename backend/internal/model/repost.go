@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+type Repost struct {
+	ID     uint `gorm:"primaryKey"`
+	PostID uint `gorm:"index;not null"`
+	Post   Post `gorm:"foreignKey:PostID"`
+
+	UserID uint `gorm:"index;not null"`
+	User   User `gorm:"foreignKey:UserID"`
+
+	CreatedAt time.Time
+}
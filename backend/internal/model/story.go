@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+const (
+	StoryMediaImage = "image"
+	StoryMediaVideo = "video"
+)
+
+// Story is an image or video that disappears after ExpiresAt.
+type Story struct {
+	ID     uint `gorm:"primaryKey"`
+	UserID uint `gorm:"index;not null"`
+	User   User `gorm:"foreignKey:UserID"`
+
+	MediaURL  string `gorm:"not null"`
+	MediaType string `gorm:"not null"`
+	Caption   string `gorm:"type:text;not null;default:''"`
+
+	ExpiresAt time.Time `gorm:"index;not null"`
+	CreatedAt time.Time
+}
+
+type StoryView struct {
+	StoryID  uint `gorm:"primaryKey"`
+	UserID   uint `gorm:"primaryKey"`
+	User     User `gorm:"foreignKey:UserID"`
+	ViewedAt time.Time
+}
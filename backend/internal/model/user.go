@@ -16,6 +16,13 @@ type User struct {
 	City        *string
 	Description *string
 
+	DMFollowingOnly bool `gorm:"not null;default:false"`
+	// IsModerator may delete any comment. Set directly in the database.
+	IsModerator bool `gorm:"not null;default:false"`
+	// IsCelebrity marks users with many followers, whose posts are pulled
+	// into home feeds on read. It is recomputed in the background.
+	IsCelebrity bool `gorm:"not null;default:false"`
+
 	Followers []Follower `gorm:"foreignKey:UserID"`
 
 	Following []Follower `gorm:"foreignKey:FollowerID"`
@@ -0,0 +1,140 @@
+package pubsub
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+const subscriberBuffer = 64
+
+type subscriber struct {
+	ch     chan Event
+	once   sync.Once
+	closed chan struct{}
+}
+
+func (s *subscriber) close() {
+	s.once.Do(func() { close(s.closed) })
+}
+
+// hub fans events out to the subscribers of this process. Both brokers use
+// it: the memory broker feeds it directly, the Redis broker from a shared
+// pattern subscription.
+type hub struct {
+	mu   sync.RWMutex
+	subs map[string]map[*subscriber]struct{}
+}
+
+func newHub() *hub {
+	return &hub{subs: make(map[string]map[*subscriber]struct{})}
+}
+
+func (h *hub) add(topics []string, s *subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, t := range topics {
+		if h.subs[t] == nil {
+			h.subs[t] = make(map[*subscriber]struct{})
+		}
+		h.subs[t][s] = struct{}{}
+	}
+}
+
+func (h *hub) remove(topics []string, s *subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, t := range topics {
+		delete(h.subs[t], s)
+		if len(h.subs[t]) == 0 {
+			delete(h.subs, t)
+		}
+	}
+}
+
+func (h *hub) dispatch(ev Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for s := range h.subs[ev.Topic] {
+		select {
+		case s.ch <- ev:
+		default:
+			// медленный клиент: рвём соединение, он переподключится
+			// с Last-Event-ID и доберёт пропущенное из истории
+			s.close()
+		}
+	}
+}
+
+type historyFunc func(ctx context.Context, topic string, after eventID) ([]Event, error)
+
+func (h *hub) subscribe(ctx context.Context, topics []string, lastEventID string, history historyFunc) (<-chan Event, error) {
+	var after *eventID
+	if lastEventID != "" {
+		id, err := parseEventID(lastEventID)
+		if err != nil {
+			return nil, err
+		}
+		after = &id
+	}
+
+	// подписываемся до чтения истории, чтобы не потерять события между ними
+	sub := &subscriber{ch: make(chan Event, subscriberBuffer), closed: make(chan struct{})}
+	h.add(topics, sub)
+
+	var replay []Event
+	if after != nil {
+		for _, t := range topics {
+			evs, err := history(ctx, t, *after)
+			if err != nil {
+				h.remove(topics, sub)
+				return nil, err
+			}
+			replay = append(replay, evs...)
+		}
+		sort.Slice(replay, func(i, j int) bool {
+			a, _ := parseEventID(replay[i].ID)
+			b, _ := parseEventID(replay[j].ID)
+			return a.less(b)
+		})
+	}
+
+	out := make(chan Event)
+	go func() {
+		defer close(out)
+		defer h.remove(topics, sub)
+
+		replayedUntil := after
+		for _, ev := range replay {
+			select {
+			case out <- ev:
+			case <-ctx.Done():
+				return
+			}
+			id, _ := parseEventID(ev.ID)
+			replayedUntil = &id
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sub.closed:
+				return
+			case ev := <-sub.ch:
+				if replayedUntil != nil {
+					if id, err := parseEventID(ev.ID); err == nil && !replayedUntil.less(id) {
+						continue
+					}
+				}
+				select {
+				case out <- ev:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
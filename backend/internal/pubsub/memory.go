@@ -0,0 +1,75 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const memoryHistorySize = 256
+
+// MemoryBroker is an in-process broker for a single replica. It keeps the last
+// memoryHistorySize events per topic for resuming.
+type MemoryBroker struct {
+	hub *hub
+
+	mu      sync.Mutex
+	last    eventID
+	history map[string][]Event
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{
+		hub:     newHub(),
+		history: make(map[string][]Event),
+	}
+}
+
+func (b *MemoryBroker) Publish(_ context.Context, topic, typ string, data interface{}) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+
+	b.mu.Lock()
+	id := eventID{ms: uint64(time.Now().UnixMilli())}
+	if !b.last.less(id) {
+		id = eventID{ms: b.last.ms, seq: b.last.seq + 1}
+	}
+	b.last = id
+
+	ev := Event{ID: id.String(), Topic: topic, Type: typ, Data: raw}
+	h := append(b.history[topic], ev)
+	if len(h) > memoryHistorySize {
+		h = h[len(h)-memoryHistorySize:]
+	}
+	b.history[topic] = h
+	b.mu.Unlock()
+
+	b.hub.dispatch(ev)
+	return nil
+}
+
+func (b *MemoryBroker) Subscribe(ctx context.Context, topics []string, lastEventID string) (<-chan Event, error) {
+	return b.hub.subscribe(ctx, topics, lastEventID, b.since)
+}
+
+func (b *MemoryBroker) since(_ context.Context, topic string, after eventID) ([]Event, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var res []Event
+	for _, ev := range b.history[topic] {
+		id, err := parseEventID(ev.ID)
+		if err == nil && after.less(id) {
+			res = append(res, ev)
+		}
+	}
+	return res, nil
+}
+
+func (b *MemoryBroker) Close() error {
+	return nil
+}
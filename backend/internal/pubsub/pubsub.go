@@ -0,0 +1,71 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidEventID = errors.New("invalid event id")
+
+// Event is a single message on a topic. IDs have the "<unix ms>-<seq>" form
+// (the same as Redis stream IDs), so they are ordered across topics and can be
+// used as SSE Last-Event-ID to resume a dropped connection.
+type Event struct {
+	ID    string          `json:"id"`
+	Topic string          `json:"topic"`
+	Type  string          `json:"type"`
+	Data  json.RawMessage `json:"data"`
+}
+
+type Broker interface {
+	Publish(ctx context.Context, topic, typ string, data interface{}) error
+	// Subscribe delivers events of the given topics until ctx is done, then
+	// closes the channel. With a non-empty lastEventID the retained events
+	// published after it are replayed first.
+	Subscribe(ctx context.Context, topics []string, lastEventID string) (<-chan Event, error)
+	Close() error
+}
+
+type eventID struct {
+	ms  uint64
+	seq uint64
+}
+
+func parseEventID(s string) (eventID, error) {
+	parts := strings.SplitN(s, "-", 2)
+	if len(parts) != 2 {
+		return eventID{}, ErrInvalidEventID
+	}
+	ms, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return eventID{}, ErrInvalidEventID
+	}
+	seq, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return eventID{}, ErrInvalidEventID
+	}
+	return eventID{ms: ms, seq: seq}, nil
+}
+
+func (id eventID) String() string {
+	return strconv.FormatUint(id.ms, 10) + "-" + strconv.FormatUint(id.seq, 10)
+}
+
+func (id eventID) less(other eventID) bool {
+	if id.ms != other.ms {
+		return id.ms < other.ms
+	}
+	return id.seq < other.seq
+}
+
+// ValidateEventID reports whether s can be used as a resume position.
+func ValidateEventID(s string) error {
+	if s == "" {
+		return nil
+	}
+	_, err := parseEventID(s)
+	return err
+}
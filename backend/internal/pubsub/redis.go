@@ -0,0 +1,117 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"backend/internal/redis"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	redisChannelPrefix = "events:"
+	redisStreamPrefix  = "stream:"
+	redisStreamMaxLen  = 1000
+	redisStreamTTL     = 24 * time.Hour
+)
+
+// RedisBroker fans events out across replicas. Every event is appended to a
+// capped Redis stream per topic (which assigns the ID and keeps history for
+// resuming) and then published on a channel; each replica holds one pattern
+// subscription and dispatches to its local subscribers.
+type RedisBroker struct {
+	rdb    *redis.Client
+	hub    *hub
+	ps     *goredis.PubSub
+	cancel context.CancelFunc
+}
+
+func NewRedisBroker(rdb *redis.Client) *RedisBroker {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &RedisBroker{
+		rdb:    rdb,
+		hub:    newHub(),
+		ps:     rdb.PSubscribe(ctx, redisChannelPrefix+"*"),
+		cancel: cancel,
+	}
+	go b.listen(ctx)
+	return b
+}
+
+func (b *RedisBroker) listen(ctx context.Context) {
+	for msg := range b.ps.Channel() {
+		var ev Event
+		if err := json.Unmarshal([]byte(msg.Payload), &ev); err != nil {
+			log.Printf("pubsub: bad event on %s: %v", msg.Channel, err)
+			continue
+		}
+		b.hub.dispatch(ev)
+	}
+	if ctx.Err() == nil {
+		log.Printf("pubsub: redis subscription closed")
+	}
+}
+
+func (b *RedisBroker) Publish(ctx context.Context, topic, typ string, data interface{}) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+
+	key := redisStreamPrefix + topic
+	id, err := b.rdb.XAdd(ctx, &goredis.XAddArgs{
+		Stream: key,
+		MaxLen: redisStreamMaxLen,
+		Approx: true,
+		Values: map[string]interface{}{"type": typ, "data": string(raw)},
+	}).Result()
+	if err != nil {
+		return fmt.Errorf("xadd %s: %w", key, err)
+	}
+	// топики постов живут недолго — пусть Redis сам их забывает
+	b.rdb.Expire(ctx, key, redisStreamTTL)
+
+	payload, err := json.Marshal(Event{ID: id, Topic: topic, Type: typ, Data: raw})
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+	if err := b.rdb.Publish(ctx, redisChannelPrefix+topic, payload).Err(); err != nil {
+		return fmt.Errorf("publish %s: %w", topic, err)
+	}
+	return nil
+}
+
+func (b *RedisBroker) Subscribe(ctx context.Context, topics []string, lastEventID string) (<-chan Event, error) {
+	return b.hub.subscribe(ctx, topics, lastEventID, b.since)
+}
+
+func (b *RedisBroker) since(ctx context.Context, topic string, after eventID) ([]Event, error) {
+	key := redisStreamPrefix + topic
+	msgs, err := b.rdb.XRange(ctx, key, "("+after.String(), "+").Result()
+	if err != nil {
+		return nil, fmt.Errorf("xrange %s: %w", key, err)
+	}
+
+	res := make([]Event, 0, len(msgs))
+	for _, m := range msgs {
+		typ, _ := m.Values["type"].(string)
+		data, _ := m.Values["data"].(string)
+		res = append(res, Event{
+			ID:    m.ID,
+			Topic: strings.TrimPrefix(key, redisStreamPrefix),
+			Type:  typ,
+			Data:  json.RawMessage(data),
+		})
+	}
+	return res, nil
+}
+
+func (b *RedisBroker) Close() error {
+	b.cancel()
+	return b.ps.Close()
+}
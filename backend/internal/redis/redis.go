@@ -1,14 +1,40 @@
 package redis
 
 import (
+	"context"
 	"log"
+	"time"
 
 	"backend/internal/config"
+
+	goredis "github.com/redis/go-redis/v9"
 )
 
-type Client struct{}
+type Client struct {
+	*goredis.Client
+}
+
+func InitRedis(cfg *config.Config) (*Client, error) {
+	rdb := goredis.NewClient(&goredis.Options{
+		Addr: cfg.RedisAddr,
+	})
+
+	var err error
+	for i := 0; i < 10; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		err = rdb.Ping(ctx).Err()
+		cancel()
+		if err == nil {
+			break
+		}
+		log.Printf("Redis not ready yet: %v (attempt %d/10)", err, i+1)
+		time.Sleep(2 * time.Second)
+	}
+	if err != nil {
+		_ = rdb.Close()
+		return nil, err
+	}
 
-func InitRedis(cfg *config.Config) *Client {
-	log.Printf("Redis stub initialized at %s", cfg.RedisAddr)
-	return &Client{}
+	log.Printf("Redis connected at %s", cfg.RedisAddr)
+	return &Client{Client: rdb}, nil
 }
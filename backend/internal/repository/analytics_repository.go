@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type AnalyticsRepository interface {
+	ApplyBatch(stats []model.PostStat, viewers []model.PostViewer) error
+	LastViewedPost(viewerID, authorID uint, since time.Time) (uint, error)
+
+	PostSeries(postID uint, unit string, since time.Time) ([]StatBucket, error)
+	AuthorSeries(authorID uint, unit string, since time.Time) ([]StatBucket, error)
+	AuthorUniqueViewers(authorID uint, since time.Time) (int64, error)
+	AuthorTopPosts(authorID uint, since time.Time, limit int) ([]PostStatTotals, error)
+}
+
+// StatBucket is one point of an insights series. UniqueViewers counts
+// viewers first seen in the bucket.
+type StatBucket struct {
+	Bucket        time.Time
+	Views         int64
+	UniqueViewers int64
+	Likes         int64
+	Comments      int64
+	Bookmarks     int64
+	FollowsGained int64
+}
+
+type PostStatTotals struct {
+	PostID   uint
+	Views    int64
+	Likes    int64
+	Comments int64
+}
+
+type analyticsRepository struct {
+	db *gorm.DB
+}
+
+func NewAnalyticsRepository(db *gorm.DB) AnalyticsRepository {
+	return &analyticsRepository{db: db}
+}
+
+// ApplyBatch adds the buffered counters to the rollups. Rows for posts or
+// users deleted in the meantime are skipped instead of failing the whole batch.
+func (r *analyticsRepository) ApplyBatch(stats []model.PostStat, viewers []model.PostViewer) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, s := range stats {
+			if err := tx.Exec(`
+				INSERT INTO post_stats (post_id, bucket, views, likes, comments, bookmarks, follows_gained)
+				SELECT id, ?, ?, ?, ?, ?, ? FROM posts WHERE id = ?
+				ON CONFLICT (post_id, bucket) DO UPDATE SET
+					views = post_stats.views + EXCLUDED.views,
+					likes = post_stats.likes + EXCLUDED.likes,
+					comments = post_stats.comments + EXCLUDED.comments,
+					bookmarks = post_stats.bookmarks + EXCLUDED.bookmarks,
+					follows_gained = post_stats.follows_gained + EXCLUDED.follows_gained`,
+				s.Bucket, s.Views, s.Likes, s.Comments, s.Bookmarks, s.FollowsGained, s.PostID,
+			).Error; err != nil {
+				return fmt.Errorf("apply post stats %d: %w", s.PostID, err)
+			}
+		}
+
+		for _, v := range viewers {
+			if err := tx.Exec(`
+				INSERT INTO post_viewers (post_id, user_id, first_seen_at, last_seen_at)
+				SELECT posts.id, users.id, ?, ? FROM posts, users WHERE posts.id = ? AND users.id = ?
+				ON CONFLICT (post_id, user_id) DO UPDATE SET
+					last_seen_at = GREATEST(post_viewers.last_seen_at, EXCLUDED.last_seen_at)`,
+				v.FirstSeenAt, v.LastSeenAt, v.PostID, v.UserID,
+			).Error; err != nil {
+				return fmt.Errorf("apply post viewer %d/%d: %w", v.PostID, v.UserID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// LastViewedPost returns the author's post the viewer saw most recently
+// after since.
+func (r *analyticsRepository) LastViewedPost(viewerID, authorID uint, since time.Time) (uint, error) {
+	var postIDs []uint
+	if err := r.db.Table("post_viewers").
+		Select("post_viewers.post_id").
+		Joins("JOIN posts ON posts.id = post_viewers.post_id").
+		Where("post_viewers.user_id = ? AND posts.user_id = ? AND post_viewers.last_seen_at >= ?", viewerID, authorID, since).
+		Order("post_viewers.last_seen_at DESC").
+		Limit(1).
+		Pluck("post_viewers.post_id", &postIDs).Error; err != nil {
+		return 0, fmt.Errorf("last viewed post: %w", err)
+	}
+	if len(postIDs) == 0 {
+		return 0, ErrNotFound
+	}
+	return postIDs[0], nil
+}
+
+func (r *analyticsRepository) PostSeries(postID uint, unit string, since time.Time) ([]StatBucket, error) {
+	stats := r.db.Table("post_stats").Where("post_stats.post_id = ?", postID)
+	viewers := r.db.Table("post_viewers").
+		Joins("JOIN posts ON posts.id = post_viewers.post_id").
+		Where("post_viewers.post_id = ?", postID)
+	return r.series(stats, viewers, unit, since)
+}
+
+func (r *analyticsRepository) AuthorSeries(authorID uint, unit string, since time.Time) ([]StatBucket, error) {
+	stats := r.db.Table("post_stats").
+		Joins("JOIN posts ON posts.id = post_stats.post_id").
+		Where("posts.user_id = ?", authorID)
+	viewers := r.db.Table("post_viewers").
+		Joins("JOIN posts ON posts.id = post_viewers.post_id").
+		Where("posts.user_id = ?", authorID)
+	return r.series(stats, viewers, unit, since)
+}
+
+// series groups the hourly rollups and first views by unit ("hour" or
+// "day", UTC). The author's own views of their posts aren't counted as
+// viewers.
+func (r *analyticsRepository) series(stats, viewers *gorm.DB, unit string, since time.Time) ([]StatBucket, error) {
+	var statRows []StatBucket
+	if err := stats.
+		Select(`date_trunc(?, post_stats.bucket AT TIME ZONE 'UTC') AS bucket,
+			SUM(post_stats.views) AS views,
+			SUM(post_stats.likes) AS likes,
+			SUM(post_stats.comments) AS comments,
+			SUM(post_stats.bookmarks) AS bookmarks,
+			SUM(post_stats.follows_gained) AS follows_gained`, unit).
+		Where("post_stats.bucket >= ?", since).
+		Group("1").
+		Scan(&statRows).Error; err != nil {
+		return nil, fmt.Errorf("stats series: %w", err)
+	}
+
+	type viewerRow struct {
+		Bucket        time.Time
+		UniqueViewers int64
+	}
+	var viewerRows []viewerRow
+	if err := viewers.
+		Select(`date_trunc(?, post_viewers.first_seen_at AT TIME ZONE 'UTC') AS bucket,
+			COUNT(DISTINCT post_viewers.user_id) AS unique_viewers`, unit).
+		Where("post_viewers.first_seen_at >= ? AND post_viewers.user_id <> posts.user_id", since).
+		Group("1").
+		Scan(&viewerRows).Error; err != nil {
+		return nil, fmt.Errorf("viewers series: %w", err)
+	}
+
+	byBucket := make(map[int64]StatBucket, len(statRows))
+	for _, row := range statRows {
+		row.Bucket = row.Bucket.UTC()
+		byBucket[row.Bucket.Unix()] = row
+	}
+	for _, v := range viewerRows {
+		b := v.Bucket.UTC()
+		row, ok := byBucket[b.Unix()]
+		if !ok {
+			row = StatBucket{Bucket: b}
+		}
+		row.UniqueViewers = v.UniqueViewers
+		byBucket[b.Unix()] = row
+	}
+
+	res := make([]StatBucket, 0, len(byBucket))
+	for _, row := range byBucket {
+		res = append(res, row)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Bucket.Before(res[j].Bucket)
+	})
+	return res, nil
+}
+
+func (r *analyticsRepository) AuthorUniqueViewers(authorID uint, since time.Time) (int64, error) {
+	var cnt int64
+	if err := r.db.Table("post_viewers").
+		Joins("JOIN posts ON posts.id = post_viewers.post_id").
+		Where("posts.user_id = ? AND post_viewers.user_id <> posts.user_id AND post_viewers.last_seen_at >= ?", authorID, since).
+		Distinct("post_viewers.user_id").
+		Count(&cnt).Error; err != nil {
+		return 0, fmt.Errorf("author unique viewers: %w", err)
+	}
+	return cnt, nil
+}
+
+func (r *analyticsRepository) AuthorTopPosts(authorID uint, since time.Time, limit int) ([]PostStatTotals, error) {
+	var rows []PostStatTotals
+	if err := r.db.Table("post_stats").
+		Select(`post_stats.post_id,
+			SUM(post_stats.views) AS views,
+			SUM(post_stats.likes) AS likes,
+			SUM(post_stats.comments) AS comments`).
+		Joins("JOIN posts ON posts.id = post_stats.post_id").
+		Where("posts.user_id = ? AND post_stats.bucket >= ?", authorID, since).
+		Group("post_stats.post_id").
+		Order("views DESC, post_stats.post_id DESC").
+		Limit(limit).
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("author top posts: %w", err)
+	}
+	return rows, nil
+}
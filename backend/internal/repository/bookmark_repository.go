@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"fmt"
+
+	"backend/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BookmarkRepository interface {
+	Save(userID, postID uint, collectionID *uint) error
+	Remove(userID, postID uint) error
+	BookmarkedByUser(postIDs []uint, userID uint) (map[uint]bool, error)
+	List(userID uint, collectionID *uint, limit int, cursor *Cursor) ([]model.Bookmark, error)
+
+	CreateCollection(c *model.BookmarkCollection) error
+	GetCollection(id uint) (*model.BookmarkCollection, error)
+	RenameCollection(id uint, name string) error
+	DeleteCollection(id uint) error
+	ListCollections(userID uint) ([]model.BookmarkCollection, error)
+	CollectionCounts(collectionIDs []uint) (map[uint]int64, error)
+}
+
+type bookmarkRepository struct {
+	db *gorm.DB
+}
+
+func NewBookmarkRepository(db *gorm.DB) BookmarkRepository {
+	return &bookmarkRepository{db: db}
+}
+
+// Save bookmarks the post, or moves an existing bookmark to collectionID.
+func (r *bookmarkRepository) Save(userID, postID uint, collectionID *uint) error {
+	if userID == 0 || postID == 0 {
+		return fmt.Errorf("invalid ids")
+	}
+	b := model.Bookmark{UserID: userID, PostID: postID, CollectionID: collectionID}
+	if err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "post_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"collection_id"}),
+	}).Create(&b).Error; err != nil {
+		return fmt.Errorf("save bookmark: %w", err)
+	}
+	return nil
+}
+
+func (r *bookmarkRepository) Remove(userID, postID uint) error {
+	if err := r.db.Where("user_id = ? AND post_id = ?", userID, postID).
+		Delete(&model.Bookmark{}).Error; err != nil {
+		return fmt.Errorf("remove bookmark: %w", err)
+	}
+	return nil
+}
+
+func (r *bookmarkRepository) BookmarkedByUser(postIDs []uint, userID uint) (map[uint]bool, error) {
+	res := make(map[uint]bool)
+	if len(postIDs) == 0 || userID == 0 {
+		return res, nil
+	}
+
+	var ids []uint
+	if err := r.db.Model(&model.Bookmark{}).
+		Where("user_id = ? AND post_id IN ?", userID, postIDs).
+		Pluck("post_id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("bookmarked by user: %w", err)
+	}
+
+	for _, id := range ids {
+		res[id] = true
+	}
+	return res, nil
+}
+
+// List returns the user's bookmarks newest first with the posts preloaded.
+func (r *bookmarkRepository) List(userID uint, collectionID *uint, limit int, cursor *Cursor) ([]model.Bookmark, error) {
+	var items []model.Bookmark
+
+	// закладки на посты, которые стали недоступны, просто не показываем
+	q := r.db.
+		Joins("JOIN posts ON posts.id = bookmarks.post_id").
+		Where("bookmarks.user_id = ?", userID).
+		Where(postVisibleTo(userID)).
+		Preload("Post.User").
+		Preload("Post.Files").
+		Order("bookmarks.created_at DESC, bookmarks.id DESC")
+
+	if collectionID != nil {
+		q = q.Where("bookmarks.collection_id = ?", *collectionID)
+	}
+	if cursor != nil {
+		q = q.Where("(bookmarks.created_at, bookmarks.id) < (?, ?)", cursor.Time, cursor.ID)
+	}
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+
+	if err := q.Find(&items).Error; err != nil {
+		return nil, fmt.Errorf("list bookmarks: %w", err)
+	}
+	return items, nil
+}
+
+func (r *bookmarkRepository) CreateCollection(c *model.BookmarkCollection) error {
+	if c == nil {
+		return fmt.Errorf("collection is nil")
+	}
+	if err := r.db.Create(c).Error; err != nil {
+		return fmt.Errorf("create collection: %w", err)
+	}
+	return nil
+}
+
+func (r *bookmarkRepository) GetCollection(id uint) (*model.BookmarkCollection, error) {
+	var c model.BookmarkCollection
+	if err := r.db.First(&c, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get collection: %w", err)
+	}
+	return &c, nil
+}
+
+func (r *bookmarkRepository) RenameCollection(id uint, name string) error {
+	if err := r.db.Model(&model.BookmarkCollection{}).
+		Where("id = ?", id).
+		Update("name", name).Error; err != nil {
+		return fmt.Errorf("rename collection: %w", err)
+	}
+	return nil
+}
+
+// DeleteCollection keeps the bookmarks, they just lose their collection.
+func (r *bookmarkRepository) DeleteCollection(id uint) error {
+	if err := r.db.Delete(&model.BookmarkCollection{}, id).Error; err != nil {
+		return fmt.Errorf("delete collection: %w", err)
+	}
+	return nil
+}
+
+func (r *bookmarkRepository) ListCollections(userID uint) ([]model.BookmarkCollection, error) {
+	var items []model.BookmarkCollection
+	if err := r.db.
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(&items).Error; err != nil {
+		return nil, fmt.Errorf("list collections: %w", err)
+	}
+	return items, nil
+}
+
+func (r *bookmarkRepository) CollectionCounts(collectionIDs []uint) (map[uint]int64, error) {
+	res := make(map[uint]int64)
+	if len(collectionIDs) == 0 {
+		return res, nil
+	}
+
+	type row struct {
+		CollectionID uint
+		Count        int64
+	}
+
+	var rows []row
+	if err := r.db.Model(&model.Bookmark{}).
+		Select("collection_id, count(*) as count").
+		Where("collection_id IN ?", collectionIDs).
+		Group("collection_id").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("collection counts: %w", err)
+	}
+
+	for _, row := range rows {
+		res[row.CollectionID] = row.Count
+	}
+	return res, nil
+}
@@ -17,6 +17,11 @@ type CommentLikeRepository interface {
 
 	LikesCountsForComments(commentIDs []uint) (map[uint]int, error)
 	LikedByUser(commentIDs []uint, userID uint) (map[uint]bool, error)
+
+	React(commentID, userID uint, reaction string) (bool, error)
+	ReactionCounts(commentIDs []uint) (map[uint]map[string]int, error)
+	ReactionsByUser(commentIDs []uint, userID uint) (map[uint]string, error)
+	Likers(commentID, viewerID uint, limit int, cursor *LikerCursor) ([]Liker, error)
 }
 
 type commentLikeRepository struct {
@@ -35,6 +40,7 @@ func (r *commentLikeRepository) LikeComment(commentID uint, userID uint) error {
 	like := model.CommentLike{
 		CommentID: commentID,
 		UserID:    userID,
+		Reaction:  model.ReactionLike,
 	}
 
 	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&like).Error; err != nil {
@@ -125,3 +131,74 @@ func (r *commentLikeRepository) LikedByUser(commentIDs []uint, userID uint) (map
 	}
 	return res, nil
 }
+
+// React sets the user's reaction on the comment, replacing an earlier one,
+// and reports whether the user hadn't reacted before.
+func (r *commentLikeRepository) React(commentID, userID uint, reaction string) (bool, error) {
+	if commentID == 0 || userID == 0 {
+		return false, fmt.Errorf("invalid ids")
+	}
+	var created bool
+	if err := r.db.Raw(`
+		INSERT INTO comment_likes (comment_id, user_id, reaction)
+		VALUES (?, ?, ?)
+		ON CONFLICT (comment_id, user_id) DO UPDATE SET reaction = EXCLUDED.reaction
+		RETURNING (xmax = 0)`,
+		commentID, userID, reaction,
+	).Scan(&created).Error; err != nil {
+		return false, fmt.Errorf("react to comment: %w", err)
+	}
+	return created, nil
+}
+
+func (r *commentLikeRepository) ReactionCounts(commentIDs []uint) (map[uint]map[string]int, error) {
+	result := make(map[uint]map[string]int)
+	if len(commentIDs) == 0 {
+		return result, nil
+	}
+
+	var rows []struct {
+		CommentID uint
+		Reaction  string
+		Count     int
+	}
+	if err := r.db.Model(&model.CommentLike{}).
+		Select("comment_id, reaction, COUNT(*) AS count").
+		Where("comment_id IN ?", commentIDs).
+		Group("comment_id, reaction").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("comment reaction counts: %w", err)
+	}
+
+	for _, row := range rows {
+		if result[row.CommentID] == nil {
+			result[row.CommentID] = make(map[string]int)
+		}
+		result[row.CommentID][row.Reaction] = row.Count
+	}
+	return result, nil
+}
+
+func (r *commentLikeRepository) ReactionsByUser(commentIDs []uint, userID uint) (map[uint]string, error) {
+	res := make(map[uint]string)
+	if len(commentIDs) == 0 || userID == 0 {
+		return res, nil
+	}
+
+	var likes []model.CommentLike
+	if err := r.db.
+		Select("comment_id, reaction").
+		Where("user_id = ? AND comment_id IN ?", userID, commentIDs).
+		Find(&likes).Error; err != nil {
+		return nil, fmt.Errorf("reactions by user: %w", err)
+	}
+
+	for _, l := range likes {
+		res[l.CommentID] = l.Reaction
+	}
+	return res, nil
+}
+
+func (r *commentLikeRepository) Likers(commentID, viewerID uint, limit int, cursor *LikerCursor) ([]Liker, error) {
+	return listLikers(r.db, "comment_likes", "comment_id", commentID, viewerID, limit, cursor)
+}
@@ -2,18 +2,86 @@ package repository
 
 import (
 	"fmt"
+	"time"
 
 	"backend/internal/model"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// Orders of top-level comments.
+const (
+	CommentSortTop = "top"
+	CommentSortNew = "new"
+	CommentSortOld = "old"
+)
+
+// CommentListOptions picks the order of top-level comments.
+type CommentListOptions struct {
+	Sort     string
+	ViewerID uint
+	// PinnedID is left out of the listing; the thread shows it separately.
+	PinnedID *uint
+	// BoostUserIDs: comments by these users come before all others.
+	BoostUserIDs []uint
+	// Now is the moment the top score is taken at. It is fixed for the
+	// whole listing: scores decay to it and count only likes and replies
+	// made by then, so new activity doesn't move comments between pages.
+	// Removed likes and replies still lower a score, and a comment can then
+	// show up twice or be skipped.
+	Now time.Time
+}
+
+// CommentCursor is a keyset position in one of the root comment orders.
+// Score is only used by CommentSortTop, Time only by the other two.
+type CommentCursor struct {
+	Boosted bool
+	Score   float64
+	Cursor
+}
+
+// RankedComment is a root comment with the keys of its position in the
+// order, so the service can build the next cursor from it.
+type RankedComment struct {
+	model.Comment
+	Boosted bool
+	Score   float64
+}
+
+// commentScoreSQL ranks comments for CommentSortTop: likes plus replies
+// (which weigh double), decayed by the comment's age in hours. All three
+// placeholders take the time the score is taken at; activity after it
+// isn't counted.
+const commentScoreSQL = `(1
+	+ (SELECT COUNT(*) FROM comment_likes WHERE comment_likes.comment_id = comments.id AND comment_likes.created_at <= ?)
+	+ 2 * (SELECT COUNT(*) FROM comments replies WHERE replies.parent_id = comments.id AND replies.created_at <= ?)
+) / POWER(GREATEST(EXTRACT(EPOCH FROM (?::timestamptz - comments.created_at)) / 3600, 0) + 2, 1.5)`
+
+// visibleCommentSQL is true for comments the viewer may see: hidden ones
+// only show to their writer and to the post author. It refers to the
+// comments table by name.
+const visibleCommentSQL = `(comments.hidden_at IS NULL OR comments.user_id = ? OR EXISTS (
+	SELECT 1 FROM posts WHERE posts.id = comments.post_id AND posts.user_id = ?
+))`
+
+func commentVisibleTo(viewerID uint) clause.Expr {
+	return gorm.Expr(visibleCommentSQL, viewerID, viewerID)
+}
+
 type CommentRepository interface {
 	Add(comment *model.Comment) error
-	GetRootComments(postID uint, limit, offset int) ([]model.Comment, error)
-	GetCommentsByPostID(postID uint) ([]model.Comment, error)
-	GetReplies(parentID uint) ([]model.Comment, error)
-	GetCommentsFlat(postID uint) ([]model.Comment, error)
+	GetByID(id uint) (*model.Comment, error)
+	GetRootComments(postID uint, opts CommentListOptions, limit int, cursor *CommentCursor) ([]RankedComment, error)
+	GetReplies(parentID, viewerID uint, limit int, cursor *Cursor) ([]model.Comment, error)
+	FirstReplies(parentIDs []uint, viewerID uint, perParent int) ([]model.Comment, error)
+	RepliesCounts(parentIDs []uint, viewerID uint) (map[uint]int, error)
+	Depth(id uint) (int, error)
+
+	UpdateText(id uint, text string) error
+	ListRevisions(commentID uint) ([]model.CommentRevision, error)
+	SetHidden(id uint, hidden bool) error
+	Delete(id uint) (bool, error)
 }
 
 type commentRepository struct {
@@ -34,56 +102,301 @@ func (r *commentRepository) Add(comment *model.Comment) error {
 	return nil
 }
 
-func (r *commentRepository) GetRootComments(postID uint, limit, offset int) ([]model.Comment, error) {
-	var comments []model.Comment
-	if err := r.db.
-		Where("post_id = ? AND parent_id IS NULL", postID).
-		Preload("User").
-		Preload("Likes").
-		Order("created_at DESC").
-		Limit(limit).
-		Offset(offset).
-		Find(&comments).Error; err != nil {
+func (r *commentRepository) GetByID(id uint) (*model.Comment, error) {
+	var comment model.Comment
+	if err := r.db.Preload("User").First(&comment, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get comment by id: %w", err)
+	}
+	return &comment, nil
+}
+
+// GetRootComments pages through a post's top-level comments in opts.Sort
+// order, boosted authors first.
+func (r *commentRepository) GetRootComments(postID uint, opts CommentListOptions, limit int, cursor *CommentCursor) ([]RankedComment, error) {
+	var comments []RankedComment
+
+	q := r.db.Table("(?) AS comments", r.rankedRoots(postID, opts)).Preload("User")
+
+	key, cmp, order := "created_at", "<", "boosted DESC, created_at DESC, id DESC"
+	switch opts.Sort {
+	case CommentSortTop:
+		key, cmp, order = "score", "<", "boosted DESC, score DESC, id DESC"
+	case CommentSortOld:
+		key, cmp, order = "created_at", ">", "boosted DESC, created_at, id"
+	}
+
+	if cursor != nil {
+		var keyArg interface{} = cursor.Time
+		if opts.Sort == CommentSortTop {
+			keyArg = cursor.Score
+		}
+		cond := fmt.Sprintf("(%s, id) %s (?, ?)", key, cmp)
+		if cursor.Boosted {
+			q = q.Where("NOT boosted OR "+cond, keyArg, cursor.ID)
+		} else {
+			q = q.Where("NOT boosted AND "+cond, keyArg, cursor.ID)
+		}
+	}
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+
+	if err := q.Order(order).Find(&comments).Error; err != nil {
 		return nil, fmt.Errorf("get root comments: %w", err)
 	}
 	return comments, nil
 }
 
-func (r *commentRepository) GetCommentsByPostID(postID uint) ([]model.Comment, error) {
-	var comments []model.Comment
-	if err := r.db.
-		Where("post_id = ?", postID).
-		Preload("User").
-		Preload("Likes").
-		Order("created_at ASC").
-		Find(&comments).Error; err != nil {
-		return nil, fmt.Errorf("get comments by post id: %w", err)
+// rankedRoots selects a post's top-level comments visible to the viewer with
+// the boosted flag and, for the top order, the score.
+func (r *commentRepository) rankedRoots(postID uint, opts CommentListOptions) *gorm.DB {
+	q := r.db.Model(&model.Comment{}).
+		Where("post_id = ? AND parent_id IS NULL", postID).
+		Where(commentVisibleTo(opts.ViewerID))
+	if opts.PinnedID != nil {
+		q = q.Where("id <> ?", *opts.PinnedID)
 	}
-	return comments, nil
+
+	sel := "comments.*"
+	var args []interface{}
+	if len(opts.BoostUserIDs) > 0 {
+		sel += ", comments.user_id IN ? AS boosted"
+		args = append(args, opts.BoostUserIDs)
+	} else {
+		sel += ", FALSE AS boosted"
+	}
+	if opts.Sort == CommentSortTop {
+		sel += ", " + commentScoreSQL + " AS score"
+		args = append(args, opts.Now, opts.Now, opts.Now)
+	}
+	return q.Select(sel, args...)
 }
 
-func (r *commentRepository) GetReplies(parentID uint) ([]model.Comment, error) {
+// GetReplies pages through direct replies in the order they were written,
+// so unlike elsewhere the cursor points at the last row already shown and
+// newer rows come next.
+func (r *commentRepository) GetReplies(parentID, viewerID uint, limit int, cursor *Cursor) ([]model.Comment, error) {
 	var replies []model.Comment
-	if err := r.db.
+
+	q := r.db.
 		Where("parent_id = ?", parentID).
+		Where(commentVisibleTo(viewerID)).
 		Preload("User").
-		Preload("Likes").
-		Order("created_at ASC").
-		Find(&replies).Error; err != nil {
+		Order("created_at, id")
+
+	if cursor != nil {
+		q = q.Where("(created_at, id) > (?, ?)", cursor.Time, cursor.ID)
+	}
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+
+	if err := q.Find(&replies).Error; err != nil {
 		return nil, fmt.Errorf("get replies: %w", err)
 	}
 	return replies, nil
 }
 
-func (r *commentRepository) GetCommentsFlat(postID uint) ([]model.Comment, error) {
-	var comments []model.Comment
+// FirstReplies returns up to perParent oldest direct replies of each parent
+// in one query.
+func (r *commentRepository) FirstReplies(parentIDs []uint, viewerID uint, perParent int) ([]model.Comment, error) {
+	var replies []model.Comment
+	if len(parentIDs) == 0 || perParent <= 0 {
+		return replies, nil
+	}
+
+	ranked := r.db.Model(&model.Comment{}).
+		Select("id, ROW_NUMBER() OVER (PARTITION BY parent_id ORDER BY created_at, id) AS rn").
+		Where("parent_id IN ?", parentIDs).
+		Where(commentVisibleTo(viewerID))
+	ids := r.db.Table("(?) AS ranked", ranked).Select("id").Where("rn <= ?", perParent)
+
 	if err := r.db.
-		Where("post_id = ?", postID).
+		Where("id IN (?)", ids).
 		Preload("User").
-		Preload("Likes").
-		Order("created_at ASC").
-		Find(&comments).Error; err != nil {
-		return nil, fmt.Errorf("get comments flat: %w", err)
+		Order("created_at, id").
+		Find(&replies).Error; err != nil {
+		return nil, fmt.Errorf("first replies: %w", err)
 	}
-	return comments, nil
+	return replies, nil
+}
+
+// RepliesCounts returns the number of direct replies per parent id that the
+// viewer can see.
+func (r *commentRepository) RepliesCounts(parentIDs []uint, viewerID uint) (map[uint]int, error) {
+	res := make(map[uint]int)
+	if len(parentIDs) == 0 {
+		return res, nil
+	}
+
+	var rows []struct {
+		ParentID uint
+		Count    int
+	}
+	if err := r.db.Model(&model.Comment{}).
+		Select("parent_id, COUNT(*) AS count").
+		Where("parent_id IN ?", parentIDs).
+		Where(commentVisibleTo(viewerID)).
+		Group("parent_id").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("replies counts: %w", err)
+	}
+	for _, row := range rows {
+		res[row.ParentID] = row.Count
+	}
+	return res, nil
+}
+
+// Depth returns the nesting level of a comment, 1 for a top-level one.
+func (r *commentRepository) Depth(id uint) (int, error) {
+	var depth int
+	if err := r.db.Raw(`
+		WITH RECURSIVE chain AS (
+			SELECT id, parent_id, 1 AS depth FROM comments WHERE id = ?
+			UNION ALL
+			SELECT comments.id, comments.parent_id, chain.depth + 1
+			FROM comments
+			JOIN chain ON comments.id = chain.parent_id
+		)
+		SELECT COALESCE(MAX(depth), 0) FROM chain`, id).Scan(&depth).Error; err != nil {
+		return 0, fmt.Errorf("comment depth: %w", err)
+	}
+	if depth == 0 {
+		return 0, ErrNotFound
+	}
+	return depth, nil
+}
+
+// UpdateText edits a live comment, stamps edited_at and saves the new text
+// as a revision. The original text is stored as the first revision on the
+// first edit; the row is locked so that it is read in the same transaction.
+func (r *commentRepository) UpdateText(id uint, text string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var c model.Comment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "text", "created_at").
+			Where("deleted_at IS NULL").
+			First(&c, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return ErrNotFound
+			}
+			return fmt.Errorf("lock comment: %w", err)
+		}
+
+		var cnt int64
+		if err := tx.Model(&model.CommentRevision{}).Where("comment_id = ?", c.ID).Count(&cnt).Error; err != nil {
+			return fmt.Errorf("count revisions: %w", err)
+		}
+		if cnt == 0 {
+			original := model.CommentRevision{CommentID: c.ID, Text: c.Text, CreatedAt: c.CreatedAt}
+			if err := tx.Create(&original).Error; err != nil {
+				return fmt.Errorf("save original revision: %w", err)
+			}
+		}
+
+		now := time.Now()
+		rev := model.CommentRevision{CommentID: c.ID, Text: text, CreatedAt: now}
+		if err := tx.Create(&rev).Error; err != nil {
+			return fmt.Errorf("save revision: %w", err)
+		}
+
+		if err := tx.Model(&model.Comment{}).Where("id = ?", c.ID).Updates(map[string]interface{}{
+			"text":      text,
+			"edited_at": now,
+		}).Error; err != nil {
+			return fmt.Errorf("update comment: %w", err)
+		}
+		return nil
+	})
+}
+
+// ListRevisions returns the comment's revisions, newest first.
+func (r *commentRepository) ListRevisions(commentID uint) ([]model.CommentRevision, error) {
+	var revs []model.CommentRevision
+	if err := r.db.Where("comment_id = ?", commentID).
+		Order("created_at DESC, id DESC").
+		Find(&revs).Error; err != nil {
+		return nil, fmt.Errorf("list revisions: %w", err)
+	}
+	return revs, nil
+}
+
+// SetHidden hides or unhides a comment.
+func (r *commentRepository) SetHidden(id uint, hidden bool) error {
+	var hiddenAt interface{}
+	if hidden {
+		hiddenAt = gorm.Expr("COALESCE(hidden_at, NOW())")
+	}
+	res := r.db.Model(&model.Comment{}).Where("id = ?", id).Update("hidden_at", hiddenAt)
+	if res.Error != nil {
+		return fmt.Errorf("set comment hidden: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Delete removes a comment and reports whether it was only soft-deleted.
+// A comment with replies is blanked and kept as a placeholder so the thread
+// stays connected; a leaf is deleted for real, and so are placeholders above
+// it that are left without replies.
+func (r *commentRepository) Delete(id uint) (bool, error) {
+	soft := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		cur := id
+		for {
+			// FOR UPDATE не даёт параллельному ответу прицепиться к удаляемой строке
+			var c model.Comment
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Select("id", "parent_id", "deleted_at").
+				First(&c, cur).Error; err != nil {
+				if err == gorm.ErrRecordNotFound {
+					return ErrNotFound
+				}
+				return fmt.Errorf("lock comment: %w", err)
+			}
+
+			var replies int64
+			if err := tx.Model(&model.Comment{}).Where("parent_id = ?", c.ID).Count(&replies).Error; err != nil {
+				return fmt.Errorf("count replies: %w", err)
+			}
+
+			if replies > 0 {
+				if c.ID != id || c.DeletedAt != nil {
+					return nil
+				}
+				soft = true
+				if err := tx.Model(&model.Comment{}).
+					Where("id = ?", c.ID).
+					Updates(map[string]interface{}{
+						"text":       "",
+						"deleted_at": gorm.Expr("NOW()"),
+					}).Error; err != nil {
+					return fmt.Errorf("soft delete comment: %w", err)
+				}
+				// заглушка остаётся, а прежние версии текста уходят вместе с ним
+				if err := tx.Where("comment_id = ?", c.ID).Delete(&model.CommentRevision{}).Error; err != nil {
+					return fmt.Errorf("delete comment revisions: %w", err)
+				}
+				return nil
+			}
+
+			// заглушку выше по ветке удаляем, только если она уже удалена
+			if c.ID != id && c.DeletedAt == nil {
+				return nil
+			}
+			if err := tx.Delete(&model.Comment{}, c.ID).Error; err != nil {
+				return fmt.Errorf("delete comment: %w", err)
+			}
+			if c.ParentID == nil {
+				return nil
+			}
+			cur = *c.ParentID
+		}
+	})
+	return soft, err
 }
@@ -0,0 +1,9 @@
+package repository
+
+import "time"
+
+// Cursor is a keyset position: rows strictly "older" than (Time, ID) come next.
+type Cursor struct {
+	Time time.Time
+	ID   uint
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// Categories of trending posts, by their media.
+const (
+	ExploreCategoryPhoto = "photo"
+	ExploreCategoryVideo = "video"
+	ExploreCategoryText  = "text"
+)
+
+type ExploreRepository interface {
+	RefreshTrending(since, now time.Time, limit int) error
+	Trending(viewerID uint, category string, limit int, cursor *ExploreCursor) ([]TrendingPost, error)
+}
+
+// ExploreCursor is a keyset position in the trending set: rows with a lower
+// (Score, ID) come next.
+type ExploreCursor struct {
+	Score float64
+	ID    uint
+}
+
+// TrendingPost is a post of the trending set with its score.
+type TrendingPost struct {
+	model.Post
+	Score float64
+}
+
+type exploreRepository struct {
+	db *gorm.DB
+}
+
+func NewExploreRepository(db *gorm.DB) ExploreRepository {
+	return &exploreRepository{db: db}
+}
+
+// trendingSQL scores public posts from the window by engagement velocity:
+// likes, comments and reposts per hour since the post (or the window) began,
+// divided by the square root of the author's audience so that big accounts
+// don't fill the page on reach alone. Each category keeps its own top
+// @limit, so a busy one can't push the others out of the set.
+const trendingSQL = `
+	INSERT INTO trending_posts (post_id, category, score, computed_at)
+	SELECT post_id, category, score, @now
+	FROM (
+		SELECT scored.*,
+		       ROW_NUMBER() OVER (PARTITION BY category ORDER BY score DESC, post_id DESC) AS rank
+		FROM (
+			SELECT posts.id AS post_id,
+			       CASE
+			           WHEN EXISTS (SELECT 1 FROM files WHERE files.post_id = posts.id AND LOWER(files.url) LIKE '%.mp4') THEN @video
+			           WHEN EXISTS (SELECT 1 FROM files WHERE files.post_id = posts.id) THEN @photo
+			           ELSE @text
+			       END AS category,
+			       COUNT(*)::float8
+			       / GREATEST(EXTRACT(EPOCH FROM (@now - GREATEST(posts.created_at, @since)))::float8 / 3600, 1)
+			       / SQRT(1 + (SELECT COUNT(*) FROM followers WHERE followers.user_id = posts.user_id)) AS score
+			FROM posts
+			JOIN (
+				SELECT post_id FROM post_likes WHERE created_at >= @since
+				UNION ALL
+				SELECT post_id FROM comments WHERE created_at >= @since
+				UNION ALL
+				SELECT post_id FROM reposts WHERE created_at >= @since
+			) engaged ON engaged.post_id = posts.id
+			WHERE posts.status = @published AND posts.visibility = @public AND posts.created_at >= @since
+			GROUP BY posts.id
+		) scored
+	) ranked
+	WHERE rank <= @limit`
+
+// RefreshTrending replaces the trending set with the top limit posts of each
+// category created since since. A replica that finds another one refreshing
+// skips its turn.
+func (r *exploreRepository) RefreshTrending(since, now time.Time, limit int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var locked bool
+		if err := tx.Raw("SELECT pg_try_advisory_xact_lock(hashtext('trending_posts'))").Scan(&locked).Error; err != nil {
+			return fmt.Errorf("lock trending: %w", err)
+		}
+		if !locked {
+			return nil
+		}
+		if err := tx.Exec("DELETE FROM trending_posts").Error; err != nil {
+			return fmt.Errorf("clear trending: %w", err)
+		}
+		if err := tx.Exec(trendingSQL, map[string]interface{}{
+			"since":     since,
+			"now":       now,
+			"limit":     limit,
+			"video":     ExploreCategoryVideo,
+			"photo":     ExploreCategoryPhoto,
+			"text":      ExploreCategoryText,
+			"published": model.PostStatusPublished,
+			"public":    model.PostVisibilityPublic,
+		}).Error; err != nil {
+			return fmt.Errorf("compute trending: %w", err)
+		}
+		return nil
+	})
+}
+
+// Trending pages the trending set, best first, keeping the posts the viewer
+// may see. An empty category means all of them.
+func (r *exploreRepository) Trending(viewerID uint, category string, limit int, cursor *ExploreCursor) ([]TrendingPost, error) {
+	q := r.db.Table("posts").
+		Select("posts.*, trending_posts.score").
+		Joins("JOIN trending_posts ON trending_posts.post_id = posts.id").
+		Where(postVisibleTo(viewerID)).
+		Order("trending_posts.score DESC, posts.id DESC").
+		Limit(limit).
+		Preload("User").
+		Preload("Files")
+	if category != "" {
+		q = q.Where("trending_posts.category = ?", category)
+	}
+	if cursor != nil {
+		q = q.Where("(trending_posts.score, posts.id) < (?, ?)", cursor.Score, cursor.ID)
+	}
+
+	var res []TrendingPost
+	if err := q.Find(&res).Error; err != nil {
+		return nil, fmt.Errorf("get trending posts: %w", err)
+	}
+	return res, nil
+}
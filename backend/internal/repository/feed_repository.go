@@ -1,15 +1,105 @@
 package repository
 
 import (
-	"backend/internal/model"
+	"fmt"
 	"time"
 
+	"backend/internal/model"
+
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type FeedRepository interface {
-	GetFollowingPosts(userID uint, limit int, cursor *time.Time) ([]model.Post, error)
-	GetRecommendedPosts(userID uint, limit int, excludeIDs []uint) ([]model.Post, error)
+	FollowingEntries(userID uint, authorIDs []uint, limit int, cursor *Cursor) ([]FeedEntry, error)
+	FollowingActivity(userID uint, authorIDs, postIDs []uint) ([]FeedEntry, error)
+	FollowersActivity(postID uint, userIDs []uint) ([]FollowerEntry, error)
+	Hydrate(q FeedQuery, entries []FeedEntry) ([]FeedItem, error)
+	GetPosts(q FeedQuery, ids []uint) ([]model.Post, error)
+	LatestPosts(q FeedQuery, limit int, cursor *Cursor) ([]model.Post, error)
+	FollowedCelebrities(userID uint) ([]uint, error)
+	IsCelebrity(userID uint) (bool, error)
+	RefreshCelebrities(minFollowers int) error
+
+	EngagedByFollowees(userID uint, since time.Time, limit int, excludeIDs []uint) ([]FeedCandidate, error)
+	SameCohort(userID uint, since time.Time, limit int, excludeIDs []uint) ([]FeedCandidate, error)
+	Trending(userID uint, since time.Time, limit int, excludeIDs []uint) ([]FeedCandidate, error)
+	Signals(postIDs []uint, viewerID uint, recentSince, affinitySince time.Time) ([]PostSignals, error)
+}
+
+// Feed scopes: which authors a feed query takes posts from.
+const (
+	FeedScopeAll    = ""
+	FeedScopeCampus = "campus"
+)
+
+// Feed media filters.
+const (
+	FeedMediaAny  = ""
+	FeedMediaOnly = "media"
+	FeedTextOnly  = "text"
+)
+
+// FeedQuery narrows the posts of a feed query: the viewer they must be
+// visible to, the authors (Scope) and whether they have files (Media).
+type FeedQuery struct {
+	ViewerID uint
+	Scope    string
+	Media    string
+}
+
+// Why a recommended post was proposed for the feed.
+const (
+	FeedReasonEngagedByFollowee = "liked_by_following"
+	FeedReasonSameMajor         = "same_major"
+	FeedReasonSameGrade         = "same_grade"
+	FeedReasonTrending          = "trending"
+)
+
+// FeedCandidate is a post proposed for the feed by one of the candidate
+// generators. Detail explains Reason: the followee who engaged, or the
+// shared major or grade.
+type FeedCandidate struct {
+	PostID uint
+	Reason string
+	Detail string
+}
+
+// PostSignals are the inputs of feed scoring for one post.
+type PostSignals struct {
+	PostID    uint
+	UserID    uint
+	CreatedAt time.Time
+	// Engagement counts likes, comments and reposts; RecentEngagement only
+	// those since recentSince.
+	Engagement       int
+	RecentEngagement int
+	// Affinity counts the viewer's likes and comments on the author's posts
+	// since affinitySince.
+	Affinity int
+}
+
+// FeedItem is a post in the following feed. RepostedBy is set when the post
+// got into the feed through a followee's repost; ActivityAt is the time the
+// item is ordered by (post or repost time).
+type FeedItem struct {
+	Post       model.Post
+	RepostedBy *model.User
+	ActivityAt time.Time
+}
+
+// FeedEntry is a following feed item before its post is loaded.
+type FeedEntry struct {
+	PostID     uint
+	AuthorID   uint
+	RepostedBy *uint
+	ActivityAt time.Time
+}
+
+// FollowerEntry is a following feed entry of the user UserID.
+type FollowerEntry struct {
+	UserID uint
+	FeedEntry
 }
 
 type feedRepository struct {
@@ -20,76 +110,376 @@ func NewFeedRepository(db *gorm.DB) FeedRepository {
 	return &feedRepository{db: db}
 }
 
-func (r *feedRepository) GetFollowingPosts(
+// followingEntriesSQL merges followees' posts and followees' reposts; a post
+// reachable several ways shows up once, at its latest activity. The third
+// and sixth parameters narrow the rows.
+const followingEntriesSQL = `
+	SELECT DISTINCT ON (e.post_id) e.post_id, e.author_id, e.reposted_by, e.activity_at
+	FROM (
+		SELECT posts.id AS post_id, posts.user_id AS author_id, NULL::int AS reposted_by, posts.created_at AS activity_at
+		FROM posts
+		JOIN followers ON followers.user_id = posts.user_id
+		WHERE followers.follower_id = ? AND ? AND ?
+		UNION ALL
+		SELECT reposts.post_id, posts.user_id, reposts.user_id, reposts.created_at
+		FROM reposts
+		JOIN followers ON followers.user_id = reposts.user_id
+		JOIN posts ON posts.id = reposts.post_id
+		WHERE followers.follower_id = ? AND ? AND ?
+	) e
+	ORDER BY e.post_id, e.activity_at DESC`
+
+// FollowingEntries lists the following feed of userID without loading the
+// posts, newest activity first. A non-empty authorIDs keeps only the
+// activity of those followees.
+func (r *feedRepository) FollowingEntries(
 	userID uint,
+	authorIDs []uint,
 	limit int,
-	cursor *time.Time,
-) ([]model.Post, error) {
+	cursor *Cursor,
+) ([]FeedEntry, error) {
 
-	var posts []model.Post
+	filter := gorm.Expr("TRUE")
+	if len(authorIDs) > 0 {
+		filter = gorm.Expr("followers.user_id IN ?", authorIDs)
+	}
+	return r.followingEntries(userID, filter, limit, cursor)
+}
+
+// FollowingActivity returns the latest activity of the given posts in the
+// following feed of userID, one entry per post found. A non-empty authorIDs
+// keeps only the activity of those followees.
+func (r *feedRepository) FollowingActivity(userID uint, authorIDs, postIDs []uint) ([]FeedEntry, error) {
+	if len(postIDs) == 0 {
+		return []FeedEntry{}, nil
+	}
+	filter := gorm.Expr("posts.id IN ?", postIDs)
+	if len(authorIDs) > 0 {
+		filter = gorm.Expr("followers.user_id IN ? AND posts.id IN ?", authorIDs, postIDs)
+	}
+	return r.followingEntries(userID, filter, 0, nil)
+}
+
+// followersActivitySQL is followingEntriesSQL turned around: the latest
+// activity of one post in the following feed of each of the given users.
+const followersActivitySQL = `
+	SELECT DISTINCT ON (e.user_id) e.user_id, e.post_id, e.author_id, e.reposted_by, e.activity_at
+	FROM (
+		SELECT followers.follower_id AS user_id, posts.id AS post_id, posts.user_id AS author_id,
+		       NULL::int AS reposted_by, posts.created_at AS activity_at
+		FROM posts
+		JOIN followers ON followers.user_id = posts.user_id
+		WHERE posts.id = @post AND posts.status = 'published' AND followers.follower_id IN @users
+		UNION ALL
+		SELECT followers.follower_id, reposts.post_id, posts.user_id, reposts.user_id, reposts.created_at
+		FROM reposts
+		JOIN followers ON followers.user_id = reposts.user_id
+		JOIN posts ON posts.id = reposts.post_id
+		WHERE reposts.post_id = @post AND posts.status = 'published' AND followers.follower_id IN @users
+	) e
+	ORDER BY e.user_id, e.activity_at DESC`
+
+// FollowersActivity returns, for each of userIDs that still reaches the
+// post through a followee, the post's latest activity in their feed. It is
+// for putting timeline entries back, so visibility is left to the read.
+func (r *feedRepository) FollowersActivity(postID uint, userIDs []uint) ([]FollowerEntry, error) {
+	var res []FollowerEntry
+	if len(userIDs) == 0 {
+		return res, nil
+	}
+	err := r.db.Raw(followersActivitySQL, map[string]interface{}{
+		"post":  postID,
+		"users": userIDs,
+	}).Scan(&res).Error
+	if err != nil {
+		return nil, fmt.Errorf("followers activity: %w", err)
+	}
+	return res, nil
+}
+
+// followingEntries runs followingEntriesSQL with filter narrowing both of
+// its branches; filter may refer to followers and posts.
+func (r *feedRepository) followingEntries(
+	userID uint,
+	filter clause.Expr,
+	limit int,
+	cursor *Cursor,
+) ([]FeedEntry, error) {
 
 	q := r.db.
-		// ✅ PostgreSQL DISTINCT ON
-		Select("DISTINCT ON (posts.id) posts.*").
-		Joins("JOIN followers ON followers.user_id = posts.user_id").
-		Where("followers.follower_id = ?", userID).
-		Preload("User").
-		Preload("Files").
-		Preload("Likes").
-		Preload("Comments").
-		Order("posts.id, posts.created_at DESC")
+		Table("(?) AS entries", gorm.Expr(followingEntriesSQL,
+			userID, postVisibleTo(userID), filter,
+			userID, postVisibleTo(userID), filter,
+		)).
+		Select("post_id, author_id, reposted_by, activity_at").
+		Order("activity_at DESC, post_id DESC")
 
+	if cursor != nil {
+		q = q.Where("(activity_at, post_id) < (?, ?)", cursor.Time, cursor.ID)
+	}
 	if limit > 0 {
 		q = q.Limit(limit)
 	}
 
-	if cursor != nil {
-		q = q.Where("posts.created_at < ?", *cursor)
+	var entries []FeedEntry
+	if err := q.Scan(&entries).Error; err != nil {
+		return nil, fmt.Errorf("following entries: %w", err)
+	}
+	return entries, nil
+}
+
+// Hydrate turns entries into feed items in the same order, dropping the
+// posts the viewer may not see (anymore) and those q filters out.
+func (r *feedRepository) Hydrate(q FeedQuery, entries []FeedEntry) ([]FeedItem, error) {
+	if len(entries) == 0 {
+		return []FeedItem{}, nil
 	}
 
-	if err := q.Find(&posts).Error; err != nil {
+	postIDs := make([]uint, 0, len(entries))
+	var reposterIDs []uint
+	for _, e := range entries {
+		postIDs = append(postIDs, e.PostID)
+		if e.RepostedBy != nil {
+			reposterIDs = append(reposterIDs, *e.RepostedBy)
+		}
+	}
+
+	posts, err := r.GetPosts(q, postIDs)
+	if err != nil {
 		return nil, err
 	}
+	byID := make(map[uint]model.Post, len(posts))
+	for _, p := range posts {
+		byID[p.ID] = p
+	}
 
+	reposters := make(map[uint]model.User)
+	if len(reposterIDs) > 0 {
+		var users []model.User
+		if err := r.db.Where("id IN ?", reposterIDs).Find(&users).Error; err != nil {
+			return nil, fmt.Errorf("get reposters: %w", err)
+		}
+		for _, u := range users {
+			reposters[u.ID] = u
+		}
+	}
+
+	items := make([]FeedItem, 0, len(entries))
+	for _, e := range entries {
+		p, ok := byID[e.PostID]
+		if !ok {
+			continue
+		}
+		item := FeedItem{Post: p, ActivityAt: e.ActivityAt}
+		if e.RepostedBy != nil {
+			if u, ok := reposters[*e.RepostedBy]; ok {
+				item.RepostedBy = &u
+			}
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// feedPosts is the query every feed mode builds on: published posts the
+// viewer may see, narrowed by q, with everything a feed item shows.
+func (r *feedRepository) feedPosts(q FeedQuery) *gorm.DB {
+	db := r.db.Model(&model.Post{}).
+		Where("posts.status = ?", model.PostStatusPublished).
+		Where(postVisibleTo(q.ViewerID)).
+		Preload("User").
+		Preload("Files")
+
+	if q.Scope == FeedScopeCampus {
+		db = db.Where(`posts.user_id IN (
+			SELECT authors.id FROM users authors
+			JOIN users viewer ON viewer.id = ?
+			WHERE authors.id <> viewer.id
+			  AND (authors.major = viewer.major OR authors.grade = viewer.grade)
+		)`, q.ViewerID)
+	}
+
+	switch q.Media {
+	case FeedMediaOnly:
+		db = db.Where("EXISTS (SELECT 1 FROM files WHERE files.post_id = posts.id)")
+	case FeedTextOnly:
+		db = db.Where("NOT EXISTS (SELECT 1 FROM files WHERE files.post_id = posts.id)")
+	}
+	return db
+}
+
+// GetPosts loads the given posts that pass q, in no particular order.
+func (r *feedRepository) GetPosts(q FeedQuery, ids []uint) ([]model.Post, error) {
+	var posts []model.Post
+	if len(ids) == 0 {
+		return posts, nil
+	}
+	if err := r.feedPosts(q).Where("posts.id IN ?", ids).Find(&posts).Error; err != nil {
+		return nil, fmt.Errorf("get feed posts: %w", err)
+	}
 	return posts, nil
 }
 
-func (r *feedRepository) GetRecommendedPosts(userID uint, limit int, excludeIDs []uint) ([]model.Post, error) {
+// LatestPosts pages the posts that pass q, newest first.
+func (r *feedRepository) LatestPosts(q FeedQuery, limit int, cursor *Cursor) ([]model.Post, error) {
+	db := r.feedPosts(q).
+		Order("posts.created_at DESC, posts.id DESC").
+		Limit(limit)
+	if cursor != nil {
+		db = db.Where("(posts.created_at, posts.id) < (?, ?)", cursor.Time, cursor.ID)
+	}
+
 	var posts []model.Post
+	if err := db.Find(&posts).Error; err != nil {
+		return nil, fmt.Errorf("get latest posts: %w", err)
+	}
+	return posts, nil
+}
+
+// FollowedCelebrities returns the followees of userID flagged as
+// celebrities.
+func (r *feedRepository) FollowedCelebrities(userID uint) ([]uint, error) {
 	var ids []uint
+	if err := r.db.Table("followers").
+		Joins("JOIN users ON users.id = followers.user_id").
+		Where("followers.follower_id = ? AND users.is_celebrity", userID).
+		Pluck("followers.user_id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("followed celebrities: %w", err)
+	}
+	return ids, nil
+}
 
-	// ✅ выбираем id рекомендованных постов, исключая уже попавшие в following
-	q := r.db.
-		Table("posts").
-		Select("id").
-		Where("user_id != ?", userID)
+func (r *feedRepository) IsCelebrity(userID uint) (bool, error) {
+	var user model.User
+	if err := r.db.Select("is_celebrity").First(&user, userID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return false, ErrNotFound
+		}
+		return false, fmt.Errorf("is celebrity: %w", err)
+	}
+	return user.IsCelebrity, nil
+}
 
-	if len(excludeIDs) > 0 {
-		q = q.Where("id NOT IN ?", excludeIDs)
+// RefreshCelebrities flags the users with at least minFollowers followers
+// and unflags the rest, touching only the rows that change.
+func (r *feedRepository) RefreshCelebrities(minFollowers int) error {
+	if err := r.db.Exec(`
+		UPDATE users SET is_celebrity = NOT users.is_celebrity
+		WHERE users.is_celebrity <> (users.id IN (
+			SELECT user_id FROM followers GROUP BY user_id HAVING COUNT(*) >= ?
+		))`, minFollowers).Error; err != nil {
+		return fmt.Errorf("refresh celebrities: %w", err)
 	}
+	return nil
+}
 
-	q = q.Order("RANDOM()")
-	if limit > 0 {
-		q = q.Limit(limit)
+// recommendable limits candidates to recent posts the viewer may see that
+// are neither their own nor by someone they follow, since those come from
+// the following feed anyway.
+func (r *feedRepository) recommendable(userID uint, since time.Time, excludeIDs []uint) *gorm.DB {
+	q := r.db.Table("posts").
+		Where("posts.user_id <> ? AND posts.created_at >= ?", userID, since).
+		Where(postVisibleTo(userID)).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM followers
+			WHERE followers.user_id = posts.user_id AND followers.follower_id = ?
+		)`, userID)
+	if len(excludeIDs) > 0 {
+		q = q.Where("posts.id NOT IN ?", excludeIDs)
 	}
-	if err := q.Scan(&ids).Error; err != nil {
-		return nil, err
+	return q
+}
+
+// EngagedByFollowees proposes posts that the viewer's followees liked or
+// commented on, the ones more followees engaged with first.
+func (r *feedRepository) EngagedByFollowees(userID uint, since time.Time, limit int, excludeIDs []uint) ([]FeedCandidate, error) {
+	var res []FeedCandidate
+	err := r.recommendable(userID, since, excludeIDs).
+		Select("posts.id AS post_id, ? AS reason, MIN(users.nickname) AS detail", FeedReasonEngagedByFollowee).
+		Joins(`JOIN (
+			SELECT post_id, user_id FROM post_likes WHERE created_at >= ?
+			UNION ALL
+			SELECT post_id, user_id FROM comments WHERE created_at >= ?
+		) engaged ON engaged.post_id = posts.id`, since, since).
+		Joins("JOIN followers f ON f.user_id = engaged.user_id AND f.follower_id = ?", userID).
+		Joins("JOIN users ON users.id = engaged.user_id").
+		Group("posts.id").
+		Order("COUNT(DISTINCT engaged.user_id) DESC, posts.id DESC").
+		Limit(limit).
+		Scan(&res).Error
+	if err != nil {
+		return nil, fmt.Errorf("engaged by followees: %w", err)
 	}
+	return res, nil
+}
 
-	if len(ids) == 0 {
-		return []model.Post{}, nil
+// SameCohort proposes posts by users who share the viewer's major or grade.
+func (r *feedRepository) SameCohort(userID uint, since time.Time, limit int, excludeIDs []uint) ([]FeedCandidate, error) {
+	var res []FeedCandidate
+	err := r.recommendable(userID, since, excludeIDs).
+		Select(`posts.id AS post_id,
+			CASE WHEN authors.major = viewer.major THEN ? ELSE ? END AS reason,
+			CASE WHEN authors.major = viewer.major THEN authors.major ELSE authors.grade END AS detail`,
+			FeedReasonSameMajor, FeedReasonSameGrade).
+		Joins("JOIN users authors ON authors.id = posts.user_id").
+		Joins("JOIN users viewer ON viewer.id = ?", userID).
+		Where("authors.major = viewer.major OR authors.grade = viewer.grade").
+		Order("posts.created_at DESC, posts.id DESC").
+		Limit(limit).
+		Scan(&res).Error
+	if err != nil {
+		return nil, fmt.Errorf("same cohort: %w", err)
 	}
+	return res, nil
+}
 
-	// ✅ подгружаем сами посты с preload
-	if err := r.db.
-		Where("id IN ?", ids).
-		Preload("User").
-		Preload("Files").
-		Preload("Likes").
-		Preload("Comments").
-		Find(&posts).Error; err != nil {
-		return nil, err
+// Trending proposes the posts with the most likes and comments since since.
+func (r *feedRepository) Trending(userID uint, since time.Time, limit int, excludeIDs []uint) ([]FeedCandidate, error) {
+	var res []FeedCandidate
+	err := r.recommendable(userID, since, excludeIDs).
+		Select(`posts.id AS post_id, ? AS reason, '' AS detail,
+			(SELECT COUNT(*) FROM post_likes WHERE post_likes.post_id = posts.id AND post_likes.created_at >= ?)
+			+ (SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id AND comments.created_at >= ?) AS heat`,
+			FeedReasonTrending, since, since).
+		Order("heat DESC, posts.id DESC").
+		Limit(limit).
+		Scan(&res).Error
+	if err != nil {
+		return nil, fmt.Errorf("trending: %w", err)
 	}
+	return res, nil
+}
 
-	return posts, nil
+const postSignalsSQL = `
+	SELECT posts.id AS post_id, posts.user_id, posts.created_at,
+	       (SELECT COUNT(*) FROM post_likes WHERE post_likes.post_id = posts.id)
+	       + (SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id)
+	       + (SELECT COUNT(*) FROM reposts WHERE reposts.post_id = posts.id) AS engagement,
+	       (SELECT COUNT(*) FROM post_likes WHERE post_likes.post_id = posts.id AND post_likes.created_at >= @recent)
+	       + (SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id AND comments.created_at >= @recent)
+	       + (SELECT COUNT(*) FROM reposts WHERE reposts.post_id = posts.id AND reposts.created_at >= @recent) AS recent_engagement,
+	       (SELECT COUNT(*) FROM post_likes pl JOIN posts ap ON ap.id = pl.post_id
+	        WHERE ap.user_id = posts.user_id AND pl.user_id = @viewer AND pl.created_at >= @affinity)
+	       + (SELECT COUNT(*) FROM comments c JOIN posts ap ON ap.id = c.post_id
+	        WHERE ap.user_id = posts.user_id AND c.user_id = @viewer AND c.created_at >= @affinity) AS affinity
+	FROM posts
+	WHERE posts.id IN @ids`
+
+// Signals loads the scoring inputs of the given posts for the viewer.
+func (r *feedRepository) Signals(postIDs []uint, viewerID uint, recentSince, affinitySince time.Time) ([]PostSignals, error) {
+	var res []PostSignals
+	if len(postIDs) == 0 {
+		return res, nil
+	}
+	err := r.db.Raw(postSignalsSQL, map[string]interface{}{
+		"ids":      postIDs,
+		"viewer":   viewerID,
+		"recent":   recentSince,
+		"affinity": affinitySince,
+	}).Scan(&res).Error
+	if err != nil {
+		return nil, fmt.Errorf("post signals: %w", err)
+	}
+	return res, nil
 }
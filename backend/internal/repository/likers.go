@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Liker is one row of a "who liked this" list.
+type Liker struct {
+	LikeID    uint
+	UserID    uint
+	Nickname  string
+	AvatarURL *string
+	Reaction  string
+	CreatedAt time.Time
+	// Followed: the viewer follows this user. Followed likers come first.
+	Followed bool
+}
+
+// LikerCursor is a keyset position in the followees-first order of likers.
+type LikerCursor struct {
+	Followed bool
+	Cursor
+}
+
+// listLikers pages through likes of a post or a comment: the viewer's
+// followees first, each group newest first. Users on either side of a block
+// with the viewer are left out.
+func listLikers(db *gorm.DB, table, column string, targetID, viewerID uint, limit int, cursor *LikerCursor) ([]Liker, error) {
+	q := fmt.Sprintf(`
+		SELECT * FROM (
+			SELECT l.id AS like_id, l.user_id, users.nickname, users.avatar_url,
+			       l.reaction, l.created_at,
+			       EXISTS (
+			           SELECT 1 FROM followers
+			           WHERE followers.user_id = l.user_id AND followers.follower_id = ?
+			       ) AS followed
+			FROM %s l
+			JOIN users ON users.id = l.user_id
+			WHERE l.%s = ?
+			  AND NOT EXISTS (
+			      SELECT 1 FROM blocks
+			      WHERE (blocks.user_id = l.user_id AND blocks.blocked_id = ?)
+			         OR (blocks.user_id = ? AND blocks.blocked_id = l.user_id)
+			  )
+		) likers`, table, column)
+	args := []interface{}{viewerID, targetID, viewerID, viewerID}
+
+	if cursor != nil {
+		if cursor.Followed {
+			q += ` WHERE NOT followed OR (created_at, like_id) < (?, ?)`
+		} else {
+			q += ` WHERE NOT followed AND (created_at, like_id) < (?, ?)`
+		}
+		args = append(args, cursor.Time, cursor.ID)
+	}
+	q += ` ORDER BY followed DESC, created_at DESC, like_id DESC LIMIT ?`
+	args = append(args, limit)
+
+	var likers []Liker
+	if err := db.Raw(q, args...).Scan(&likers).Error; err != nil {
+		return nil, fmt.Errorf("list likers: %w", err)
+	}
+	return likers, nil
+}
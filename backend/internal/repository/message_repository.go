@@ -0,0 +1,309 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"backend/internal/model"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrDirectExists is returned by CreateConversation when a concurrent
+// request has already created the direct conversation with the same key.
+var ErrDirectExists = fmt.Errorf("direct conversation exists")
+
+type MessageRepository interface {
+	FindDirect(directKey string) (*model.Conversation, error)
+	CreateConversation(conv *model.Conversation, memberIDs []uint) error
+	GetConversation(id uint) (*model.Conversation, error)
+	IsMember(conversationID, userID uint) (bool, error)
+	MemberIDs(conversationID uint) ([]uint, error)
+	ListConversations(userID uint, limit int, cursor *Cursor) ([]model.Conversation, error)
+	UnreadCounts(userID uint, conversationIDs []uint) (map[uint]int64, error)
+	LastMessages(userID uint, conversationIDs []uint) (map[uint]model.Message, error)
+
+	CreateMessage(msg *model.Message) error
+	GetMessage(id uint) (*model.Message, error)
+	ListMessages(conversationID, userID uint, limit int, beforeID uint) ([]model.Message, error)
+	LatestMessageID(conversationID uint) (uint, error)
+	MarkRead(conversationID, userID, messageID uint) error
+	DeleteForMe(messageID, userID uint) error
+	DeleteForEveryone(messageID uint) error
+}
+
+type messageRepository struct {
+	db *gorm.DB
+}
+
+func NewMessageRepository(db *gorm.DB) MessageRepository {
+	return &messageRepository{db: db}
+}
+
+func (r *messageRepository) FindDirect(directKey string) (*model.Conversation, error) {
+	var conv model.Conversation
+	if err := r.db.
+		Where("direct_key = ?", directKey).
+		Preload("Members.User").
+		First(&conv).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("find direct conversation: %w", err)
+	}
+	return &conv, nil
+}
+
+func (r *messageRepository) CreateConversation(conv *model.Conversation, memberIDs []uint) error {
+	if conv == nil {
+		return fmt.Errorf("conversation is nil")
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(conv).Error; err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "uniq_conversations_direct_key" {
+				return ErrDirectExists
+			}
+			return fmt.Errorf("create conversation: %w", err)
+		}
+		members := make([]model.ConversationMember, 0, len(memberIDs))
+		for _, id := range memberIDs {
+			members = append(members, model.ConversationMember{
+				ConversationID: conv.ID,
+				UserID:         id,
+			})
+		}
+		if err := tx.Create(&members).Error; err != nil {
+			return fmt.Errorf("add conversation members: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *messageRepository) GetConversation(id uint) (*model.Conversation, error) {
+	var conv model.Conversation
+	if err := r.db.
+		Preload("Members.User").
+		First(&conv, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get conversation: %w", err)
+	}
+	return &conv, nil
+}
+
+func (r *messageRepository) IsMember(conversationID, userID uint) (bool, error) {
+	var cnt int64
+	if err := r.db.Model(&model.ConversationMember{}).
+		Where("conversation_id = ? AND user_id = ?", conversationID, userID).
+		Count(&cnt).Error; err != nil {
+		return false, fmt.Errorf("check membership: %w", err)
+	}
+	return cnt > 0, nil
+}
+
+func (r *messageRepository) MemberIDs(conversationID uint) ([]uint, error) {
+	var ids []uint
+	if err := r.db.Model(&model.ConversationMember{}).
+		Where("conversation_id = ?", conversationID).
+		Pluck("user_id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("member ids: %w", err)
+	}
+	return ids, nil
+}
+
+func (r *messageRepository) ListConversations(userID uint, limit int, cursor *Cursor) ([]model.Conversation, error) {
+	var convs []model.Conversation
+
+	q := r.db.
+		Joins("JOIN conversation_members cm ON cm.conversation_id = conversations.id").
+		Where("cm.user_id = ?", userID).
+		Preload("Members.User").
+		Order("conversations.last_message_at DESC, conversations.id DESC")
+
+	if cursor != nil {
+		q = q.Where("(conversations.last_message_at, conversations.id) < (?, ?)", cursor.Time, cursor.ID)
+	}
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+
+	if err := q.Find(&convs).Error; err != nil {
+		return nil, fmt.Errorf("list conversations: %w", err)
+	}
+	return convs, nil
+}
+
+// visibleTo filters out messages the user deleted for themselves.
+func visibleTo(userID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("NOT EXISTS (SELECT 1 FROM message_deletions md WHERE md.message_id = messages.id AND md.user_id = ?)", userID)
+	}
+}
+
+func (r *messageRepository) UnreadCounts(userID uint, conversationIDs []uint) (map[uint]int64, error) {
+	res := make(map[uint]int64)
+	if len(conversationIDs) == 0 {
+		return res, nil
+	}
+
+	type row struct {
+		ConversationID uint
+		Count          int64
+	}
+
+	var rows []row
+	if err := r.db.Model(&model.Message{}).
+		Select("messages.conversation_id, count(*) as count").
+		Joins("JOIN conversation_members cm ON cm.conversation_id = messages.conversation_id AND cm.user_id = ?", userID).
+		Where("messages.conversation_id IN ?", conversationIDs).
+		Where("messages.id > cm.last_read_message_id").
+		Where("messages.sender_id <> ? AND messages.deleted_for_everyone = FALSE", userID).
+		Scopes(visibleTo(userID)).
+		Group("messages.conversation_id").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("unread counts: %w", err)
+	}
+
+	for _, row := range rows {
+		res[row.ConversationID] = row.Count
+	}
+	return res, nil
+}
+
+func (r *messageRepository) LastMessages(userID uint, conversationIDs []uint) (map[uint]model.Message, error) {
+	res := make(map[uint]model.Message)
+	if len(conversationIDs) == 0 {
+		return res, nil
+	}
+
+	var msgs []model.Message
+	if err := r.db.
+		Select("DISTINCT ON (messages.conversation_id) messages.*").
+		Where("messages.conversation_id IN ?", conversationIDs).
+		Scopes(visibleTo(userID)).
+		Preload("Sender").
+		Preload("Files").
+		Order("messages.conversation_id, messages.id DESC").
+		Find(&msgs).Error; err != nil {
+		return nil, fmt.Errorf("last messages: %w", err)
+	}
+
+	for _, m := range msgs {
+		res[m.ConversationID] = m
+	}
+	return res, nil
+}
+
+// CreateMessage stores the message with its files and bumps the
+// conversation's activity time.
+func (r *messageRepository) CreateMessage(msg *model.Message) error {
+	if msg == nil {
+		return fmt.Errorf("message is nil")
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(msg).Error; err != nil {
+			return fmt.Errorf("create message: %w", err)
+		}
+		if err := tx.Model(&model.Conversation{}).
+			Where("id = ?", msg.ConversationID).
+			UpdateColumn("last_message_at", msg.CreatedAt).Error; err != nil {
+			return fmt.Errorf("touch conversation: %w", err)
+		}
+		// собственные сообщения считаем прочитанными
+		if err := tx.Model(&model.ConversationMember{}).
+			Where("conversation_id = ? AND user_id = ?", msg.ConversationID, msg.SenderID).
+			UpdateColumn("last_read_message_id", msg.ID).Error; err != nil {
+			return fmt.Errorf("mark own message read: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *messageRepository) GetMessage(id uint) (*model.Message, error) {
+	var msg model.Message
+	if err := r.db.
+		Preload("Sender").
+		Preload("Files").
+		First(&msg, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get message: %w", err)
+	}
+	return &msg, nil
+}
+
+// ListMessages returns messages newest first, strictly older than beforeID
+// when it is set.
+func (r *messageRepository) ListMessages(conversationID, userID uint, limit int, beforeID uint) ([]model.Message, error) {
+	var msgs []model.Message
+
+	q := r.db.
+		Where("messages.conversation_id = ?", conversationID).
+		Scopes(visibleTo(userID)).
+		Preload("Sender").
+		Preload("Files").
+		Order("messages.id DESC")
+
+	if beforeID > 0 {
+		q = q.Where("messages.id < ?", beforeID)
+	}
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+
+	if err := q.Find(&msgs).Error; err != nil {
+		return nil, fmt.Errorf("list messages: %w", err)
+	}
+	return msgs, nil
+}
+
+func (r *messageRepository) LatestMessageID(conversationID uint) (uint, error) {
+	var id uint
+	if err := r.db.Model(&model.Message{}).
+		Select("COALESCE(MAX(id), 0)").
+		Where("conversation_id = ?", conversationID).
+		Scan(&id).Error; err != nil {
+		return 0, fmt.Errorf("latest message id: %w", err)
+	}
+	return id, nil
+}
+
+// MarkRead moves the read pointer forward; it never goes back.
+func (r *messageRepository) MarkRead(conversationID, userID, messageID uint) error {
+	if err := r.db.Model(&model.ConversationMember{}).
+		Where("conversation_id = ? AND user_id = ?", conversationID, userID).
+		UpdateColumn("last_read_message_id", gorm.Expr("GREATEST(last_read_message_id, ?)", messageID)).Error; err != nil {
+		return fmt.Errorf("mark read: %w", err)
+	}
+	return nil
+}
+
+func (r *messageRepository) DeleteForMe(messageID, userID uint) error {
+	d := model.MessageDeletion{MessageID: messageID, UserID: userID}
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&d).Error; err != nil {
+		return fmt.Errorf("delete message for me: %w", err)
+	}
+	return nil
+}
+
+func (r *messageRepository) DeleteForEveryone(messageID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Message{}).
+			Where("id = ?", messageID).
+			Updates(map[string]interface{}{
+				"text":                 "",
+				"deleted_for_everyone": true,
+			}).Error; err != nil {
+			return fmt.Errorf("delete message for everyone: %w", err)
+		}
+		if err := tx.Where("message_id = ?", messageID).Delete(&model.MessageFile{}).Error; err != nil {
+			return fmt.Errorf("delete message files: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"fmt"
+
+	"backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type NotificationRepository interface {
+	Push(userID, actorID uint, typ string, postID, commentID *uint) (*model.Notification, error)
+	List(userID uint, limit int, cursor *Cursor) ([]model.Notification, error)
+	UnreadCount(userID uint) (int64, error)
+	MarkRead(userID, id uint) error
+	MarkAllRead(userID uint) error
+}
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+// Push adds actorID to the unread group (userID, typ, postID, commentID),
+// creating the group if there is none. Repeated actions of the same actor
+// on the same group are counted once; in that case nil is returned.
+func (r *notificationRepository) Push(userID, actorID uint, typ string, postID, commentID *uint) (*model.Notification, error) {
+	if userID == 0 || actorID == 0 {
+		return nil, fmt.Errorf("invalid ids")
+	}
+
+	var changed *model.Notification
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var id uint
+		if err := tx.Raw(`
+			INSERT INTO notifications (user_id, actor_id, type, post_id, comment_id, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, NOW(), NOW())
+			ON CONFLICT (user_id, type, COALESCE(post_id, 0), COALESCE(comment_id, 0)) WHERE is_read = FALSE
+			DO UPDATE SET user_id = EXCLUDED.user_id
+			RETURNING id`,
+			userID, actorID, typ, postID, commentID,
+		).Scan(&id).Error; err != nil {
+			return fmt.Errorf("upsert notification: %w", err)
+		}
+
+		res := tx.Exec(`
+			INSERT INTO notification_actors (notification_id, actor_id, created_at)
+			VALUES (?, ?, NOW())
+			ON CONFLICT DO NOTHING`,
+			id, actorID,
+		)
+		if res.Error != nil {
+			return fmt.Errorf("add notification actor: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Model(&model.Notification{}).
+			Where("id = ?", id).
+			Updates(map[string]interface{}{
+				"actor_id":     actorID,
+				"actors_count": gorm.Expr("actors_count + 1"),
+				"updated_at":   gorm.Expr("NOW()"),
+			}).Error; err != nil {
+			return fmt.Errorf("bump notification: %w", err)
+		}
+
+		var n model.Notification
+		if err := tx.Preload("Actor").First(&n, id).Error; err != nil {
+			return fmt.Errorf("reload notification: %w", err)
+		}
+		changed = &n
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changed, nil
+}
+
+func (r *notificationRepository) List(userID uint, limit int, cursor *Cursor) ([]model.Notification, error) {
+	var items []model.Notification
+
+	q := r.db.
+		Where("user_id = ? AND actors_count > 0", userID).
+		Preload("Actor").
+		Order("updated_at DESC, id DESC")
+
+	if cursor != nil {
+		q = q.Where("(updated_at, id) < (?, ?)", cursor.Time, cursor.ID)
+	}
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+
+	if err := q.Find(&items).Error; err != nil {
+		return nil, fmt.Errorf("list notifications: %w", err)
+	}
+	return items, nil
+}
+
+func (r *notificationRepository) UnreadCount(userID uint) (int64, error) {
+	var cnt int64
+	if err := r.db.Model(&model.Notification{}).
+		Where("user_id = ? AND is_read = FALSE AND actors_count > 0", userID).
+		Count(&cnt).Error; err != nil {
+		return 0, fmt.Errorf("count unread notifications: %w", err)
+	}
+	return cnt, nil
+}
+
+func (r *notificationRepository) MarkRead(userID, id uint) error {
+	res := r.db.Model(&model.Notification{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		UpdateColumn("is_read", true)
+	if res.Error != nil {
+		return fmt.Errorf("mark notification read: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *notificationRepository) MarkAllRead(userID uint) error {
+	if err := r.db.Model(&model.Notification{}).
+		Where("user_id = ? AND is_read = FALSE", userID).
+		UpdateColumn("is_read", true).Error; err != nil {
+		return fmt.Errorf("mark all notifications read: %w", err)
+	}
+	return nil
+}
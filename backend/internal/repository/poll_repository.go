@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"fmt"
+
+	"backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type PollRepository interface {
+	GetByPostID(postID uint) (*model.Poll, error)
+	ForPosts(postIDs []uint) ([]model.Poll, error)
+
+	OptionCounts(pollIDs []uint) (map[uint]int, error)
+	VoterCounts(pollIDs []uint) (map[uint]int, error)
+	Choices(pollIDs []uint, userID uint) (map[uint][]uint, error)
+
+	Vote(pollID, userID uint, optionIDs []uint) error
+	Retract(pollID, userID uint) error
+	Voters(optionID uint, limit int, cursor *Cursor) ([]model.PollVote, error)
+}
+
+type pollRepository struct {
+	db *gorm.DB
+}
+
+func NewPollRepository(db *gorm.DB) PollRepository {
+	return &pollRepository{db: db}
+}
+
+func (r *pollRepository) GetByPostID(postID uint) (*model.Poll, error) {
+	var poll model.Poll
+	if err := r.db.
+		Where("post_id = ?", postID).
+		Preload("Options", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		First(&poll).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get poll: %w", err)
+	}
+	return &poll, nil
+}
+
+func (r *pollRepository) ForPosts(postIDs []uint) ([]model.Poll, error) {
+	var polls []model.Poll
+	if len(postIDs) == 0 {
+		return polls, nil
+	}
+	if err := r.db.
+		Where("post_id IN ?", postIDs).
+		Preload("Options", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		Find(&polls).Error; err != nil {
+		return nil, fmt.Errorf("polls for posts: %w", err)
+	}
+	return polls, nil
+}
+
+// OptionCounts returns votes per option id.
+func (r *pollRepository) OptionCounts(pollIDs []uint) (map[uint]int, error) {
+	res := make(map[uint]int)
+	if len(pollIDs) == 0 {
+		return res, nil
+	}
+
+	type row struct {
+		OptionID uint
+		Count    int64
+	}
+
+	var rows []row
+	if err := r.db.Table("poll_vote_options").
+		Select("poll_vote_options.option_id, count(*) as count").
+		Joins("JOIN poll_votes ON poll_votes.id = poll_vote_options.vote_id").
+		Where("poll_votes.poll_id IN ?", pollIDs).
+		Group("poll_vote_options.option_id").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("poll option counts: %w", err)
+	}
+
+	for _, row := range rows {
+		res[row.OptionID] = int(row.Count)
+	}
+	return res, nil
+}
+
+// VoterCounts returns the number of ballots per poll id.
+func (r *pollRepository) VoterCounts(pollIDs []uint) (map[uint]int, error) {
+	res := make(map[uint]int)
+	if len(pollIDs) == 0 {
+		return res, nil
+	}
+
+	type row struct {
+		PollID uint
+		Count  int64
+	}
+
+	var rows []row
+	if err := r.db.Model(&model.PollVote{}).
+		Select("poll_id, count(*) as count").
+		Where("poll_id IN ?", pollIDs).
+		Group("poll_id").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("poll voter counts: %w", err)
+	}
+
+	for _, row := range rows {
+		res[row.PollID] = int(row.Count)
+	}
+	return res, nil
+}
+
+// Choices returns the options userID picked, by poll id.
+func (r *pollRepository) Choices(pollIDs []uint, userID uint) (map[uint][]uint, error) {
+	res := make(map[uint][]uint)
+	if len(pollIDs) == 0 || userID == 0 {
+		return res, nil
+	}
+
+	type row struct {
+		PollID   uint
+		OptionID uint
+	}
+
+	var rows []row
+	if err := r.db.Table("poll_vote_options").
+		Select("poll_votes.poll_id, poll_vote_options.option_id").
+		Joins("JOIN poll_votes ON poll_votes.id = poll_vote_options.vote_id").
+		Where("poll_votes.poll_id IN ? AND poll_votes.user_id = ?", pollIDs, userID).
+		Order("poll_vote_options.option_id").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("poll choices: %w", err)
+	}
+
+	for _, row := range rows {
+		res[row.PollID] = append(res[row.PollID], row.OptionID)
+	}
+	return res, nil
+}
+
+// Vote replaces the user's ballot. The unique (poll_id, user_id) index keeps
+// it to one ballot per user even under concurrent requests.
+func (r *pollRepository) Vote(pollID, userID uint, optionIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var voteID uint
+		if err := tx.Raw(`
+			INSERT INTO poll_votes (poll_id, user_id, created_at, updated_at)
+			VALUES (?, ?, NOW(), NOW())
+			ON CONFLICT (poll_id, user_id) DO UPDATE SET updated_at = NOW()
+			RETURNING id`, pollID, userID).Scan(&voteID).Error; err != nil {
+			return fmt.Errorf("upsert poll vote: %w", err)
+		}
+
+		if err := tx.Where("vote_id = ?", voteID).Delete(&model.PollVoteOption{}).Error; err != nil {
+			return fmt.Errorf("clear poll vote options: %w", err)
+		}
+
+		opts := make([]model.PollVoteOption, 0, len(optionIDs))
+		for _, id := range optionIDs {
+			opts = append(opts, model.PollVoteOption{VoteID: voteID, OptionID: id})
+		}
+		if err := tx.Create(&opts).Error; err != nil {
+			return fmt.Errorf("save poll vote options: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *pollRepository) Retract(pollID, userID uint) error {
+	if err := r.db.Where("poll_id = ? AND user_id = ?", pollID, userID).
+		Delete(&model.PollVote{}).Error; err != nil {
+		return fmt.Errorf("retract poll vote: %w", err)
+	}
+	return nil
+}
+
+// Voters lists ballots that picked optionID, latest first, with users.
+func (r *pollRepository) Voters(optionID uint, limit int, cursor *Cursor) ([]model.PollVote, error) {
+	var votes []model.PollVote
+
+	q := r.db.
+		Joins("JOIN poll_vote_options ON poll_vote_options.vote_id = poll_votes.id").
+		Where("poll_vote_options.option_id = ?", optionID).
+		Preload("User").
+		Order("poll_votes.updated_at DESC, poll_votes.id DESC")
+
+	if cursor != nil {
+		q = q.Where("(poll_votes.updated_at, poll_votes.id) < (?, ?)", cursor.Time, cursor.ID)
+	}
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+
+	if err := q.Find(&votes).Error; err != nil {
+		return nil, fmt.Errorf("poll voters: %w", err)
+	}
+	return votes, nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"fmt"
+	"time"
 
 	"backend/internal/model"
 
@@ -10,14 +11,37 @@ import (
 )
 
 type PostRepository interface {
-	CreatePost(post *model.Post) error
+	CreatePost(post *model.Post, poll *model.Poll) error
 	UpdateFields(id uint, fields map[string]interface{}) error
+	EditDescription(post *model.Post, description *string) error
+	ListRevisions(postID uint) ([]model.PostRevision, error)
 	DeletePost(id uint, userID uint) error
 	AddFiles(files []model.File) error
-	LikePost(postID, userID uint) error
+	LikePost(postID, userID uint) (bool, error)
 	UnlikePost(postID, userID uint) error
-	FindByID(id uint) (*model.Post, error)
-	GetByUser(userID uint) ([]model.Post, error)
+	LikesCount(postID uint) (int, error)
+	React(postID, userID uint, reaction string) (bool, error)
+	ReactionCounts(postID uint) (map[string]int, error)
+	Likers(postID, viewerID uint, limit int, cursor *LikerCursor) ([]Liker, error)
+	FindByID(id, viewerID uint) (*model.Post, error)
+	FindByIDs(ids []uint, viewerID uint) ([]model.Post, error)
+	VisibleIDs(ids []uint, viewerID uint) ([]uint, error)
+	GetByUser(userID, viewerID uint) ([]model.Post, error)
+	GetDrafts(userID uint) ([]model.Post, error)
+
+	SetStatus(postID uint, status string, publishAt *time.Time) error
+	Publish(postID uint) (bool, error)
+	PublishDue(limit int) ([]model.Post, error)
+
+	Repost(postID, userID uint) (bool, error)
+	Unrepost(postID, userID uint) error
+	RepostCounts(postIDs []uint) (map[uint]int, error)
+	RepostedByUser(postIDs []uint, userID uint) (map[uint]bool, error)
+	ReactionCountsByPost(postIDs []uint) (map[uint]map[string]int, error)
+	ReactionsByUser(postIDs []uint, userID uint) (map[uint]string, error)
+	CommentCounts(postIDs []uint) (map[uint]int, error)
+
+	IncrementViews(counts map[uint]int64) error
 }
 
 type postRepository struct {
@@ -28,14 +52,25 @@ func NewPostRepository(db *gorm.DB) PostRepository {
 	return &postRepository{db: db}
 }
 
-func (r *postRepository) CreatePost(post *model.Post) error {
+// CreatePost creates the post and its poll, when there is one, in one
+// transaction, so a post never shows up without the poll it was sent with.
+func (r *postRepository) CreatePost(post *model.Post, poll *model.Poll) error {
 	if post == nil {
 		return fmt.Errorf("post is nil")
 	}
-	if err := r.db.Create(post).Error; err != nil {
-		return fmt.Errorf("create post: %w", err)
-	}
-	return nil
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(post).Error; err != nil {
+			return fmt.Errorf("create post: %w", err)
+		}
+		if poll == nil {
+			return nil
+		}
+		poll.PostID = post.ID
+		if err := tx.Create(poll).Error; err != nil {
+			return fmt.Errorf("create poll: %w", err)
+		}
+		return nil
+	})
 }
 
 func (r *postRepository) UpdateFields(id uint, fields map[string]interface{}) error {
@@ -48,6 +83,60 @@ func (r *postRepository) UpdateFields(id uint, fields map[string]interface{}) er
 	return nil
 }
 
+// EditDescription saves the new description as a revision and updates the
+// post. The original text is stored as the first revision on the first edit;
+// the row is locked and re-read inside the transaction, so concurrent edits
+// can't both store an original or store a stale one.
+func (r *postRepository) EditDescription(post *model.Post, description *string) error {
+	now := time.Now()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var cur model.Post
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "description", "created_at").
+			First(&cur, post.ID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return ErrNotFound
+			}
+			return fmt.Errorf("lock post: %w", err)
+		}
+
+		var cnt int64
+		if err := tx.Model(&model.PostRevision{}).Where("post_id = ?", cur.ID).Count(&cnt).Error; err != nil {
+			return fmt.Errorf("count revisions: %w", err)
+		}
+		if cnt == 0 {
+			original := model.PostRevision{PostID: cur.ID, Description: cur.Description, CreatedAt: cur.CreatedAt}
+			if err := tx.Create(&original).Error; err != nil {
+				return fmt.Errorf("save original revision: %w", err)
+			}
+		}
+
+		rev := model.PostRevision{PostID: cur.ID, Description: description, CreatedAt: now}
+		if err := tx.Create(&rev).Error; err != nil {
+			return fmt.Errorf("save revision: %w", err)
+		}
+
+		if err := tx.Model(&model.Post{}).Where("id = ?", cur.ID).Updates(map[string]interface{}{
+			"description": description,
+			"edited_at":   now,
+		}).Error; err != nil {
+			return fmt.Errorf("update post: %w", err)
+		}
+		return nil
+	})
+}
+
+// ListRevisions returns the post's revisions, newest first.
+func (r *postRepository) ListRevisions(postID uint) ([]model.PostRevision, error) {
+	var revs []model.PostRevision
+	if err := r.db.Where("post_id = ?", postID).
+		Order("created_at DESC, id DESC").
+		Find(&revs).Error; err != nil {
+		return nil, fmt.Errorf("list revisions: %w", err)
+	}
+	return revs, nil
+}
+
 func (r *postRepository) DeletePost(id uint, userID uint) error {
 	if err := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&model.Post{}).Error; err != nil {
 		return fmt.Errorf("delete post: %w", err)
@@ -65,18 +154,21 @@ func (r *postRepository) AddFiles(files []model.File) error {
 	return nil
 }
 
-func (r *postRepository) LikePost(postID, userID uint) error {
+// LikePost reports whether a new like was created.
+func (r *postRepository) LikePost(postID, userID uint) (bool, error) {
 	if postID == 0 || userID == 0 {
-		return fmt.Errorf("invalid ids")
+		return false, fmt.Errorf("invalid ids")
 	}
 	like := model.PostLike{
-		PostID: postID,
-		UserID: userID,
+		PostID:   postID,
+		UserID:   userID,
+		Reaction: model.ReactionLike,
 	}
-	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&like).Error; err != nil {
-		return fmt.Errorf("like post: %w", err)
+	res := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&like)
+	if res.Error != nil {
+		return false, fmt.Errorf("like post: %w", res.Error)
 	}
-	return nil
+	return res.RowsAffected > 0, nil
 }
 
 func (r *postRepository) UnlikePost(postID, userID uint) error {
@@ -87,12 +179,67 @@ func (r *postRepository) UnlikePost(postID, userID uint) error {
 	return nil
 }
 
-func (r *postRepository) FindByID(id uint) (*model.Post, error) {
+func (r *postRepository) LikesCount(postID uint) (int, error) {
+	var cnt int64
+	if err := r.db.Model(&model.PostLike{}).
+		Where("post_id = ?", postID).
+		Count(&cnt).Error; err != nil {
+		return 0, fmt.Errorf("count post likes: %w", err)
+	}
+	return int(cnt), nil
+}
+
+// React sets the user's reaction on the post, replacing an earlier one, and
+// reports whether the user hadn't reacted before.
+func (r *postRepository) React(postID, userID uint, reaction string) (bool, error) {
+	if postID == 0 || userID == 0 {
+		return false, fmt.Errorf("invalid ids")
+	}
+	var created bool
+	// xmax = 0 только у только что вставленной строки
+	if err := r.db.Raw(`
+		INSERT INTO post_likes (post_id, user_id, reaction)
+		VALUES (?, ?, ?)
+		ON CONFLICT (post_id, user_id) DO UPDATE SET reaction = EXCLUDED.reaction
+		RETURNING (xmax = 0)`,
+		postID, userID, reaction,
+	).Scan(&created).Error; err != nil {
+		return false, fmt.Errorf("react to post: %w", err)
+	}
+	return created, nil
+}
+
+func (r *postRepository) ReactionCounts(postID uint) (map[string]int, error) {
+	var rows []struct {
+		Reaction string
+		Count    int
+	}
+	if err := r.db.Model(&model.PostLike{}).
+		Select("reaction, COUNT(*) AS count").
+		Where("post_id = ?", postID).
+		Group("reaction").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("post reaction counts: %w", err)
+	}
+	res := make(map[string]int, len(rows))
+	for _, row := range rows {
+		res[row.Reaction] = row.Count
+	}
+	return res, nil
+}
+
+func (r *postRepository) Likers(postID, viewerID uint, limit int, cursor *LikerCursor) ([]Liker, error) {
+	return listLikers(r.db, "post_likes", "post_id", postID, viewerID, limit, cursor)
+}
+
+// FindByID returns ErrNotFound both for missing posts and for posts the
+// viewer may not see.
+func (r *postRepository) FindByID(id, viewerID uint) (*model.Post, error) {
 	var post model.Post
 	if err := r.db.
-		Where("id = ?", id).
+		Where("posts.id = ?", id).
+		Where(postVisibleTo(viewerID)).
 		Preload("Files").
-		Preload("Likes").
 		First(&post).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, ErrNotFound
@@ -102,16 +249,271 @@ func (r *postRepository) FindByID(id uint) (*model.Post, error) {
 	return &post, nil
 }
 
-func (r *postRepository) GetByUser(userID uint) ([]model.Post, error) {
+func (r *postRepository) GetByUser(userID, viewerID uint) ([]model.Post, error) {
 	var posts []model.Post
 	if err := r.db.
-		Where("user_id = ?", userID).
+		Where("posts.user_id = ? AND posts.status = ?", userID, model.PostStatusPublished).
+		Where(postVisibleTo(viewerID)).
 		Preload("Files").
-		Preload("Likes").
-		Preload("Comments").
 		Order("created_at DESC").
 		Find(&posts).Error; err != nil {
 		return nil, fmt.Errorf("get posts by user: %w", err)
 	}
 	return posts, nil
 }
+
+// GetDrafts returns the user's drafts and scheduled posts.
+func (r *postRepository) GetDrafts(userID uint) ([]model.Post, error) {
+	var posts []model.Post
+	if err := r.db.
+		Where("user_id = ? AND status IN ?", userID, []string{model.PostStatusDraft, model.PostStatusScheduled}).
+		Preload("Files").
+		Order("created_at DESC").
+		Find(&posts).Error; err != nil {
+		return nil, fmt.Errorf("get drafts: %w", err)
+	}
+	return posts, nil
+}
+
+// SetStatus moves an unpublished post between draft and scheduled.
+func (r *postRepository) SetStatus(postID uint, status string, publishAt *time.Time) error {
+	res := r.db.Model(&model.Post{}).
+		Where("id = ? AND status <> ?", postID, model.PostStatusPublished).
+		Updates(map[string]interface{}{
+			"status":     status,
+			"publish_at": publishAt,
+		})
+	if res.Error != nil {
+		return fmt.Errorf("set post status: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Publish reports whether the post was published by this call, so a post
+// published concurrently by the scheduler doesn't fire events twice.
+// created_at becomes the publication time, which is what feeds order by.
+func (r *postRepository) Publish(postID uint) (bool, error) {
+	res := r.db.Model(&model.Post{}).
+		Where("id = ? AND status <> ?", postID, model.PostStatusPublished).
+		Updates(map[string]interface{}{
+			"status":     model.PostStatusPublished,
+			"publish_at": nil,
+			"created_at": gorm.Expr("NOW()"),
+		})
+	if res.Error != nil {
+		return false, fmt.Errorf("publish post: %w", res.Error)
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// PublishDue publishes scheduled posts whose time has come. SKIP LOCKED lets
+// several replicas run it at once: every post is returned to exactly one.
+func (r *postRepository) PublishDue(limit int) ([]model.Post, error) {
+	var posts []model.Post
+	if err := r.db.Raw(`
+		UPDATE posts SET status = ?, publish_at = NULL, created_at = NOW()
+		WHERE id IN (
+			SELECT id FROM posts
+			WHERE status = ? AND publish_at <= NOW()
+			ORDER BY publish_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, user_id, visibility, created_at`,
+		model.PostStatusPublished, model.PostStatusScheduled, limit,
+	).Scan(&posts).Error; err != nil {
+		return nil, fmt.Errorf("publish due posts: %w", err)
+	}
+	return posts, nil
+}
+
+// FindByIDs loads the posts the viewer may see with author and files, in no
+// particular order.
+func (r *postRepository) FindByIDs(ids []uint, viewerID uint) ([]model.Post, error) {
+	var posts []model.Post
+	if len(ids) == 0 {
+		return posts, nil
+	}
+	if err := r.db.
+		Where("posts.id IN ?", ids).
+		Where(postVisibleTo(viewerID)).
+		Preload("User").
+		Preload("Files").
+		Find(&posts).Error; err != nil {
+		return nil, fmt.Errorf("find posts by ids: %w", err)
+	}
+	return posts, nil
+}
+
+// VisibleIDs filters ids down to the posts the viewer may see.
+func (r *postRepository) VisibleIDs(ids []uint, viewerID uint) ([]uint, error) {
+	var res []uint
+	if len(ids) == 0 {
+		return res, nil
+	}
+	if err := r.db.Model(&model.Post{}).
+		Where("posts.id IN ?", ids).
+		Where(postVisibleTo(viewerID)).
+		Pluck("posts.id", &res).Error; err != nil {
+		return nil, fmt.Errorf("visible post ids: %w", err)
+	}
+	return res, nil
+}
+
+// Repost reports whether a new repost was created.
+func (r *postRepository) Repost(postID, userID uint) (bool, error) {
+	if postID == 0 || userID == 0 {
+		return false, fmt.Errorf("invalid ids")
+	}
+	rp := model.Repost{PostID: postID, UserID: userID}
+	res := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&rp)
+	if res.Error != nil {
+		return false, fmt.Errorf("repost: %w", res.Error)
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (r *postRepository) Unrepost(postID, userID uint) error {
+	if err := r.db.Where("post_id = ? AND user_id = ?", postID, userID).
+		Delete(&model.Repost{}).Error; err != nil {
+		return fmt.Errorf("unrepost: %w", err)
+	}
+	return nil
+}
+
+func (r *postRepository) RepostCounts(postIDs []uint) (map[uint]int, error) {
+	result := make(map[uint]int)
+	if len(postIDs) == 0 {
+		return result, nil
+	}
+
+	type row struct {
+		PostID uint
+		Count  int64
+	}
+
+	var rows []row
+	if err := r.db.Model(&model.Repost{}).
+		Select("post_id, count(*) as count").
+		Where("post_id IN ?", postIDs).
+		Group("post_id").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("repost counts: %w", err)
+	}
+
+	for _, row := range rows {
+		result[row.PostID] = int(row.Count)
+	}
+	return result, nil
+}
+
+func (r *postRepository) RepostedByUser(postIDs []uint, userID uint) (map[uint]bool, error) {
+	res := make(map[uint]bool)
+	if len(postIDs) == 0 || userID == 0 {
+		return res, nil
+	}
+
+	var ids []uint
+	if err := r.db.Model(&model.Repost{}).
+		Where("user_id = ? AND post_id IN ?", userID, postIDs).
+		Pluck("post_id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("reposted by user: %w", err)
+	}
+
+	for _, id := range ids {
+		res[id] = true
+	}
+	return res, nil
+}
+
+func (r *postRepository) ReactionCountsByPost(postIDs []uint) (map[uint]map[string]int, error) {
+	result := make(map[uint]map[string]int)
+	if len(postIDs) == 0 {
+		return result, nil
+	}
+
+	var rows []struct {
+		PostID   uint
+		Reaction string
+		Count    int
+	}
+	if err := r.db.Model(&model.PostLike{}).
+		Select("post_id, reaction, COUNT(*) AS count").
+		Where("post_id IN ?", postIDs).
+		Group("post_id, reaction").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("post reaction counts: %w", err)
+	}
+
+	for _, row := range rows {
+		if result[row.PostID] == nil {
+			result[row.PostID] = make(map[string]int)
+		}
+		result[row.PostID][row.Reaction] = row.Count
+	}
+	return result, nil
+}
+
+func (r *postRepository) ReactionsByUser(postIDs []uint, userID uint) (map[uint]string, error) {
+	res := make(map[uint]string)
+	if len(postIDs) == 0 || userID == 0 {
+		return res, nil
+	}
+
+	var likes []model.PostLike
+	if err := r.db.
+		Select("post_id, reaction").
+		Where("user_id = ? AND post_id IN ?", userID, postIDs).
+		Find(&likes).Error; err != nil {
+		return nil, fmt.Errorf("reactions by user: %w", err)
+	}
+
+	for _, l := range likes {
+		res[l.PostID] = l.Reaction
+	}
+	return res, nil
+}
+
+func (r *postRepository) CommentCounts(postIDs []uint) (map[uint]int, error) {
+	result := make(map[uint]int)
+	if len(postIDs) == 0 {
+		return result, nil
+	}
+
+	var rows []struct {
+		PostID uint
+		Count  int
+	}
+	if err := r.db.Model(&model.Comment{}).
+		Select("post_id, COUNT(*) AS count").
+		Where("post_id IN ?", postIDs).
+		Group("post_id").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("comment counts: %w", err)
+	}
+
+	for _, row := range rows {
+		result[row.PostID] = row.Count
+	}
+	return result, nil
+}
+
+// IncrementViews applies buffered view counts in a single transaction.
+func (r *postRepository) IncrementViews(counts map[uint]int64) error {
+	if len(counts) == 0 {
+		return nil
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for postID, n := range counts {
+			if err := tx.Model(&model.Post{}).
+				Where("id = ?", postID).
+				UpdateColumn("views_count", gorm.Expr("views_count + ?", n)).Error; err != nil {
+				return fmt.Errorf("increment views %d: %w", postID, err)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// visiblePostSQL is true for rows of posts the viewer may see: their own
+// posts, and published posts of users they haven't blocked (and who haven't
+// blocked them) whose audience includes them. It refers to the posts table
+// by name, so queries must not alias it.
+const visiblePostSQL = `(posts.user_id = ? OR (
+	posts.status = 'published'
+	AND NOT EXISTS (
+		SELECT 1 FROM blocks
+		WHERE (blocks.user_id = posts.user_id AND blocks.blocked_id = ?)
+		   OR (blocks.user_id = ? AND blocks.blocked_id = posts.user_id)
+	)
+	AND (
+		posts.visibility = 'public'
+		OR (posts.visibility = 'followers' AND EXISTS (
+			SELECT 1 FROM followers
+			WHERE followers.user_id = posts.user_id AND followers.follower_id = ?
+		))
+		OR (posts.visibility = 'close_friends' AND EXISTS (
+			SELECT 1 FROM close_friends
+			WHERE close_friends.user_id = posts.user_id AND close_friends.friend_id = ?
+		))
+	)
+))`
+
+// postVisibleTo is the condition every query handing out posts must include.
+func postVisibleTo(viewerID uint) clause.Expr {
+	return gorm.Expr(visiblePostSQL, viewerID, viewerID, viewerID, viewerID, viewerID)
+}
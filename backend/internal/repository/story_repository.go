@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// StoryTrayEntry is one author in the stories tray.
+type StoryTrayEntry struct {
+	UserID       uint
+	StoriesCount int
+	HasUnseen    bool
+	LatestAt     time.Time
+}
+
+type StoryRepository interface {
+	Create(story *model.Story) error
+	GetActive(id uint) (*model.Story, error)
+	ActiveByUser(userID uint) ([]model.Story, error)
+	Tray(viewerID uint, limit int) ([]StoryTrayEntry, error)
+	Delete(id uint) error
+	DeleteExpired(limit int) ([]string, error)
+
+	MarkViewed(storyID, userID uint) (bool, error)
+	SeenByUser(storyIDs []uint, userID uint) (map[uint]bool, error)
+	ViewerCounts(storyIDs []uint) (map[uint]int, error)
+	Viewers(storyID uint, limit int, cursor *Cursor) ([]model.StoryView, error)
+}
+
+type storyRepository struct {
+	db *gorm.DB
+}
+
+func NewStoryRepository(db *gorm.DB) StoryRepository {
+	return &storyRepository{db: db}
+}
+
+func (r *storyRepository) Create(story *model.Story) error {
+	if story == nil {
+		return fmt.Errorf("story is nil")
+	}
+	if err := r.db.Create(story).Error; err != nil {
+		return fmt.Errorf("create story: %w", err)
+	}
+	return nil
+}
+
+// GetActive returns ErrNotFound for expired stories too.
+func (r *storyRepository) GetActive(id uint) (*model.Story, error) {
+	var story model.Story
+	if err := r.db.
+		Preload("User").
+		Where("id = ? AND expires_at > NOW()", id).
+		First(&story).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get story: %w", err)
+	}
+	return &story, nil
+}
+
+// ActiveByUser returns the user's live stories in the order they were posted.
+func (r *storyRepository) ActiveByUser(userID uint) ([]model.Story, error) {
+	var stories []model.Story
+	if err := r.db.
+		Preload("User").
+		Where("user_id = ? AND expires_at > NOW()", userID).
+		Order("created_at, id").
+		Find(&stories).Error; err != nil {
+		return nil, fmt.Errorf("active stories: %w", err)
+	}
+	return stories, nil
+}
+
+// Tray lists followed users that have live stories: authors with stories the
+// viewer hasn't opened yet come first, then by the latest story.
+func (r *storyRepository) Tray(viewerID uint, limit int) ([]StoryTrayEntry, error) {
+	var entries []StoryTrayEntry
+	if err := r.db.Raw(`
+		SELECT stories.user_id,
+		       COUNT(*) AS stories_count,
+		       BOOL_OR(story_views.story_id IS NULL) AS has_unseen,
+		       MAX(stories.created_at) AS latest_at
+		FROM stories
+		JOIN followers ON followers.user_id = stories.user_id AND followers.follower_id = ?
+		LEFT JOIN story_views ON story_views.story_id = stories.id AND story_views.user_id = ?
+		WHERE stories.expires_at > NOW()
+		  AND NOT EXISTS (
+			SELECT 1 FROM blocks
+			WHERE (blocks.user_id = stories.user_id AND blocks.blocked_id = ?)
+			   OR (blocks.user_id = ? AND blocks.blocked_id = stories.user_id)
+		  )
+		GROUP BY stories.user_id
+		ORDER BY has_unseen DESC, latest_at DESC
+		LIMIT ?`,
+		viewerID, viewerID, viewerID, viewerID, limit,
+	).Scan(&entries).Error; err != nil {
+		return nil, fmt.Errorf("stories tray: %w", err)
+	}
+	return entries, nil
+}
+
+func (r *storyRepository) Delete(id uint) error {
+	if err := r.db.Delete(&model.Story{}, id).Error; err != nil {
+		return fmt.Errorf("delete story: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired removes up to limit expired stories and returns their media
+// URLs so the files can be removed from disk. SKIP LOCKED keeps replicas
+// running the cleanup at once from fighting over the same rows.
+func (r *storyRepository) DeleteExpired(limit int) ([]string, error) {
+	var urls []string
+	if err := r.db.Raw(`
+		DELETE FROM stories
+		WHERE id IN (
+			SELECT id FROM stories
+			WHERE expires_at <= NOW()
+			ORDER BY expires_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING media_url`,
+		limit,
+	).Scan(&urls).Error; err != nil {
+		return nil, fmt.Errorf("delete expired stories: %w", err)
+	}
+	return urls, nil
+}
+
+// MarkViewed records the first view of a story; repeated views return false.
+func (r *storyRepository) MarkViewed(storyID, userID uint) (bool, error) {
+	res := r.db.Exec(`
+		INSERT INTO story_views (story_id, user_id, viewed_at)
+		VALUES (?, ?, NOW())
+		ON CONFLICT DO NOTHING`,
+		storyID, userID,
+	)
+	if res.Error != nil {
+		return false, fmt.Errorf("mark story viewed: %w", res.Error)
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (r *storyRepository) SeenByUser(storyIDs []uint, userID uint) (map[uint]bool, error) {
+	res := make(map[uint]bool, len(storyIDs))
+	if len(storyIDs) == 0 {
+		return res, nil
+	}
+
+	var ids []uint
+	if err := r.db.Model(&model.StoryView{}).
+		Where("story_id IN ? AND user_id = ?", storyIDs, userID).
+		Pluck("story_id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("seen stories: %w", err)
+	}
+	for _, id := range ids {
+		res[id] = true
+	}
+	return res, nil
+}
+
+func (r *storyRepository) ViewerCounts(storyIDs []uint) (map[uint]int, error) {
+	res := make(map[uint]int, len(storyIDs))
+	if len(storyIDs) == 0 {
+		return res, nil
+	}
+
+	var rows []struct {
+		StoryID uint
+		Count   int
+	}
+	if err := r.db.Model(&model.StoryView{}).
+		Select("story_id, COUNT(*) AS count").
+		Where("story_id IN ?", storyIDs).
+		Group("story_id").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("story viewer counts: %w", err)
+	}
+	for _, row := range rows {
+		res[row.StoryID] = row.Count
+	}
+	return res, nil
+}
+
+// Viewers pages through a story's viewers, most recent first. The cursor ID
+// is the viewer's user id.
+func (r *storyRepository) Viewers(storyID uint, limit int, cursor *Cursor) ([]model.StoryView, error) {
+	var views []model.StoryView
+
+	q := r.db.
+		Where("story_id = ?", storyID).
+		Preload("User").
+		Order("viewed_at DESC, user_id DESC")
+
+	if cursor != nil {
+		q = q.Where("(viewed_at, user_id) < (?, ?)", cursor.Time, cursor.ID)
+	}
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+
+	if err := q.Find(&views).Error; err != nil {
+		return nil, fmt.Errorf("story viewers: %w", err)
+	}
+	return views, nil
+}
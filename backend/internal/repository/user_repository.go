@@ -16,6 +16,7 @@ type UserRepository interface {
 	Create(user *model.User) error
 	GetByID(id uint) (*model.User, error)
 	GetByIDWithPreloads(id uint) (*model.User, error)
+	GetByIDs(ids []uint) ([]model.User, error)
 	GetByEmail(email string) (*model.User, error)
 	GetByNickname(nickname string) (*model.User, error)
 	Update(user *model.User) error
@@ -27,10 +28,21 @@ type UserRepository interface {
 	GetFollowingCount(userID uint) (int64, error)
 
 	Search(query string) ([]model.User, error)
-	Follow(userID, targetID uint) error
+	Follow(userID, targetID uint) (bool, error)
 	Unfollow(userID, targetID uint) error
 	GetFollowers(userID uint) ([]model.User, error)
 	GetFollowing(userID uint) ([]model.User, error)
+	GetFollowerIDs(userID uint) ([]uint, error)
+
+	Block(userID, targetID uint) error
+	Unblock(userID, targetID uint) error
+	IsBlockedEither(userID, targetID uint) (bool, error)
+	GetBlocked(userID uint) ([]model.User, error)
+
+	AddCloseFriend(userID, friendID uint) error
+	RemoveCloseFriend(userID, friendID uint) error
+	GetCloseFriends(userID uint) ([]model.User, error)
+	GetCloseFriendIDs(userID uint) ([]uint, error)
 }
 
 type userRepository struct {
@@ -76,6 +88,17 @@ func (r *userRepository) GetByID(id uint) (*model.User, error) {
 	return &user, nil
 }
 
+func (r *userRepository) GetByIDs(ids []uint) ([]model.User, error) {
+	var users []model.User
+	if len(ids) == 0 {
+		return users, nil
+	}
+	if err := r.db.Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("get users by ids: %w", err)
+	}
+	return users, nil
+}
+
 func (r *userRepository) GetByIDWithPreloads(id uint) (*model.User, error) {
 	var user model.User
 	if err := r.db.Preload("Posts").Preload("Followers").Preload("Following").First(&user, id).Error; err != nil {
@@ -128,7 +151,9 @@ func (r *userRepository) Delete(id uint) error {
 
 func (r *userRepository) GetPostsCount(userID uint) (int64, error) {
 	var cnt int64
-	if err := r.db.Model(&model.Post{}).Where("user_id = ?", userID).Count(&cnt).Error; err != nil {
+	if err := r.db.Model(&model.Post{}).
+		Where("user_id = ? AND status = ?", userID, model.PostStatusPublished).
+		Count(&cnt).Error; err != nil {
 		return 0, fmt.Errorf("count posts: %w", err)
 	}
 	return cnt, nil
@@ -199,18 +224,20 @@ func reverseSlice(s []string) []string {
 	return reversed
 }
 
-func (r *userRepository) Follow(userID, targetID uint) error {
+// Follow reports whether a new follow was created.
+func (r *userRepository) Follow(userID, targetID uint) (bool, error) {
 	if userID == 0 || targetID == 0 {
-		return fmt.Errorf("invalid ids")
+		return false, fmt.Errorf("invalid ids")
 	}
 	if userID == targetID {
-		return fmt.Errorf("cannot follow yourself")
+		return false, fmt.Errorf("cannot follow yourself")
 	}
 	f := model.Follower{UserID: targetID, FollowerID: userID}
-	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&f).Error; err != nil {
-		return fmt.Errorf("follow create: %w", err)
+	res := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&f)
+	if res.Error != nil {
+		return false, fmt.Errorf("follow create: %w", res.Error)
 	}
-	return nil
+	return res.RowsAffected > 0, nil
 }
 
 func (r *userRepository) Unfollow(userID, targetID uint) error {
@@ -246,3 +273,116 @@ func (r *userRepository) GetFollowing(userID uint) ([]model.User, error) {
 
 	return users, nil
 }
+
+func (r *userRepository) GetFollowerIDs(userID uint) ([]uint, error) {
+	var ids []uint
+	if err := r.db.Model(&model.Follower{}).
+		Where("user_id = ?", userID).
+		Pluck("follower_id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("get follower ids: %w", err)
+	}
+	return ids, nil
+}
+
+// Block also drops follow and close friend relations in both directions.
+func (r *userRepository) Block(userID, targetID uint) error {
+	if userID == 0 || targetID == 0 {
+		return fmt.Errorf("invalid ids")
+	}
+	if userID == targetID {
+		return fmt.Errorf("cannot block yourself")
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		b := model.Block{UserID: userID, BlockedID: targetID}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&b).Error; err != nil {
+			return fmt.Errorf("block create: %w", err)
+		}
+		if err := tx.Where("(user_id = ? AND follower_id = ?) OR (user_id = ? AND follower_id = ?)",
+			userID, targetID, targetID, userID).
+			Delete(&model.Follower{}).Error; err != nil {
+			return fmt.Errorf("block unfollow: %w", err)
+		}
+		if err := tx.Where("(user_id = ? AND friend_id = ?) OR (user_id = ? AND friend_id = ?)",
+			userID, targetID, targetID, userID).
+			Delete(&model.CloseFriend{}).Error; err != nil {
+			return fmt.Errorf("block close friends: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *userRepository) Unblock(userID, targetID uint) error {
+	if err := r.db.Where("user_id = ? AND blocked_id = ?", userID, targetID).
+		Delete(&model.Block{}).Error; err != nil {
+		return fmt.Errorf("unblock delete: %w", err)
+	}
+	return nil
+}
+
+func (r *userRepository) IsBlockedEither(userID, targetID uint) (bool, error) {
+	var count int64
+	if err := r.db.Model(&model.Block{}).
+		Where("(user_id = ? AND blocked_id = ?) OR (user_id = ? AND blocked_id = ?)",
+			userID, targetID, targetID, userID).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("check blocked: %w", err)
+	}
+	return count > 0, nil
+}
+
+func (r *userRepository) GetBlocked(userID uint) ([]model.User, error) {
+	var users []model.User
+
+	if err := r.db.
+		Joins("JOIN blocks ON blocks.blocked_id = users.id").
+		Where("blocks.user_id = ?", userID).
+		Order("blocks.created_at DESC").
+		Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("get blocked: %w", err)
+	}
+
+	return users, nil
+}
+
+func (r *userRepository) AddCloseFriend(userID, friendID uint) error {
+	if userID == 0 || friendID == 0 {
+		return fmt.Errorf("invalid ids")
+	}
+	cf := model.CloseFriend{UserID: userID, FriendID: friendID}
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&cf).Error; err != nil {
+		return fmt.Errorf("add close friend: %w", err)
+	}
+	return nil
+}
+
+func (r *userRepository) RemoveCloseFriend(userID, friendID uint) error {
+	if err := r.db.Where("user_id = ? AND friend_id = ?", userID, friendID).
+		Delete(&model.CloseFriend{}).Error; err != nil {
+		return fmt.Errorf("remove close friend: %w", err)
+	}
+	return nil
+}
+
+func (r *userRepository) GetCloseFriends(userID uint) ([]model.User, error) {
+	var users []model.User
+
+	if err := r.db.
+		Joins("JOIN close_friends ON close_friends.friend_id = users.id").
+		Where("close_friends.user_id = ?", userID).
+		Order("close_friends.created_at DESC").
+		Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("get close friends: %w", err)
+	}
+
+	return users, nil
+}
+
+func (r *userRepository) GetCloseFriendIDs(userID uint) ([]uint, error) {
+	var ids []uint
+	if err := r.db.Model(&model.CloseFriend{}).
+		Where("user_id = ?", userID).
+		Pluck("friend_id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("get close friend ids: %w", err)
+	}
+	return ids, nil
+}
@@ -0,0 +1,147 @@
+package seen
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// memorySweepInterval is how often Run drops expired entries.
+const memorySweepInterval = 10 * time.Minute
+
+// MemoryStore keeps everything in process; fine for a single replica. Add
+// only prunes the user it writes to, so Run sweeps the rest.
+type MemoryStore struct {
+	ttl      time.Duration
+	maxPosts int
+
+	mu     sync.Mutex
+	posts  map[uint]map[uint]time.Time
+	visits map[uint]visit
+}
+
+// visit is a read mark and when it was written; the mark expires by the
+// latter, as the Redis key does after SET.
+type visit struct {
+	at      time.Time
+	written time.Time
+}
+
+func NewMemoryStore(ttl time.Duration, maxPosts int) *MemoryStore {
+	return &MemoryStore{
+		ttl:      ttl,
+		maxPosts: maxPosts,
+		posts:    make(map[uint]map[uint]time.Time),
+		visits:   make(map[uint]visit),
+	}
+}
+
+func (s *MemoryStore) Add(_ context.Context, userID uint, postIDs []uint) error {
+	if len(postIDs) == 0 {
+		return nil
+	}
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	served := s.posts[userID]
+	if served == nil {
+		served = make(map[uint]time.Time)
+		s.posts[userID] = served
+	}
+	for _, id := range postIDs {
+		served[id] = now
+	}
+
+	// заодно выкидываем истёкшие и самые старые сверх лимита
+	for id, at := range served {
+		if now.Sub(at) >= s.ttl {
+			delete(served, id)
+		}
+	}
+	if len(served) > s.maxPosts {
+		ids := make([]uint, 0, len(served))
+		for id := range served {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool { return served[ids[i]].Before(served[ids[j]]) })
+		for _, id := range ids[:len(ids)-s.maxPosts] {
+			delete(served, id)
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) List(_ context.Context, userID uint) ([]uint, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ids []uint
+	for id, at := range s.posts[userID] {
+		if now.Sub(at) < s.ttl {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// Visit forgets a mark written more than the TTL ago, like the Redis key
+// expiring.
+func (s *MemoryStore) Visit(_ context.Context, userID uint, at time.Time) (time.Time, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var prev time.Time
+	if v, ok := s.visits[userID]; ok && now.Sub(v.written) < s.ttl {
+		prev = v.at
+	}
+	if at.After(prev) {
+		s.visits[userID] = visit{at: at, written: now}
+	}
+	return prev, nil
+}
+
+// Run sweeps expired served posts, users left with none and expired read
+// marks until ctx is done.
+func (s *MemoryStore) Run(ctx context.Context) {
+	ticker := time.NewTicker(memorySweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sweep()
+		}
+	}
+}
+
+func (s *MemoryStore) sweep() {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for userID, served := range s.posts {
+		for id, at := range served {
+			if now.Sub(at) >= s.ttl {
+				delete(served, id)
+			}
+		}
+		if len(served) == 0 {
+			delete(s.posts, userID)
+		}
+	}
+	for userID, v := range s.visits {
+		if now.Sub(v.written) >= s.ttl {
+			delete(s.visits, userID)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package seen
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"backend/internal/redis"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	redisPostsPrefix = "seen:posts:"
+	redisVisitPrefix = "seen:visit:"
+)
+
+// RedisStore keeps served posts in a sorted set per user, scored by the time
+// they were served, so expired and surplus ones can be cut by score and rank.
+// The whole set expires when the user stops reading.
+type RedisStore struct {
+	rdb      *redis.Client
+	ttl      time.Duration
+	maxPosts int
+}
+
+func NewRedisStore(rdb *redis.Client, ttl time.Duration, maxPosts int) *RedisStore {
+	return &RedisStore{rdb: rdb, ttl: ttl, maxPosts: maxPosts}
+}
+
+func redisPostsKey(userID uint) string {
+	return redisPostsPrefix + strconv.FormatUint(uint64(userID), 10)
+}
+
+func redisVisitKey(userID uint) string {
+	return redisVisitPrefix + strconv.FormatUint(uint64(userID), 10)
+}
+
+func (s *RedisStore) Add(ctx context.Context, userID uint, postIDs []uint) error {
+	if len(postIDs) == 0 {
+		return nil
+	}
+	now := time.Now()
+	key := redisPostsKey(userID)
+
+	zs := make([]goredis.Z, 0, len(postIDs))
+	for _, id := range postIDs {
+		zs = append(zs, goredis.Z{Score: float64(now.Unix()), Member: strconv.FormatUint(uint64(id), 10)})
+	}
+	pipe := s.rdb.Pipeline()
+	pipe.ZAdd(ctx, key, zs...)
+	pipe.ZRemRangeByScore(ctx, key, "-inf", "("+strconv.FormatInt(now.Add(-s.ttl).Unix(), 10))
+	pipe.ZRemRangeByRank(ctx, key, 0, int64(-s.maxPosts-1))
+	pipe.Expire(ctx, key, s.ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("add seen posts of %d: %w", userID, err)
+	}
+	return nil
+}
+
+func (s *RedisStore) List(ctx context.Context, userID uint) ([]uint, error) {
+	members, err := s.rdb.ZRangeByScore(ctx, redisPostsKey(userID), &goredis.ZRangeBy{
+		Min: strconv.FormatInt(time.Now().Add(-s.ttl).Unix(), 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("list seen posts of %d: %w", userID, err)
+	}
+	ids := make([]uint, 0, len(members))
+	for _, m := range members {
+		id, err := strconv.ParseUint(m, 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids, nil
+}
+
+// Visit reads and then writes the mark; two concurrent first pages may both
+// see the old mark, which only shows the marker twice.
+func (s *RedisStore) Visit(ctx context.Context, userID uint, at time.Time) (time.Time, error) {
+	key := redisVisitKey(userID)
+	var prev time.Time
+	raw, err := s.rdb.Get(ctx, key).Result()
+	switch {
+	case err == goredis.Nil:
+	case err != nil:
+		return time.Time{}, fmt.Errorf("get visit of %d: %w", userID, err)
+	default:
+		if nanos, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			prev = time.Unix(0, nanos)
+		}
+	}
+
+	if at.After(prev) {
+		if err := s.rdb.Set(ctx, key, at.UnixNano(), s.ttl).Err(); err != nil {
+			return time.Time{}, fmt.Errorf("set visit of %d: %w", userID, err)
+		}
+	}
+	return prev, nil
+}
@@ -0,0 +1,21 @@
+package seen
+
+import (
+	"context"
+	"time"
+)
+
+// Store remembers, per user, which posts the feed has already served and how
+// far the user has read their feed. Served posts and read marks expire after
+// a TTL and the oldest posts are dropped past a maximum count, so the
+// storage stays small.
+type Store interface {
+	// Add marks posts as served to userID.
+	Add(ctx context.Context, userID uint, postIDs []uint) error
+	// List returns the posts served to userID that haven't expired yet.
+	List(ctx context.Context, userID uint) ([]uint, error)
+	// Visit moves the user's read mark forward to at and returns the mark as
+	// it was, zero for a user seen for the first time or after the mark
+	// expired. A mark is never moved back.
+	Visit(ctx context.Context, userID uint, at time.Time) (time.Time, error)
+}
@@ -0,0 +1,380 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"backend/internal/dto"
+	"backend/internal/model"
+	"backend/internal/repository"
+)
+
+const (
+	// AnalyticsFlushInterval is how often buffered events are rolled up into
+	// post_stats.
+	AnalyticsFlushInterval = time.Minute
+	// FollowAttributionWindow: a follow counts for the author's post the
+	// follower viewed last within this window.
+	FollowAttributionWindow = 24 * time.Hour
+
+	defaultInsightsDays = 7
+	maxInsightsDays     = 90
+	maxHourlyDays       = 7
+	topPostsLimit       = 5
+)
+
+var ErrInvalidInsightsRange = errors.New("interval must be hour or day; days must be 1-90 (1-7 for hour)")
+
+type AnalyticsService interface {
+	TrackView(postID, viewerID uint)
+	TrackLike(postID, userID uint)
+	TrackComment(postID, userID uint)
+	TrackBookmark(postID, userID uint)
+	TrackFollow(followerID, followeeID uint)
+
+	// Run rolls up buffered events periodically until ctx is done.
+	Run(ctx context.Context)
+	Flush(ctx context.Context) error
+
+	PostInsights(postID, userID uint, interval string, days int) (*dto.PostInsightsResponse, error)
+	ProfileInsights(userID uint, interval string, days int) (*dto.ProfileInsightsResponse, error)
+}
+
+type statKey struct {
+	postID uint
+	bucket int64
+}
+
+type viewerKey struct {
+	postID uint
+	userID uint
+}
+
+type pendingFollow struct {
+	followerID uint
+	followeeID uint
+	at         time.Time
+}
+
+// analyticsBuffer is what piles up between flushes.
+type analyticsBuffer struct {
+	stats   map[statKey]*model.PostStat
+	viewers map[viewerKey]*model.PostViewer
+	follows []pendingFollow
+}
+
+func newAnalyticsBuffer() *analyticsBuffer {
+	return &analyticsBuffer{
+		stats:   make(map[statKey]*model.PostStat),
+		viewers: make(map[viewerKey]*model.PostViewer),
+	}
+}
+
+func (b *analyticsBuffer) stat(postID uint, at time.Time) *model.PostStat {
+	bucket := at.UTC().Truncate(time.Hour)
+	key := statKey{postID: postID, bucket: bucket.Unix()}
+	st, ok := b.stats[key]
+	if !ok {
+		st = &model.PostStat{PostID: postID, Bucket: bucket}
+		b.stats[key] = st
+	}
+	return st
+}
+
+func (b *analyticsBuffer) viewer(postID, userID uint, at time.Time) {
+	key := viewerKey{postID: postID, userID: userID}
+	v, ok := b.viewers[key]
+	if !ok {
+		b.viewers[key] = &model.PostViewer{PostID: postID, UserID: userID, FirstSeenAt: at, LastSeenAt: at}
+		return
+	}
+	if at.Before(v.FirstSeenAt) {
+		v.FirstSeenAt = at
+	}
+	if at.After(v.LastSeenAt) {
+		v.LastSeenAt = at
+	}
+}
+
+// merge puts a buffer that failed to flush back, so it goes out next time.
+func (b *analyticsBuffer) merge(o *analyticsBuffer) {
+	for _, s := range o.stats {
+		st := b.stat(s.PostID, s.Bucket)
+		st.Views += s.Views
+		st.Likes += s.Likes
+		st.Comments += s.Comments
+		st.Bookmarks += s.Bookmarks
+		st.FollowsGained += s.FollowsGained
+	}
+	for _, v := range o.viewers {
+		b.viewer(v.PostID, v.UserID, v.FirstSeenAt)
+		b.viewer(v.PostID, v.UserID, v.LastSeenAt)
+	}
+	b.follows = append(b.follows, o.follows...)
+}
+
+type analyticsService struct {
+	repo     repository.AnalyticsRepository
+	postRepo repository.PostRepository
+
+	mu  sync.Mutex
+	buf *analyticsBuffer
+}
+
+func NewAnalyticsService(repo repository.AnalyticsRepository, postRepo repository.PostRepository) AnalyticsService {
+	return &analyticsService{
+		repo:     repo,
+		postRepo: postRepo,
+		buf:      newAnalyticsBuffer(),
+	}
+}
+
+func (s *analyticsService) track(fn func(b *analyticsBuffer, now time.Time)) {
+	now := time.Now()
+	s.mu.Lock()
+	fn(s.buf, now)
+	s.mu.Unlock()
+}
+
+func (s *analyticsService) TrackView(postID, viewerID uint) {
+	s.track(func(b *analyticsBuffer, now time.Time) {
+		b.stat(postID, now).Views++
+		b.viewer(postID, viewerID, now)
+	})
+}
+
+func (s *analyticsService) TrackLike(postID, _ uint) {
+	s.track(func(b *analyticsBuffer, now time.Time) {
+		b.stat(postID, now).Likes++
+	})
+}
+
+func (s *analyticsService) TrackComment(postID, _ uint) {
+	s.track(func(b *analyticsBuffer, now time.Time) {
+		b.stat(postID, now).Comments++
+	})
+}
+
+func (s *analyticsService) TrackBookmark(postID, _ uint) {
+	s.track(func(b *analyticsBuffer, now time.Time) {
+		b.stat(postID, now).Bookmarks++
+	})
+}
+
+// TrackFollow is attributed to a post at flush time, after the viewers
+// buffered so far are written.
+func (s *analyticsService) TrackFollow(followerID, followeeID uint) {
+	s.track(func(b *analyticsBuffer, now time.Time) {
+		b.follows = append(b.follows, pendingFollow{followerID: followerID, followeeID: followeeID, at: now})
+	})
+}
+
+func (s *analyticsService) Run(ctx context.Context) {
+	ticker := time.NewTicker(AnalyticsFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Flush(ctx); err != nil {
+				log.Printf("analytics: flush: %v", err)
+			}
+		}
+	}
+}
+
+func (s *analyticsService) Flush(_ context.Context) error {
+	s.mu.Lock()
+	buf := s.buf
+	s.buf = newAnalyticsBuffer()
+	s.mu.Unlock()
+
+	if err := s.apply(buf); err != nil {
+		s.mu.Lock()
+		s.buf.merge(buf)
+		s.mu.Unlock()
+		return err
+	}
+
+	if len(buf.follows) == 0 {
+		return nil
+	}
+
+	// подписки приписываем после записи зрителей, иначе свежий просмотр не найдётся
+	attributed := newAnalyticsBuffer()
+	for _, f := range buf.follows {
+		postID, err := s.repo.LastViewedPost(f.followerID, f.followeeID, f.at.Add(-FollowAttributionWindow))
+		if err != nil {
+			if !errors.Is(err, repository.ErrNotFound) {
+				log.Printf("analytics: attribute follow %d->%d: %v", f.followerID, f.followeeID, err)
+			}
+			continue
+		}
+		attributed.stat(postID, f.at).FollowsGained++
+	}
+	if err := s.apply(attributed); err != nil {
+		s.mu.Lock()
+		s.buf.merge(attributed)
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (s *analyticsService) apply(buf *analyticsBuffer) error {
+	if len(buf.stats) == 0 && len(buf.viewers) == 0 {
+		return nil
+	}
+
+	stats := make([]model.PostStat, 0, len(buf.stats))
+	for _, st := range buf.stats {
+		stats = append(stats, *st)
+	}
+	viewers := make([]model.PostViewer, 0, len(buf.viewers))
+	for _, v := range buf.viewers {
+		viewers = append(viewers, *v)
+	}
+	return s.repo.ApplyBatch(stats, viewers)
+}
+
+// insightsRange validates the query and returns the truncation unit, step
+// and start of the range.
+func insightsRange(interval string, days int) (string, time.Duration, time.Time, error) {
+	if interval == "" {
+		interval = "day"
+	}
+	if days == 0 {
+		days = defaultInsightsDays
+	}
+
+	var step time.Duration
+	switch interval {
+	case "hour":
+		if days > maxHourlyDays {
+			return "", 0, time.Time{}, ErrInvalidInsightsRange
+		}
+		step = time.Hour
+	case "day":
+		step = 24 * time.Hour
+	default:
+		return "", 0, time.Time{}, ErrInvalidInsightsRange
+	}
+	if days < 1 || days > maxInsightsDays {
+		return "", 0, time.Time{}, ErrInvalidInsightsRange
+	}
+
+	since := time.Now().UTC().Add(-time.Duration(days) * 24 * time.Hour).Truncate(step)
+	return interval, step, since, nil
+}
+
+// buildSeries fills empty buckets so the client can chart the series as is,
+// and sums the totals.
+func buildSeries(rows []repository.StatBucket, step time.Duration, since time.Time) ([]dto.InsightsPoint, dto.InsightsCounters) {
+	byBucket := make(map[int64]repository.StatBucket, len(rows))
+	for _, r := range rows {
+		byBucket[r.Bucket.Unix()] = r
+	}
+
+	var totals dto.InsightsCounters
+	var series []dto.InsightsPoint
+	now := time.Now().UTC()
+	for t := since; !t.After(now); t = t.Add(step) {
+		r := byBucket[t.Unix()]
+		c := dto.InsightsCounters{
+			Views:         r.Views,
+			UniqueViewers: r.UniqueViewers,
+			Likes:         r.Likes,
+			Comments:      r.Comments,
+			Bookmarks:     r.Bookmarks,
+			FollowsGained: r.FollowsGained,
+		}
+		series = append(series, dto.InsightsPoint{Time: t, InsightsCounters: c})
+
+		totals.Views += c.Views
+		totals.UniqueViewers += c.UniqueViewers
+		totals.Likes += c.Likes
+		totals.Comments += c.Comments
+		totals.Bookmarks += c.Bookmarks
+		totals.FollowsGained += c.FollowsGained
+	}
+	return series, totals
+}
+
+func (s *analyticsService) PostInsights(postID, userID uint, interval string, days int) (*dto.PostInsightsResponse, error) {
+	if postID == 0 || userID == 0 {
+		return nil, ErrForbidden
+	}
+	unit, step, since, err := insightsRange(interval, days)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := s.postRepo.FindByID(postID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if post.UserID != userID {
+		return nil, ErrForbidden
+	}
+
+	rows, err := s.repo.PostSeries(postID, unit, since)
+	if err != nil {
+		return nil, err
+	}
+	series, totals := buildSeries(rows, step, since)
+
+	return &dto.PostInsightsResponse{
+		PostID:   postID,
+		Interval: unit,
+		Since:    since,
+		Totals:   totals,
+		Series:   series,
+	}, nil
+}
+
+func (s *analyticsService) ProfileInsights(userID uint, interval string, days int) (*dto.ProfileInsightsResponse, error) {
+	if userID == 0 {
+		return nil, ErrForbidden
+	}
+	unit, step, since, err := insightsRange(interval, days)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.repo.AuthorSeries(userID, unit, since)
+	if err != nil {
+		return nil, err
+	}
+	series, totals := buildSeries(rows, step, since)
+
+	if totals.UniqueViewers, err = s.repo.AuthorUniqueViewers(userID, since); err != nil {
+		return nil, err
+	}
+
+	top, err := s.repo.AuthorTopPosts(userID, since, topPostsLimit)
+	if err != nil {
+		return nil, err
+	}
+	topPosts := make([]dto.TopPostDTO, 0, len(top))
+	for _, t := range top {
+		topPosts = append(topPosts, dto.TopPostDTO{
+			PostID:   t.PostID,
+			Views:    t.Views,
+			Likes:    t.Likes,
+			Comments: t.Comments,
+		})
+	}
+
+	return &dto.ProfileInsightsResponse{
+		Interval: unit,
+		Since:    since,
+		Totals:   totals,
+		Series:   series,
+		TopPosts: topPosts,
+	}, nil
+}
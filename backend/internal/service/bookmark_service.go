@@ -0,0 +1,224 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"backend/internal/dto"
+	"backend/internal/model"
+	"backend/internal/repository"
+)
+
+const MaxCollectionNameLength = 64
+
+type BookmarkService interface {
+	Bookmark(userID, postID uint, req dto.BookmarkRequest) (*dto.BookmarkResponse, error)
+	Unbookmark(userID, postID uint) (*dto.BookmarkResponse, error)
+	List(userID uint, collectionID *uint, limit int, cursor string) (*dto.BookmarkListResponse, error)
+
+	CreateCollection(userID uint, req dto.CollectionRequest) (*dto.CollectionDTO, error)
+	RenameCollection(userID, collectionID uint, req dto.CollectionRequest) (*dto.CollectionDTO, error)
+	DeleteCollection(userID, collectionID uint) error
+	ListCollections(userID uint) ([]dto.CollectionDTO, error)
+}
+
+type bookmarkService struct {
+	repo         repository.BookmarkRepository
+	postRepo     repository.PostRepository
+	views        *PostViewBuilder
+	analyticsSvc AnalyticsService
+}
+
+func NewBookmarkService(
+	repo repository.BookmarkRepository,
+	postRepo repository.PostRepository,
+	views *PostViewBuilder,
+	analyticsSvc AnalyticsService,
+) BookmarkService {
+	return &bookmarkService{repo: repo, postRepo: postRepo, views: views, analyticsSvc: analyticsSvc}
+}
+
+func (s *bookmarkService) Bookmark(userID, postID uint, req dto.BookmarkRequest) (*dto.BookmarkResponse, error) {
+	if userID == 0 || postID == 0 {
+		return nil, fmt.Errorf("invalid ids")
+	}
+	if _, err := s.postRepo.FindByID(postID, userID); err != nil {
+		return nil, err
+	}
+	if req.CollectionID != nil {
+		if _, err := s.ownCollection(userID, *req.CollectionID); err != nil {
+			return nil, err
+		}
+	}
+
+	// повторный Save только переносит закладку в другую коллекцию
+	already, err := s.repo.BookmarkedByUser([]uint{postID}, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(userID, postID, req.CollectionID); err != nil {
+		return nil, err
+	}
+	if !already[postID] {
+		s.analyticsSvc.TrackBookmark(postID, userID)
+	}
+	return &dto.BookmarkResponse{PostID: postID, Bookmarked: true, CollectionID: req.CollectionID}, nil
+}
+
+func (s *bookmarkService) Unbookmark(userID, postID uint) (*dto.BookmarkResponse, error) {
+	if userID == 0 || postID == 0 {
+		return nil, fmt.Errorf("invalid ids")
+	}
+	if err := s.repo.Remove(userID, postID); err != nil {
+		return nil, err
+	}
+	return &dto.BookmarkResponse{PostID: postID, Bookmarked: false}, nil
+}
+
+func (s *bookmarkService) List(userID uint, collectionID *uint, limit int, cursor string) (*dto.BookmarkListResponse, error) {
+	if userID == 0 {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if collectionID != nil {
+		if _, err := s.ownCollection(userID, *collectionID); err != nil {
+			return nil, err
+		}
+	}
+
+	cur, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.repo.List(userID, collectionID, limit+1, cur)
+	if err != nil {
+		return nil, err
+	}
+
+	hasMore := len(items) > limit
+	if hasMore {
+		items = items[:limit]
+	}
+
+	posts := make([]model.Post, 0, len(items))
+	for _, b := range items {
+		posts = append(posts, b.Post)
+	}
+	resp, err := s.views.Build(posts, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var nextCursor *string
+	if hasMore {
+		last := items[len(items)-1]
+		c := encodeCursor(last.CreatedAt, last.ID)
+		nextCursor = &c
+	}
+
+	return &dto.BookmarkListResponse{
+		Posts:      resp,
+		NextCursor: nextCursor,
+		HasMore:    hasMore,
+	}, nil
+}
+
+// ownCollection hides other users' collections behind ErrNotFound.
+func (s *bookmarkService) ownCollection(userID, collectionID uint) (*model.BookmarkCollection, error) {
+	c, err := s.repo.GetCollection(collectionID)
+	if err != nil {
+		return nil, err
+	}
+	if c.UserID != userID {
+		return nil, repository.ErrNotFound
+	}
+	return c, nil
+}
+
+func normalizeCollectionName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("name is required")
+	}
+	if len([]rune(name)) > MaxCollectionNameLength {
+		return "", fmt.Errorf("name is too long, max %d characters", MaxCollectionNameLength)
+	}
+	return name, nil
+}
+
+func (s *bookmarkService) CreateCollection(userID uint, req dto.CollectionRequest) (*dto.CollectionDTO, error) {
+	if userID == 0 {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	name, err := normalizeCollectionName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	c := model.BookmarkCollection{UserID: userID, Name: name}
+	if err := s.repo.CreateCollection(&c); err != nil {
+		return nil, err
+	}
+	return &dto.CollectionDTO{ID: c.ID, Name: c.Name, CreatedAt: c.CreatedAt}, nil
+}
+
+func (s *bookmarkService) RenameCollection(userID, collectionID uint, req dto.CollectionRequest) (*dto.CollectionDTO, error) {
+	c, err := s.ownCollection(userID, collectionID)
+	if err != nil {
+		return nil, err
+	}
+	name, err := normalizeCollectionName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.RenameCollection(c.ID, name); err != nil {
+		return nil, err
+	}
+
+	counts, err := s.repo.CollectionCounts([]uint{c.ID})
+	if err != nil {
+		return nil, err
+	}
+	return &dto.CollectionDTO{ID: c.ID, Name: name, BookmarksCount: counts[c.ID], CreatedAt: c.CreatedAt}, nil
+}
+
+func (s *bookmarkService) DeleteCollection(userID, collectionID uint) error {
+	c, err := s.ownCollection(userID, collectionID)
+	if err != nil {
+		return err
+	}
+	return s.repo.DeleteCollection(c.ID)
+}
+
+func (s *bookmarkService) ListCollections(userID uint) ([]dto.CollectionDTO, error) {
+	if userID == 0 {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	items, err := s.repo.ListCollections(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(items))
+	for _, c := range items {
+		ids = append(ids, c.ID)
+	}
+	counts, err := s.repo.CollectionCounts(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dto.CollectionDTO, 0, len(items))
+	for _, c := range items {
+		res = append(res, dto.CollectionDTO{
+			ID:             c.ID,
+			Name:           c.Name,
+			BookmarksCount: counts[c.ID],
+			CreatedAt:      c.CreatedAt,
+		})
+	}
+	return res, nil
+}
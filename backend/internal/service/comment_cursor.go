@@ -0,0 +1,79 @@
+package service
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/repository"
+)
+
+func parseCommentSort(s string) (string, error) {
+	switch s {
+	case "":
+		return repository.CommentSortNew, nil
+	case repository.CommentSortTop, repository.CommentSortNew, repository.CommentSortOld:
+		return s, nil
+	}
+	return "", ErrInvalidCommentSort
+}
+
+// Root comment cursors name the sort they belong to and the boosted group:
+// "<new|old>_<1|0>_<unix nanos>_<id>", or "top_<1|0>_<anchor>_<score>_<id>"
+// where anchor is the unix nanos the first page was scored at, so every
+// later page scores comments as of the same moment.
+func encodeCommentCursor(sort string, anchor time.Time, c repository.RankedComment) string {
+	group := "0"
+	if c.Boosted {
+		group = "1"
+	}
+	prefix := sort + "_" + group + "_"
+	if sort == repository.CommentSortTop {
+		return prefix + strconv.FormatInt(anchor.UnixNano(), 10) + "_" +
+			strconv.FormatFloat(c.Score, 'g', -1, 64) + "_" + strconv.FormatUint(uint64(c.ID), 10)
+	}
+	return prefix + encodeCursor(c.CreatedAt, c.ID)
+}
+
+// decodeCommentCursor parses a cursor made for sort. The returned anchor is
+// zero unless sort is top.
+func decodeCommentCursor(sort, s string) (*repository.CommentCursor, time.Time, error) {
+	if s == "" {
+		return nil, time.Time{}, nil
+	}
+	parts := strings.SplitN(s, "_", 3)
+	if len(parts) != 3 || parts[0] != sort || (parts[1] != "0" && parts[1] != "1") {
+		return nil, time.Time{}, ErrInvalidCursor
+	}
+	boosted := parts[1] == "1"
+
+	if sort != repository.CommentSortTop {
+		cur, err := decodeCursor(parts[2])
+		if err != nil || cur == nil {
+			return nil, time.Time{}, ErrInvalidCursor
+		}
+		return &repository.CommentCursor{Boosted: boosted, Cursor: *cur}, time.Time{}, nil
+	}
+
+	keys := strings.Split(parts[2], "_")
+	if len(keys) != 3 {
+		return nil, time.Time{}, ErrInvalidCursor
+	}
+	nanos, err := strconv.ParseInt(keys[0], 10, 64)
+	if err != nil {
+		return nil, time.Time{}, ErrInvalidCursor
+	}
+	score, err := strconv.ParseFloat(keys[1], 64)
+	if err != nil {
+		return nil, time.Time{}, ErrInvalidCursor
+	}
+	id, err := strconv.ParseUint(keys[2], 10, 64)
+	if err != nil {
+		return nil, time.Time{}, ErrInvalidCursor
+	}
+	return &repository.CommentCursor{
+		Boosted: boosted,
+		Score:   score,
+		Cursor:  repository.Cursor{ID: uint(id)},
+	}, time.Unix(0, nanos), nil
+}
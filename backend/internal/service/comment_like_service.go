@@ -2,6 +2,7 @@ package service
 
 import (
 	"fmt"
+	"log"
 
 	"backend/internal/dto"
 	"backend/internal/repository"
@@ -10,23 +11,61 @@ import (
 type CommentLikeService interface {
 	Like(commentID, userID uint) (*dto.CommentLikeResponse, error)
 	Unlike(commentID, userID uint) (*dto.CommentLikeResponse, error)
+	React(commentID, userID uint, req dto.ReactionRequest) (*dto.ReactionResponse, error)
+	Unreact(commentID, userID uint) (*dto.ReactionResponse, error)
+	Likers(commentID, viewerID uint, limit int, cursor string) (*dto.LikersResponse, error)
 }
 
 type commentLikeService struct {
-	repo repository.CommentLikeRepository
+	repo        repository.CommentLikeRepository
+	commentRepo repository.CommentRepository
+	postRepo    repository.PostRepository
+	notifySvc   NotificationService
+	streamSvc   StreamService
 }
 
-func NewCommentLikeService(r repository.CommentLikeRepository) CommentLikeService {
-	return &commentLikeService{repo: r}
+func NewCommentLikeService(
+	r repository.CommentLikeRepository,
+	c repository.CommentRepository,
+	p repository.PostRepository,
+	n NotificationService,
+	st StreamService,
+) CommentLikeService {
+	return &commentLikeService{repo: r, commentRepo: c, postRepo: p, notifySvc: n, streamSvc: st}
+}
+
+// checkVisible: comments of a post the user can't see, and comments hidden
+// from the user, can't be liked either.
+func (s *commentLikeService) checkVisible(commentID, userID uint) error {
+	comment, err := s.commentRepo.GetByID(commentID)
+	if err != nil {
+		return fmt.Errorf("find comment: %w", err)
+	}
+	if comment.DeletedAt != nil {
+		return fmt.Errorf("find comment: %w", repository.ErrNotFound)
+	}
+	post, err := s.postRepo.FindByID(comment.PostID, userID)
+	if err != nil {
+		return fmt.Errorf("find post: %w", err)
+	}
+	if !commentVisible(comment, post, userID) {
+		return fmt.Errorf("find comment: %w", repository.ErrNotFound)
+	}
+	return nil
 }
 
 func (s *commentLikeService) Like(commentID uint, userID uint) (*dto.CommentLikeResponse, error) {
 	if commentID == 0 || userID == 0 {
 		return nil, fmt.Errorf("invalid ids")
 	}
+	if err := s.checkVisible(commentID, userID); err != nil {
+		return nil, err
+	}
 	if err := s.repo.LikeComment(commentID, userID); err != nil {
 		return nil, fmt.Errorf("like comment: %w", err)
 	}
+	s.notifySvc.NotifyCommentLike(commentID, userID)
+	s.publishLikes(commentID)
 	return &dto.CommentLikeResponse{CommentID: commentID, Liked: true}, nil
 }
 
@@ -34,8 +73,87 @@ func (s *commentLikeService) Unlike(commentID uint, userID uint) (*dto.CommentLi
 	if commentID == 0 || userID == 0 {
 		return nil, fmt.Errorf("invalid ids")
 	}
+	if err := s.checkVisible(commentID, userID); err != nil {
+		return nil, err
+	}
 	if err := s.repo.UnlikeComment(commentID, userID); err != nil {
 		return nil, fmt.Errorf("unlike comment: %w", err)
 	}
+	s.publishLikes(commentID)
 	return &dto.CommentLikeResponse{CommentID: commentID, Liked: false}, nil
 }
+
+func (s *commentLikeService) React(commentID, userID uint, req dto.ReactionRequest) (*dto.ReactionResponse, error) {
+	if commentID == 0 || userID == 0 {
+		return nil, fmt.Errorf("invalid ids")
+	}
+	reaction, err := parseReaction(req.Reaction)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkVisible(commentID, userID); err != nil {
+		return nil, err
+	}
+	created, err := s.repo.React(commentID, userID, reaction)
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		s.notifySvc.NotifyCommentLike(commentID, userID)
+	}
+	s.publishLikes(commentID)
+
+	counts, err := s.reactionCounts(commentID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.ReactionResponse{Reactions: counts, MyReaction: &reaction}, nil
+}
+
+func (s *commentLikeService) Unreact(commentID, userID uint) (*dto.ReactionResponse, error) {
+	if _, err := s.Unlike(commentID, userID); err != nil {
+		return nil, err
+	}
+	counts, err := s.reactionCounts(commentID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.ReactionResponse{Reactions: counts}, nil
+}
+
+func (s *commentLikeService) Likers(commentID, viewerID uint, limit int, cursor string) (*dto.LikersResponse, error) {
+	if commentID == 0 || viewerID == 0 {
+		return nil, fmt.Errorf("invalid ids")
+	}
+	if err := s.checkVisible(commentID, viewerID); err != nil {
+		return nil, err
+	}
+	return likersPage(limit, cursor, func(limit int, cur *repository.LikerCursor) ([]repository.Liker, error) {
+		return s.repo.Likers(commentID, viewerID, limit, cur)
+	})
+}
+
+func (s *commentLikeService) reactionCounts(commentID uint) (map[string]int, error) {
+	counts, err := s.repo.ReactionCounts([]uint{commentID})
+	if err != nil {
+		return nil, err
+	}
+	if counts[commentID] == nil {
+		return map[string]int{}, nil
+	}
+	return counts[commentID], nil
+}
+
+func (s *commentLikeService) publishLikes(commentID uint) {
+	comment, err := s.commentRepo.GetByID(commentID)
+	if err != nil {
+		log.Printf("publish comment likes: find comment %d: %v", commentID, err)
+		return
+	}
+	counts, err := s.reactionCounts(commentID)
+	if err != nil {
+		log.Printf("publish comment likes: count %d: %v", commentID, err)
+		return
+	}
+	s.streamSvc.PublishCommentLikes(comment.PostID, commentID, counts)
+}
@@ -1,169 +1,621 @@
 package service
 
 import (
+	"errors"
 	"fmt"
-	"sort"
+	"strings"
+	"time"
 
 	"backend/internal/dto"
+	"backend/internal/mapper"
 	"backend/internal/model"
 	"backend/internal/repository"
 )
 
+const (
+	// InlineReplies is how many replies come with each root comment.
+	InlineReplies = 3
+	// MaxCommentDepth is the deepest nesting level, top-level comments being
+	// level 1. Replies to a comment at this level become its siblings.
+	MaxCommentDepth  = 3
+	MaxCommentLength = 2000
+)
+
+var (
+	ErrCommentEmpty    = errors.New("text is required")
+	ErrCommentTooLong  = fmt.Errorf("text is too long, max %d characters", MaxCommentLength)
+	ErrParentNotFound  = errors.New("parent comment not found")
+	ErrParentOtherPost = errors.New("parent comment belongs to another post")
+	ErrParentDeleted   = errors.New("cannot reply to a deleted comment")
+)
+
 type CommentService interface {
-	AddComment(postID, userID uint, req dto.AddCommentRequest) error
-	GetCommentsTree(postID uint, userID uint, page, limit int) (*dto.CommentListResponse, error)
+	AddComment(postID, userID uint, req dto.AddCommentRequest) (*dto.CommentDTO, error)
+	ListThreads(postID, userID uint, sort string, boost bool, limit int, cursor string) (*dto.CommentListResponse, error)
+	ListReplies(commentID, userID uint, limit int, cursor string) (*dto.CommentListResponse, error)
+	EditComment(commentID, userID uint, req dto.EditCommentRequest) (*dto.CommentDTO, error)
+	GetRevisions(commentID, userID uint) (*dto.CommentRevisionsResponse, error)
+	DeleteComment(commentID, userID uint) error
+
+	PinComment(postID, userID, commentID uint) error
+	UnpinComment(postID, userID uint) error
+	SetCommentPolicy(postID, userID uint, req dto.CommentSettingsRequest) error
+	SetCommentHidden(commentID, userID uint, hidden bool) error
 }
 
 type commentService struct {
-	repo repository.CommentRepository
-	like repository.CommentLikeRepository
+	repo         repository.CommentRepository
+	like         repository.CommentLikeRepository
+	postRepo     repository.PostRepository
+	notifySvc    NotificationService
+	streamSvc    StreamService
+	analyticsSvc AnalyticsService
+	userRepo     repository.UserRepository
 }
 
-func NewCommentService(r repository.CommentRepository, l repository.CommentLikeRepository) CommentService {
-	return &commentService{repo: r, like: l}
+func NewCommentService(
+	r repository.CommentRepository,
+	l repository.CommentLikeRepository,
+	p repository.PostRepository,
+	n NotificationService,
+	st StreamService,
+	a AnalyticsService,
+	u repository.UserRepository,
+) CommentService {
+	return &commentService{repo: r, like: l, postRepo: p, notifySvc: n, streamSvc: st, analyticsSvc: a, userRepo: u}
 }
 
-func (s *commentService) AddComment(postID, userID uint, req dto.AddCommentRequest) error {
+// commentText trims the text and checks it against the length limit.
+func commentText(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", ErrCommentEmpty
+	}
+	if len([]rune(text)) > MaxCommentLength {
+		return "", ErrCommentTooLong
+	}
+	return text, nil
+}
+
+func (s *commentService) AddComment(postID, userID uint, req dto.AddCommentRequest) (*dto.CommentDTO, error) {
 	if postID == 0 || userID == 0 {
-		return fmt.Errorf("invalid ids")
+		return nil, fmt.Errorf("invalid ids")
+	}
+	text, err := commentText(req.Text)
+	if err != nil {
+		return nil, err
 	}
-	if req.Text == "" {
-		return fmt.Errorf("text is required")
+	post, err := s.postRepo.FindByID(postID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("find post: %w", err)
+	}
+	if err := s.canComment(post, userID); err != nil {
+		return nil, err
+	}
+	parentID, err := s.replyParent(post, userID, req.ParentID)
+	if err != nil {
+		return nil, err
 	}
 
 	comment := model.Comment{
 		PostID:   postID,
 		UserID:   userID,
-		ParentID: req.ParentID,
-		Text:     req.Text,
+		ParentID: parentID,
+		Text:     text,
+	}
+	if err := s.repo.Add(&comment); err != nil {
+		return nil, err
+	}
+	s.notifySvc.NotifyComment(&comment)
+	s.analyticsSvc.TrackComment(postID, userID)
+
+	created, err := s.repo.GetByID(comment.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.streamSvc.PublishComment(mapper.MapCommentToDTO(*created, false, 0))
+
+	items, err := s.mapComments([]model.Comment{*created}, post, userID)
+	if err != nil {
+		return nil, err
 	}
-	return s.repo.Add(&comment)
+	return &items[0], nil
 }
 
-func (s *commentService) GetCommentsTree(postID uint, userID uint, page, limit int) (*dto.CommentListResponse, error) {
-	if page < 1 {
-		page = 1
+// replyParent checks the comment being replied to and returns the parent
+// the reply is stored under: replies past MaxCommentDepth are attached to
+// the parent's own parent, so they stay on the deepest allowed level.
+func (s *commentService) replyParent(post *model.Post, userID uint, parentID *uint) (*uint, error) {
+	if parentID == nil {
+		return nil, nil
 	}
-	if limit <= 0 {
-		limit = 20
+	parent, err := s.repo.GetByID(*parentID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrParentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find parent: %w", err)
+	}
+	if parent.PostID != post.ID {
+		return nil, ErrParentOtherPost
+	}
+	if !commentVisible(parent, post, userID) {
+		return nil, ErrParentNotFound
+	}
+	if parent.DeletedAt != nil {
+		return nil, ErrParentDeleted
+	}
+
+	depth, err := s.repo.Depth(parent.ID)
+	if err != nil {
+		return nil, err
+	}
+	if depth >= MaxCommentDepth && parent.ParentID != nil {
+		return parent.ParentID, nil
+	}
+	return &parent.ID, nil
+}
+
+// canComment applies the post's comment policy. Turning comments off stops
+// the author too; the followers policy lets the author through.
+func (s *commentService) canComment(post *model.Post, userID uint) error {
+	switch post.CommentPolicy {
+	case model.CommentPolicyOff:
+		return ErrCommentsOff
+	case model.CommentPolicyFollowers:
+		if userID == post.UserID {
+			return nil
+		}
+		following, err := s.userRepo.IsFollowing(userID, post.UserID)
+		if err != nil {
+			return err
+		}
+		if !following {
+			return ErrCommentsFollowersOnly
+		}
+	}
+	return nil
+}
+
+// commentVisible reports whether the viewer may see a comment of post:
+// hidden comments show only to their writer and the post author.
+func commentVisible(c *model.Comment, post *model.Post, viewerID uint) bool {
+	return c.HiddenAt == nil || c.UserID == viewerID || post.UserID == viewerID
+}
+
+// threadInfo fills the fields of a comment listing that describe the post's
+// comment section for the viewer.
+func (s *commentService) threadInfo(resp *dto.CommentListResponse, post *model.Post, userID uint) error {
+	resp.CommentPolicy = post.CommentPolicy
+	if userID == 0 {
+		return nil
+	}
+	err := s.canComment(post, userID)
+	if err != nil && !errors.Is(err, ErrCommentsOff) && !errors.Is(err, ErrCommentsFollowersOnly) {
+		return err
 	}
+	resp.CanComment = err == nil
+	return nil
+}
+
+// ListThreads pages through a post's top-level comments in the given sort,
+// each with its first replies inline. With boost, comments by the viewer
+// and by the post author are listed before everyone else's. The pinned
+// comment opens the first page and is left out of the rest.
+func (s *commentService) ListThreads(postID, userID uint, sort string, boost bool, limit int, cursor string) (*dto.CommentListResponse, error) {
 	if postID == 0 {
 		return nil, fmt.Errorf("invalid post id")
 	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	sort, err := parseCommentSort(sort)
+	if err != nil {
+		return nil, err
+	}
+	cur, anchor, err := decodeCommentCursor(sort, cursor)
+	if err != nil {
+		return nil, err
+	}
+	post, err := s.postRepo.FindByID(postID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("find post: %w", err)
+	}
+
+	opts := repository.CommentListOptions{
+		Sort:     sort,
+		ViewerID: userID,
+		PinnedID: post.PinnedCommentID,
+		Now:      anchor,
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	if boost {
+		opts.BoostUserIDs = []uint{post.UserID}
+		if userID != 0 && userID != post.UserID {
+			opts.BoostUserIDs = append(opts.BoostUserIDs, userID)
+		}
+	}
 
-	allComments, err := s.repo.GetCommentsByPostID(postID)
+	roots, err := s.repo.GetRootComments(postID, opts, limit+1, cur)
 	if err != nil {
-		return nil, fmt.Errorf("load comments: %w", err)
+		return nil, err
+	}
+	hasMore := len(roots) > limit
+	if hasMore {
+		roots = roots[:limit]
 	}
 
-	if len(allComments) == 0 {
-		return &dto.CommentListResponse{
-			Comments: []dto.CommentDTO{},
-			Page:     page,
-			PageSize: limit,
-			Total:    0,
-		}, nil
+	comments := make([]model.Comment, 0, len(roots)+1)
+	rootIDs := make([]uint, 0, len(roots)+1)
+	if cur == nil && post.PinnedCommentID != nil {
+		pinned, err := s.repo.GetByID(*post.PinnedCommentID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+		if err == nil && commentVisible(pinned, post, userID) {
+			comments = append(comments, *pinned)
+			rootIDs = append(rootIDs, pinned.ID)
+		}
+	}
+	for _, c := range roots {
+		comments = append(comments, c.Comment)
+		rootIDs = append(rootIDs, c.ID)
+	}
+	replies, err := s.repo.FirstReplies(rootIDs, userID, InlineReplies)
+	if err != nil {
+		return nil, err
 	}
 
-	allIDs := make([]uint, 0, len(allComments))
-	for _, c := range allComments {
-		allIDs = append(allIDs, c.ID)
+	items, err := s.mapComments(append(comments, replies...), post, userID)
+	if err != nil {
+		return nil, err
+	}
+	threads, inline := items[:len(rootIDs)], items[len(rootIDs):]
+
+	byParent := make(map[uint][]dto.CommentDTO, len(rootIDs))
+	for _, r := range inline {
+		byParent[*r.ParentID] = append(byParent[*r.ParentID], r)
+	}
+	for i := range threads {
+		shown := byParent[threads[i].ID]
+		threads[i].Replies = shown
+		// счётчик читается позже первых ответов: если ответ появился между
+		// запросами, shown может быть пуст — тогда курсора нет, и клиент
+		// грузит ответы с начала
+		if threads[i].RepliesCount > len(shown) && len(shown) > 0 {
+			last := shown[len(shown)-1]
+			c := encodeCursor(last.CreatedAt, last.ID)
+			threads[i].RepliesCursor = &c
+		}
+	}
+
+	var nextCursor *string
+	if hasMore {
+		c := encodeCommentCursor(sort, opts.Now, roots[len(roots)-1])
+		nextCursor = &c
+	}
+
+	resp := &dto.CommentListResponse{
+		Comments:   threads,
+		NextCursor: nextCursor,
+		HasMore:    hasMore,
+	}
+	if err := s.threadInfo(resp, post, userID); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ListReplies pages through the direct replies of a comment, oldest first.
+// Deeper levels are loaded the same way from each reply.
+func (s *commentService) ListReplies(commentID, userID uint, limit int, cursor string) (*dto.CommentListResponse, error) {
+	if commentID == 0 {
+		return nil, fmt.Errorf("invalid comment id")
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	cur, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	parent, err := s.repo.GetByID(commentID)
+	if err != nil {
+		return nil, fmt.Errorf("find comment: %w", err)
 	}
-	likesMap, err := s.like.LikesCountsForComments(allIDs)
+	post, err := s.postRepo.FindByID(parent.PostID, userID)
 	if err != nil {
-		return nil, fmt.Errorf("likes counts: %w", err)
+		return nil, fmt.Errorf("find post: %w", err)
 	}
-	likedMap, err := s.like.LikedByUser(allIDs, userID)
+	if !commentVisible(parent, post, userID) {
+		return nil, fmt.Errorf("find comment: %w", repository.ErrNotFound)
+	}
+
+	replies, err := s.repo.GetReplies(parent.ID, userID, limit+1, cur)
+	if err != nil {
+		return nil, err
+	}
+	hasMore := len(replies) > limit
+	if hasMore {
+		replies = replies[:limit]
+	}
+
+	items, err := s.mapComments(replies, post, userID)
 	if err != nil {
-		return nil, fmt.Errorf("liked by user: %w", err)
+		return nil, err
+	}
+
+	var nextCursor *string
+	if hasMore {
+		last := replies[len(replies)-1]
+		c := encodeCursor(last.CreatedAt, last.ID)
+		nextCursor = &c
+	}
+
+	resp := &dto.CommentListResponse{
+		Comments:   items,
+		NextCursor: nextCursor,
+		HasMore:    hasMore,
 	}
+	if err := s.threadInfo(resp, post, userID); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
 
-	type node struct {
-		dto dto.CommentDTO
-		raw model.Comment
+// mapComments builds DTOs of comments of post in input order, loading
+// reactions and reply counts for the whole batch at once.
+func (s *commentService) mapComments(comments []model.Comment, post *model.Post, userID uint) ([]dto.CommentDTO, error) {
+	ids := make([]uint, 0, len(comments))
+	for _, c := range comments {
+		ids = append(ids, c.ID)
 	}
-	nodes := make(map[uint]*node, len(allComments))
-	var roots []uint
 
-	for _, c := range allComments {
-		lc := 0
-		if v, ok := likesMap[c.ID]; ok {
-			lc = v
+	reactionsMap, err := s.like.ReactionCounts(ids)
+	if err != nil {
+		return nil, fmt.Errorf("reaction counts: %w", err)
+	}
+	mineMap, err := s.like.ReactionsByUser(ids, userID)
+	if err != nil {
+		return nil, fmt.Errorf("reactions by user: %w", err)
+	}
+	repliesCounts, err := s.repo.RepliesCounts(ids, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dto.CommentDTO, 0, len(comments))
+	for _, c := range comments {
+		mapper.RedactDeletedComment(&c)
+		reactions := reactionsMap[c.ID]
+		if reactions == nil {
+			reactions = map[string]int{}
 		}
-		isLiked := false
-		if userID != 0 {
-			if v, ok := likedMap[c.ID]; ok {
-				isLiked = v
-			}
+		var myReaction *string
+		if r, ok := mineMap[c.ID]; ok {
+			myReaction = &r
 		}
 
-		d := dto.CommentDTO{
-			ID:       c.ID,
-			PostID:   c.PostID,
-			UserID:   c.UserID,
-			ParentID: c.ParentID,
-			Text:     c.Text,
-			Likes:    lc,
-			IsLiked:  isLiked,
+		res = append(res, dto.CommentDTO{
+			ID:         c.ID,
+			PostID:     c.PostID,
+			UserID:     c.UserID,
+			ParentID:   c.ParentID,
+			Text:       c.Text,
+			Likes:      mapper.ReactionsTotal(reactions),
+			IsLiked:    myReaction != nil,
+			Reactions:  reactions,
+			MyReaction: myReaction,
 			User: dto.UserShortDTO{
 				ID:       c.User.ID,
 				Nickname: c.User.Nickname,
 				Avatar:   c.User.AvatarURL,
 			},
-			Replies:   []dto.CommentDTO{},
-			CreatedAt: c.CreatedAt,
+			CreatedAt:    c.CreatedAt,
+			EditedAt:     c.EditedAt,
+			IsDeleted:    c.DeletedAt != nil,
+			IsPinned:     post.PinnedCommentID != nil && *post.PinnedCommentID == c.ID,
+			IsHidden:     c.HiddenAt != nil && post.UserID == userID,
+			RepliesCount: repliesCounts[c.ID],
+		})
+	}
+	return res, nil
+}
+
+// liveComment loads a comment that isn't deleted or hidden from the user and
+// whose post the user may see; anything else is ErrNotFound.
+func (s *commentService) liveComment(commentID, userID uint) (*model.Comment, *model.Post, error) {
+	if commentID == 0 || userID == 0 {
+		return nil, nil, fmt.Errorf("invalid ids")
+	}
+	comment, err := s.repo.GetByID(commentID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("find comment: %w", err)
+	}
+	if comment.DeletedAt != nil {
+		return nil, nil, fmt.Errorf("find comment: %w", repository.ErrNotFound)
+	}
+	post, err := s.postRepo.FindByID(comment.PostID, userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("find post: %w", err)
+	}
+	if !commentVisible(comment, post, userID) {
+		return nil, nil, fmt.Errorf("find comment: %w", repository.ErrNotFound)
+	}
+	return comment, post, nil
+}
+
+func (s *commentService) EditComment(commentID, userID uint, req dto.EditCommentRequest) (*dto.CommentDTO, error) {
+	comment, post, err := s.liveComment(commentID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.UserID != userID {
+		return nil, ErrForbidden
+	}
+	text, err := commentText(req.Text)
+	if err != nil {
+		return nil, err
+	}
+
+	if text != comment.Text {
+		if err := s.repo.UpdateText(comment.ID, text); err != nil {
+			return nil, err
 		}
-		nodes[c.ID] = &node{dto: d, raw: c}
-		if c.ParentID == nil {
-			roots = append(roots, c.ID)
+		if comment, err = s.repo.GetByID(comment.ID); err != nil {
+			return nil, err
 		}
 	}
 
-	sort.Slice(roots, func(i, j int) bool {
-		return nodes[roots[i]].raw.CreatedAt.After(nodes[roots[j]].raw.CreatedAt)
-	})
+	reactions, err := s.like.ReactionCounts([]uint{comment.ID})
+	if err != nil {
+		return nil, fmt.Errorf("reaction counts: %w", err)
+	}
+	mine, err := s.like.ReactionsByUser([]uint{comment.ID}, userID)
+	if err != nil {
+		return nil, fmt.Errorf("reactions by user: %w", err)
+	}
 
-	for _, n := range nodes {
-		if n.dto.ParentID != nil {
-			parent, ok := nodes[*n.dto.ParentID]
-			if ok {
-				if parent.dto.ParentID != nil && *parent.dto.ParentID == n.dto.ID {
-					continue
-				}
-				parent.dto.Replies = append(parent.dto.Replies, n.dto)
-			}
+	resp := mapper.MapCommentToDTO(*comment, false, mapper.ReactionsTotal(reactions[comment.ID]))
+	if reactions[comment.ID] != nil {
+		resp.Reactions = reactions[comment.ID]
+	}
+	if r, ok := mine[comment.ID]; ok {
+		resp.IsLiked = true
+		resp.MyReaction = &r
+	}
+
+	// a hidden comment is seen only by its writer and the post author, so
+	// its new text goes to them and not to everyone on the post
+	if comment.HiddenAt != nil {
+		to := []uint{comment.UserID}
+		if post.UserID != comment.UserID {
+			to = append(to, post.UserID)
 		}
+		s.streamSvc.PublishToUsers(to, EventCommentUpdated, resp)
+	} else {
+		s.streamSvc.PublishCommentUpdated(resp)
+	}
+	return &resp, nil
+}
+
+// GetRevisions is open to whoever can see the comment.
+func (s *commentService) GetRevisions(commentID, userID uint) (*dto.CommentRevisionsResponse, error) {
+	comment, _, err := s.liveComment(commentID, userID)
+	if err != nil {
+		return nil, err
+	}
+	revs, err := s.repo.ListRevisions(comment.ID)
+	if err != nil {
+		return nil, err
+	}
+	res := mapper.MapCommentRevisions(comment, revs)
+	return &res, nil
+}
+
+// DeleteComment is open to the comment's author, the post owner and
+// moderators.
+func (s *commentService) DeleteComment(commentID, userID uint) error {
+	comment, post, err := s.liveComment(commentID, userID)
+	if err != nil {
+		return err
 	}
 
-	for _, n := range nodes {
-		if len(n.dto.Replies) > 1 {
-			sort.Slice(n.dto.Replies, func(i, j int) bool {
-				return n.dto.Replies[i].CreatedAt.Before(n.dto.Replies[j].CreatedAt)
-			})
+	if comment.UserID != userID && post.UserID != userID {
+		user, err := s.userRepo.GetByID(userID)
+		if err != nil {
+			return err
+		}
+		if !user.IsModerator {
+			return ErrForbidden
 		}
 	}
 
-	totalRoots := len(roots)
-	start := (page - 1) * limit
-	if start > totalRoots {
-		start = totalRoots
+	placeholder, err := s.repo.Delete(comment.ID)
+	if err != nil {
+		return err
 	}
-	end := start + limit
-	if end > totalRoots {
-		end = totalRoots
+	s.streamSvc.PublishCommentDeleted(post.ID, comment.ID, placeholder)
+	return nil
+}
+
+// ownPost loads a post for changes only its author may make.
+func (s *commentService) ownPost(postID, userID uint) (*model.Post, error) {
+	if postID == 0 || userID == 0 {
+		return nil, fmt.Errorf("invalid ids")
 	}
-	pagedRoots := roots[start:end]
+	post, err := s.postRepo.FindByID(postID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("find post: %w", err)
+	}
+	if post.UserID != userID {
+		return nil, ErrForbidden
+	}
+	return post, nil
+}
 
-	res := make([]dto.CommentDTO, 0, len(pagedRoots))
-	for _, id := range pagedRoots {
-		res = append(res, nodes[id].dto)
+// PinComment puts a top-level comment of the post on top of its thread,
+// replacing the previously pinned one.
+func (s *commentService) PinComment(postID, userID, commentID uint) error {
+	post, err := s.ownPost(postID, userID)
+	if err != nil {
+		return err
 	}
+	comment, _, err := s.liveComment(commentID, userID)
+	if err != nil {
+		return err
+	}
+	if comment.PostID != post.ID {
+		return fmt.Errorf("find comment: %w", repository.ErrNotFound)
+	}
+	if comment.ParentID != nil {
+		return fmt.Errorf("only top-level comments can be pinned")
+	}
+	if comment.HiddenAt != nil {
+		return fmt.Errorf("hidden comments can't be pinned")
+	}
+	return s.postRepo.UpdateFields(post.ID, map[string]interface{}{"pinned_comment_id": comment.ID})
+}
 
-	resp := &dto.CommentListResponse{
-		Comments: res,
-		Page:     page,
-		PageSize: limit,
-		Total:    int64(totalRoots),
+func (s *commentService) UnpinComment(postID, userID uint) error {
+	post, err := s.ownPost(postID, userID)
+	if err != nil {
+		return err
 	}
-	return resp, nil
+	return s.postRepo.UpdateFields(post.ID, map[string]interface{}{"pinned_comment_id": nil})
+}
+
+func (s *commentService) SetCommentPolicy(postID, userID uint, req dto.CommentSettingsRequest) error {
+	post, err := s.ownPost(postID, userID)
+	if err != nil {
+		return err
+	}
+	switch req.CommentPolicy {
+	case model.CommentPolicyEveryone, model.CommentPolicyFollowers, model.CommentPolicyOff:
+	default:
+		return ErrInvalidCommentPolicy
+	}
+	return s.postRepo.UpdateFields(post.ID, map[string]interface{}{"comment_policy": req.CommentPolicy})
+}
+
+// SetCommentHidden lets the post author hide a comment from everyone but its
+// writer, or show it again. Hiding the pinned comment unpins it.
+func (s *commentService) SetCommentHidden(commentID, userID uint, hidden bool) error {
+	comment, post, err := s.liveComment(commentID, userID)
+	if err != nil {
+		return err
+	}
+	if post.UserID != userID {
+		return ErrForbidden
+	}
+	if err := s.repo.SetHidden(comment.ID, hidden); err != nil {
+		return err
+	}
+	if hidden && post.PinnedCommentID != nil && *post.PinnedCommentID == comment.ID {
+		return s.postRepo.UpdateFields(post.ID, map[string]interface{}{"pinned_comment_id": nil})
+	}
+	return nil
 }
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/repository"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// encodeCursor renders a keyset position as "<unix nanos>_<id>" so that rows
+// sharing the same second are not skipped between pages.
+func encodeCursor(t time.Time, id uint) string {
+	return fmt.Sprintf("%d_%d", t.UnixNano(), id)
+}
+
+func decodeCursor(s string) (*repository.Cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	parts := strings.SplitN(s, "_", 2)
+	if len(parts) != 2 {
+		return nil, ErrInvalidCursor
+	}
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	id, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &repository.Cursor{Time: time.Unix(0, nanos), ID: uint(id)}, nil
+}
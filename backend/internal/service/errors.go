@@ -0,0 +1,52 @@
+package service
+
+import "errors"
+
+// ErrForbidden means the caller is authenticated but may not do this.
+var ErrForbidden = errors.New("forbidden")
+
+// ErrPostPublished is returned when a draft-only action hits a published post.
+var ErrPostPublished = errors.New("post is already published")
+
+// ErrInvalidSchedule means publish_at is missing, malformed or not in the future.
+var ErrInvalidSchedule = errors.New("publish_at must be an RFC3339 time in the future")
+
+// ErrInvalidPostStatus is returned for an unknown status on create.
+var ErrInvalidPostStatus = errors.New("status must be draft, scheduled or published")
+
+// ErrInvalidVisibility is returned for an unknown post audience.
+var ErrInvalidVisibility = errors.New("visibility must be public, followers, close_friends or only_me")
+
+// ErrNotShareable: only public published posts can be reposted or quoted.
+var ErrNotShareable = errors.New("only public posts can be reposted or quoted")
+
+// ErrDMNotAllowed is returned when a block or the recipient's DM settings
+// forbid starting a conversation or sending a message.
+var ErrDMNotAllowed = errors.New("this user does not accept messages from you")
+
+// ErrInvalidStoryMedia: stories are a single image or mp4 video.
+var ErrInvalidStoryMedia = errors.New("story must be a jpg, png, gif or mp4 file")
+
+// ErrInvalidReaction is returned for a reaction outside the fixed set.
+var ErrInvalidReaction = errors.New("reaction must be like, love, haha, wow, sad or angry")
+
+// ErrInvalidCommentSort is returned for an unknown comments sort parameter.
+var ErrInvalidCommentSort = errors.New("sort must be top, new or old")
+
+// ErrCommentsOff: the post author turned comments off.
+var ErrCommentsOff = errors.New("comments are turned off for this post")
+
+// ErrCommentsFollowersOnly: the post author limited comments to followers.
+var ErrCommentsFollowersOnly = errors.New("only followers of the author can comment on this post")
+
+// ErrInvalidCommentPolicy is returned for an unknown comment_policy.
+var ErrInvalidCommentPolicy = errors.New("comment_policy must be everyone, followers or off")
+
+// ErrInvalidExploreTab is returned for an unknown explore tab.
+var ErrInvalidExploreTab = errors.New("tab must be photos, videos or text")
+
+// ErrInvalidFeedMode is returned for an unknown feed mode.
+var ErrInvalidFeedMode = errors.New("mode must be for_you, following, latest or campus")
+
+// ErrInvalidFeedFilter is returned for an unknown feed filter.
+var ErrInvalidFeedFilter = errors.New("filter must be media or text")
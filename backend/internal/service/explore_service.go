@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/dto"
+	"backend/internal/model"
+	"backend/internal/repository"
+)
+
+const (
+	// ExploreWindow is how far back trending posts are looked for.
+	ExploreWindow = 48 * time.Hour
+	// ExploreRefreshInterval is how often the trending set is recomputed.
+	ExploreRefreshInterval = 5 * time.Minute
+	// ExploreSetSize caps the trending set of each category.
+	ExploreSetSize = 1000
+)
+
+// Explore tabs and the post category each one shows.
+var exploreTabs = map[string]string{
+	"photos": repository.ExploreCategoryPhoto,
+	"videos": repository.ExploreCategoryVideo,
+	"text":   repository.ExploreCategoryText,
+}
+
+// ExploreService serves the explore page from a trending set that Run
+// recomputes in the background, so requests never scan engagement tables.
+// A refresh between two pages can reorder the set; the cursor then just
+// continues from its score.
+type ExploreService interface {
+	Run(ctx context.Context)
+	Get(userID uint, tab string, limit int, cursor string) (*dto.ExploreResponse, error)
+}
+
+type exploreService struct {
+	repo  repository.ExploreRepository
+	views *PostViewBuilder
+}
+
+func NewExploreService(repo repository.ExploreRepository, views *PostViewBuilder) ExploreService {
+	return &exploreService{repo: repo, views: views}
+}
+
+func (s *exploreService) Run(ctx context.Context) {
+	ticker := time.NewTicker(ExploreRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now()
+		if err := s.repo.RefreshTrending(now.Add(-ExploreWindow), now, ExploreSetSize); err != nil {
+			log.Printf("explore: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *exploreService) Get(userID uint, tab string, limit int, cursor string) (*dto.ExploreResponse, error) {
+	if userID == 0 {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	category, ok := exploreTabs[tab]
+	if tab != "" && !ok {
+		return nil, ErrInvalidExploreTab
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	cur, err := decodeExploreCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	trending, err := s.repo.Trending(userID, category, limit+1, cur)
+	if err != nil {
+		return nil, err
+	}
+	hasMore := len(trending) > limit
+	if hasMore {
+		trending = trending[:limit]
+	}
+
+	posts := make([]model.Post, 0, len(trending))
+	for _, t := range trending {
+		posts = append(posts, t.Post)
+	}
+	result, err := s.views.Build(posts, userID)
+	if err != nil {
+		return nil, fmt.Errorf("build explore posts: %w", err)
+	}
+
+	var nextCursor *string
+	if hasMore {
+		last := trending[len(trending)-1]
+		c := encodeExploreCursor(last.Score, last.ID)
+		nextCursor = &c
+	}
+
+	return &dto.ExploreResponse{
+		Tab:        tab,
+		Posts:      result,
+		NextCursor: nextCursor,
+		HasMore:    hasMore,
+	}, nil
+}
+
+// encodeExploreCursor renders a position as "<score>_<id>"; the shortest
+// float formatting still parses back to the exact score.
+func encodeExploreCursor(score float64, id uint) string {
+	return strconv.FormatFloat(score, 'g', -1, 64) + "_" + strconv.FormatUint(uint64(id), 10)
+}
+
+func decodeExploreCursor(s string) (*repository.ExploreCursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	score, id, ok := strings.Cut(s, "_")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	v, err := strconv.ParseFloat(score, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	n, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &repository.ExploreCursor{Score: v, ID: uint(n)}, nil
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"backend/internal/repository"
+)
+
+// feedCursor is the feed position handed to clients: the last following
+// item as (activity time, post id) and the read mark of the previous visit,
+// which tells later pages where the new posts end.
+type feedCursor struct {
+	Time  int64 `json:"t"`
+	ID    uint  `json:"id"`
+	Since int64 `json:"s,omitempty"`
+}
+
+// encodeFeedCursor renders the cursor as "<payload>.<mac>", both base64url.
+// The HMAC keeps clients from crafting positions.
+func encodeFeedCursor(key []byte, c feedCursor) string {
+	payload, _ := json.Marshal(c)
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(payload) + "." + enc.EncodeToString(feedCursorMAC(key, payload))
+}
+
+func decodeFeedCursor(key []byte, s string) (*feedCursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	enc := base64.RawURLEncoding
+	p, m, ok := strings.Cut(s, ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	payload, err := enc.DecodeString(p)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	mac, err := enc.DecodeString(m)
+	if err != nil || !hmac.Equal(mac, feedCursorMAC(key, payload)) {
+		return nil, ErrInvalidCursor
+	}
+
+	var c feedCursor
+	if err := json.Unmarshal(payload, &c); err != nil || c.ID == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+func feedCursorMAC(key, payload []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(payload)
+	return h.Sum(nil)[:16]
+}
+
+func (c *feedCursor) position() *repository.Cursor {
+	return &repository.Cursor{Time: time.Unix(0, c.Time), ID: c.ID}
+}
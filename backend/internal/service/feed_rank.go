@@ -0,0 +1,126 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"backend/internal/repository"
+)
+
+// Feed scorers selectable with FEED_SCORER.
+const (
+	FeedScorerWeighted = "weighted"
+	FeedScorerRecency  = "recency"
+)
+
+// RankedPost is a feed candidate on its way through scoring. Reason is
+// empty for posts from the following feed.
+type RankedPost struct {
+	repository.PostSignals
+	Reason string
+	Detail string
+	Score  float64
+}
+
+// FeedScorer orders the candidates of one feed page, best first.
+type FeedScorer interface {
+	Rank(posts []RankedPost, now time.Time) []RankedPost
+}
+
+// NewFeedScorer picks a scorer by name; unknown names get the weighted one.
+func NewFeedScorer(name string) FeedScorer {
+	switch name {
+	case FeedScorerRecency:
+		return recencyScorer{}
+	default:
+		return defaultWeightedScorer
+	}
+}
+
+// weightedScorer adds up recency, engagement velocity and the viewer's
+// affinity with the author, each squashed into [0, 1). Diversity then
+// demotes every further post of an author already placed above.
+type weightedScorer struct {
+	Recency   float64
+	Velocity  float64
+	Affinity  float64
+	Following float64
+	// Diversity multiplies the score once per post of the same author
+	// ranked higher.
+	Diversity float64
+	// HalfLife is the age at which the recency part halves.
+	HalfLife time.Duration
+}
+
+var defaultWeightedScorer = weightedScorer{
+	Recency:   1.0,
+	Velocity:  0.8,
+	Affinity:  0.6,
+	Following: 0.3,
+	Diversity: 0.7,
+	HalfLife:  12 * time.Hour,
+}
+
+func (w weightedScorer) Rank(posts []RankedPost, now time.Time) []RankedPost {
+	for i := range posts {
+		p := &posts[i]
+		age := now.Sub(p.CreatedAt)
+		if age < 0 {
+			age = 0
+		}
+		recency := math.Exp2(-float64(age) / float64(w.HalfLife))
+
+		// interactions per hour over the velocity window, at least an hour
+		hours := math.Min(math.Max(age.Hours(), 1), feedVelocityWindow.Hours())
+		velocity := squash(float64(p.RecentEngagement)/hours, 2)
+
+		p.Score = w.Recency*recency + w.Velocity*velocity + w.Affinity*squash(float64(p.Affinity), 5)
+		if p.Reason == "" {
+			p.Score += w.Following
+		}
+	}
+	sort.SliceStable(posts, func(i, j int) bool { return posts[i].Score > posts[j].Score })
+
+	// greedy re-rank: each pick lowers the effective score of the author's
+	// remaining posts
+	res := make([]RankedPost, 0, len(posts))
+	placed := make(map[uint]int)
+	used := make([]bool, len(posts))
+	for range posts {
+		best, bestScore := -1, math.Inf(-1)
+		for i, p := range posts {
+			if used[i] {
+				continue
+			}
+			s := p.Score * math.Pow(w.Diversity, float64(placed[p.UserID]))
+			if s > bestScore {
+				best, bestScore = i, s
+			}
+		}
+		used[best] = true
+		picked := posts[best]
+		picked.Score = bestScore
+		res = append(res, picked)
+		placed[picked.UserID]++
+	}
+	return res
+}
+
+// squash maps [0, inf) onto [0, 1), reaching one half at k.
+func squash(x, k float64) float64 {
+	return x / (x + k)
+}
+
+// recencyScorer is the chronological baseline.
+type recencyScorer struct{}
+
+func (recencyScorer) Rank(posts []RankedPost, _ time.Time) []RankedPost {
+	sort.SliceStable(posts, func(i, j int) bool {
+		if !posts[i].CreatedAt.Equal(posts[j].CreatedAt) {
+			return posts[i].CreatedAt.After(posts[j].CreatedAt)
+		}
+		return posts[i].PostID > posts[j].PostID
+	})
+	return posts
+}
@@ -5,89 +5,357 @@ import (
  "backend/internal/mapper"
  "backend/internal/model"
  "backend/internal/repository"
+ "backend/internal/seen"
+ "context"
  "fmt"
+ "log"
  "time"
 )
 
 type FeedService interface {
- GetFeed(userID uint, limit int, cursor *time.Time) (*dto.FeedResponse, error)
+ GetFeed(userID uint, mode, filter string, limit int, cursor string) (*dto.FeedResponse, error)
 }
 
 type feedService struct {
- repo repository.FeedRepository
+ repo      repository.FeedRepository
+ timelines TimelineService
+ seen      seen.Store
+ views     *PostViewBuilder
+ scorer    FeedScorer
+ cursorKey []byte
 }
 
-func NewFeedService(r repository.FeedRepository) FeedService {
- return &feedService{repo: r}
+// cursorKey signs feed cursors; any server secret will do.
+func NewFeedService(r repository.FeedRepository, timelines TimelineService, seen seen.Store, views *PostViewBuilder, scorer FeedScorer, cursorKey []byte) FeedService {
+ return &feedService{repo: r, timelines: timelines, seen: seen, views: views, scorer: scorer, cursorKey: cursorKey}
 }
 
+// MixRatio is how many following posts a page holds per recommended one.
 const MixRatio = 4
 
-func (s *feedService) GetFeed(userID uint, limit int, cursor *time.Time) (*dto.FeedResponse, error) {
+const (
+ // feedCandidateWindow bounds the age of recommended posts.
+ feedCandidateWindow = 7 * 24 * time.Hour
+ feedVelocityWindow  = 24 * time.Hour
+ feedAffinityWindow  = 30 * 24 * time.Hour
+ // each generator proposes this many times the recommendations a page
+ // needs, so the scorer has something to choose from
+ feedCandidateFactor = 3
+)
+
+const (
+ // SeenPostsTTL is how long a served recommendation is not proposed again;
+ // older posts aren't candidates anyway.
+ SeenPostsTTL = feedCandidateWindow
+ // SeenPostsMax caps the served posts remembered per user.
+ SeenPostsMax = 1000
+)
+
+type candidateGenerator func(userID uint, since time.Time, limit int, excludeIDs []uint) ([]repository.FeedCandidate, error)
+
+// generators are asked in order; a post proposed twice keeps the first reason.
+func (s *feedService) generators() []candidateGenerator {
+ return []candidateGenerator{
+  s.repo.EngagedByFollowees,
+  s.repo.SameCohort,
+  s.repo.Trending,
+ }
+}
+
+// Feed modes: for_you mixes the home timeline with recommendations and
+// ranks them, following is the home timeline alone, newest first, latest is
+// every post the viewer may see, newest first, and campus the same for
+// authors sharing the viewer's major or grade.
+const (
+ FeedModeForYou    = "for_you"
+ FeedModeFollowing = "following"
+ FeedModeLatest    = "latest"
+ FeedModeCampus    = "campus"
+)
+
+// GetFeed returns a page of the feed in the given mode; filter keeps only
+// posts with files ("media") or without ("text"). In the timeline modes
+// CaughtUp marks the page where the following posts newer than the previous
+// visit run out.
+func (s *feedService) GetFeed(userID uint, mode, filter string, limit int, cursor string) (*dto.FeedResponse, error) {
  if userID == 0 {
   return nil, fmt.Errorf("unauthorized")
  }
+ if mode == "" {
+  mode = FeedModeForYou
+ }
+ switch mode {
+ case FeedModeForYou, FeedModeFollowing, FeedModeLatest, FeedModeCampus:
+ default:
+  return nil, ErrInvalidFeedMode
+ }
+ switch filter {
+ case repository.FeedMediaAny, repository.FeedMediaOnly, repository.FeedTextOnly:
+ default:
+  return nil, ErrInvalidFeedFilter
+ }
+ if limit <= 0 || limit > 100 {
+  limit = 20
+ }
+ cur, err := decodeFeedCursor(s.cursorKey, cursor)
+ if err != nil {
+  return nil, err
+ }
 
- following, err := s.repo.GetFollowingPosts(userID, limit, cursor)
+ q := repository.FeedQuery{ViewerID: userID, Media: filter}
+ switch mode {
+ case FeedModeLatest:
+  return s.latest(q, limit, cur)
+ case FeedModeCampus:
+  q.Scope = repository.FeedScopeCampus
+  return s.latest(q, limit, cur)
+ }
+ return s.home(q, mode == FeedModeForYou, limit, cur)
+}
+
+// home pages the home timeline. With rank set it is the "For You" feed:
+// recommended posts from the candidate generators are added and everything
+// is ordered by the configured scorer. Recommendations already served are
+// not proposed again.
+func (s *feedService) home(q repository.FeedQuery, rank bool, limit int, cur *feedCursor) (*dto.FeedResponse, error) {
+ userID := q.ViewerID
+ var position *repository.Cursor
+ if cur != nil {
+  position = cur.position()
+ }
+
+ following, next, err := s.timelines.Page(userID, q.Media, limit, position)
  if err != nil {
   return nil, fmt.Errorf("get following posts: %w", err)
  }
 
- // ✅ исключаем посты, которые уже пришли в following (иначе будут дубли)
- excludeIDs := make([]uint, 0, len(following))
- for _, p := range following {
-  excludeIDs = append(excludeIDs, p.ID)
+ ctx := context.Background()
+ since := s.since(ctx, userID, q.Media, cur, following)
+
+ byID := make(map[uint]repository.FeedItem, len(following))
+ for _, item := range following {
+  byID[item.Post.ID] = item
  }
 
- var recCount int
- if limit > 0 {
-  recCount = limit / MixRatio
-  if recCount < 1 {
-   recCount = 1
+ var page []RankedPost
+ var recIDs []uint
+ if rank {
+  if page, recIDs, err = s.rank(ctx, userID, following, limit); err != nil {
+   return nil, err
   }
  } else {
-  // When no limit, use a reasonable default for recommended posts
-  recCount = 0 // No limit on recommended posts either
+  for _, item := range following {
+   page = append(page, RankedPost{PostSignals: repository.PostSignals{PostID: item.Post.ID}})
+  }
+ }
+
+ recPosts, err := s.repo.GetPosts(q, recIDs)
+ if err != nil {
+  return nil, fmt.Errorf("get recommended posts: %w", err)
+ }
+ served := make([]uint, 0, len(recPosts))
+ for _, p := range recPosts {
+  byID[p.ID] = repository.FeedItem{Post: p}
+  served = append(served, p.ID)
+ }
+ if err := s.seen.Add(ctx, userID, served); err != nil {
+  log.Printf("feed seen posts of %d: %v", userID, err)
+ }
+
+ posts := make([]model.Post, 0, len(page))
+ items := make([]RankedPost, 0, len(page))
+ for _, p := range page {
+  item, ok := byID[p.PostID]
+  if !ok {
+   continue
+  }
+  posts = append(posts, item.Post)
+  items = append(items, p)
+ }
+
+ result, err := s.views.Build(posts, userID)
+ if err != nil {
+  return nil, fmt.Errorf("build feed posts: %w", err)
+ }
+ for i, p := range items {
+  if item := byID[p.PostID]; item.RepostedBy != nil {
+   author := mapper.MapPostAuthor(*item.RepostedBy)
+   result[i].RepostedBy = &author
+  }
+  if p.Reason != "" {
+   result[i].Reason = &dto.FeedReasonDTO{Type: p.Reason, Detail: p.Detail}
+  }
+ }
+
+ var nextCursor *string
+ if next != nil {
+  c := encodeFeedCursor(s.cursorKey, feedCursor{
+   Time:  next.Time.UnixNano(),
+   ID:    next.ID,
+   Since: unixNanoOrZero(since),
+  })
+  nextCursor = &c
+ }
+
+ return &dto.FeedResponse{
+  Posts:      result,
+  NextCursor: nextCursor,
+  HasMore:    next != nil,
+  CaughtUp:   caughtUp(since, position, next),
+ }, nil
+}
+
+// rank mixes recommendations into the following items and orders them. All
+// following items stay on the page, otherwise the cursor would skip them;
+// recommendations are capped to one per MixRatio items.
+func (s *feedService) rank(ctx context.Context, userID uint, following []repository.FeedItem, limit int) ([]RankedPost, []uint, error) {
+ now := time.Now()
+ recCount := limit / MixRatio
+ if recCount < 1 {
+  recCount = 1
  }
 
- recommended, err := s.repo.GetRecommendedPosts(userID, recCount, excludeIDs)
+ servedRecs, err := s.seen.List(ctx, userID)
  if err != nil {
-  recommended = []model.Post{}
+  log.Printf("feed seen posts of %d: %v", userID, err)
  }
 
- followingDTO := mapper.MapPostsToDTO(following, userID)
- recommendedDTO := mapper.MapPostsToDTO(recommended, userID)
+ // исключаем посты, которые уже пришли в following (иначе будут дубли)
+ excludeIDs := make([]uint, 0, len(following))
+ for _, item := range following {
+  excludeIDs = append(excludeIDs, item.Post.ID)
+ }
 
- result := []dto.PostResponse{}
- recIndex := 0
+ ids := append([]uint{}, excludeIDs...)
+ candidates := s.recommend(userID, now, recCount*feedCandidateFactor, append(excludeIDs, servedRecs...))
+ reasons := make(map[uint]repository.FeedCandidate, len(candidates))
+ for _, c := range candidates {
+  reasons[c.PostID] = c
+  ids = append(ids, c.PostID)
+ }
 
- for i, p := range followingDTO {
-  result = append(result, p)
+ signals, err := s.repo.Signals(ids, userID, now.Add(-feedVelocityWindow), now.Add(-feedAffinityWindow))
+ if err != nil {
+  return nil, nil, fmt.Errorf("feed signals: %w", err)
+ }
+ pool := make([]RankedPost, 0, len(signals))
+ for _, sig := range signals {
+  c := reasons[sig.PostID]
+  pool = append(pool, RankedPost{PostSignals: sig, Reason: c.Reason, Detail: c.Detail})
+ }
 
-  if (i+1)%MixRatio == 0 && recIndex < len(recommendedDTO) {
-   result = append(result, recommendedDTO[recIndex])
-   recIndex++
+ var page []RankedPost
+ var recIDs []uint
+ for _, p := range s.scorer.Rank(pool, now) {
+  if p.Reason != "" {
+   if len(recIDs) == recCount {
+    continue
+   }
+   recIDs = append(recIDs, p.PostID)
   }
+  page = append(page, p)
  }
+ return page, recIDs, nil
+}
 
- for recIndex < len(recommendedDTO) {
-  result = append(result, recommendedDTO[recIndex])
-  recIndex++
+// latest pages the posts that pass q, newest first.
+func (s *feedService) latest(q repository.FeedQuery, limit int, cur *feedCursor) (*dto.FeedResponse, error) {
+ var position *repository.Cursor
+ if cur != nil {
+  position = cur.position()
+ }
+ posts, err := s.repo.LatestPosts(q, limit+1, position)
+ if err != nil {
+  return nil, err
+ }
+ hasMore := len(posts) > limit
+ if hasMore {
+  posts = posts[:limit]
  }
 
- var nextCursor *string
- if len(following) > 0 {
-  t := following[len(following)-1].CreatedAt.UTC().Format(time.RFC3339)
-  nextCursor = &t
+ result, err := s.views.Build(posts, q.ViewerID)
+ if err != nil {
+  return nil, fmt.Errorf("build feed posts: %w", err)
  }
 
- hasMore := false
- if limit > 0 {
-  hasMore = len(following) == limit
+ var nextCursor *string
+ if hasMore {
+  last := posts[len(posts)-1]
+  c := encodeFeedCursor(s.cursorKey, feedCursor{Time: last.CreatedAt.UnixNano(), ID: last.ID})
+  nextCursor = &c
  }
+
  return &dto.FeedResponse{
   Posts:      result,
   NextCursor: nextCursor,
   HasMore:    hasMore,
  }, nil
-}
\ No newline at end of file
+}
+
+// since is the read mark of the previous visit. The first page moves the
+// mark to its newest following item and keeps the old one; later pages
+// carry it in the cursor. A media filtered page leaves the mark alone and
+// has none: its newest item says nothing about what else was read.
+func (s *feedService) since(ctx context.Context, userID uint, media string, cur *feedCursor, following []repository.FeedItem) time.Time {
+ if media != repository.FeedMediaAny {
+  return time.Time{}
+ }
+ if cur != nil {
+  if cur.Since == 0 {
+   return time.Time{}
+  }
+  return time.Unix(0, cur.Since)
+ }
+ var newest time.Time
+ if len(following) > 0 {
+  newest = following[0].ActivityAt
+ }
+ prev, err := s.seen.Visit(ctx, userID, newest)
+ if err != nil {
+  log.Printf("feed visit of %d: %v", userID, err)
+ }
+ return prev
+}
+
+// caughtUp reports whether this page holds the last following item newer
+// than since: the page started above the mark and ends at or below it, or
+// the feed ends. With no mark only the end of the feed counts.
+func caughtUp(since time.Time, position, next *repository.Cursor) bool {
+ startedNew := position == nil || position.Time.After(since)
+ reachedOld := next == nil || (!since.IsZero() && !next.Time.After(since))
+ return startedNew && reachedOld
+}
+
+func unixNanoOrZero(t time.Time) int64 {
+ if t.IsZero() {
+  return 0
+ }
+ return t.UnixNano()
+}
+
+// recommend collects candidates from all generators. A failing generator is
+// logged and skipped: the feed still works without its recommendations.
+func (s *feedService) recommend(userID uint, now time.Time, perGenerator int, excludeIDs []uint) []repository.FeedCandidate {
+ since := now.Add(-feedCandidateWindow)
+ seen := make(map[uint]bool, len(excludeIDs))
+ for _, id := range excludeIDs {
+  seen[id] = true
+ }
+
+ var res []repository.FeedCandidate
+ for _, gen := range s.generators() {
+  cands, err := gen(userID, since, perGenerator, excludeIDs)
+  if err != nil {
+   log.Printf("feed candidates for %d: %v", userID, err)
+   continue
+  }
+  for _, c := range cands {
+   if seen[c.PostID] {
+    continue
+   }
+   seen[c.PostID] = true
+   res = append(res, c)
+  }
+ }
+ return res
+}
@@ -32,7 +32,12 @@ func NewFileService(uploadDir, baseURL string, maxFileSize int64, allowedExts []
 }
 
 func (fs *FileService) SaveFiles(postID uint, files []*multipart.FileHeader) ([]string, error) {
-	uploadDir := filepath.Join(fs.UploadDir, "posts", fmt.Sprint(postID))
+	return fs.SaveFilesTo(filepath.Join("posts", fmt.Sprint(postID)), files)
+}
+
+// SaveFilesTo stores uploads under UploadDir/subdir and returns their public URLs.
+func (fs *FileService) SaveFilesTo(subdir string, files []*multipart.FileHeader) ([]string, error) {
+	uploadDir := filepath.Join(fs.UploadDir, subdir)
 	if err := os.MkdirAll(uploadDir, 0755); err != nil {
 		return nil, fmt.Errorf("create upload dir: %w", err)
 	}
@@ -72,9 +77,32 @@ func (fs *FileService) SaveFiles(postID uint, files []*multipart.FileHeader) ([]
 			return nil, fmt.Errorf("copy file content: %w", err)
 		}
 
-		relURL := filepath.ToSlash(filepath.Join(fs.BaseURL, "posts", fmt.Sprint(postID), newName))
+		relURL := filepath.ToSlash(filepath.Join(fs.BaseURL, subdir, newName))
 		urls = append(urls, relURL)
 	}
 
 	return urls, nil
 }
+
+// Path maps a URL returned by SaveFilesTo to the file on disk. URLs outside
+// BaseURL are rejected.
+func (fs *FileService) Path(url string) (string, error) {
+	rel := strings.TrimPrefix(url, filepath.ToSlash(filepath.Clean(fs.BaseURL))+"/")
+	if rel == url || !filepath.IsLocal(filepath.FromSlash(rel)) {
+		return "", fmt.Errorf("file %s is not an upload", url)
+	}
+	return filepath.Join(fs.UploadDir, filepath.FromSlash(rel)), nil
+}
+
+// Remove deletes a file previously returned by SaveFilesTo. URLs outside
+// BaseURL are rejected and a missing file is not an error.
+func (fs *FileService) Remove(url string) error {
+	path, err := fs.Path(url)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove file: %w", err)
+	}
+	return nil
+}
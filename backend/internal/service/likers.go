@@ -0,0 +1,78 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"backend/internal/dto"
+	"backend/internal/repository"
+)
+
+// Liker lists are ordered followees first, so their cursor also carries the
+// group: "<1|0>_<unix nanos>_<like id>".
+func encodeLikerCursor(l repository.Liker) string {
+	group := "0"
+	if l.Followed {
+		group = "1"
+	}
+	return group + "_" + encodeCursor(l.CreatedAt, l.LikeID)
+}
+
+func decodeLikerCursor(s string) (*repository.LikerCursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	group, rest, ok := strings.Cut(s, "_")
+	if !ok || (group != "0" && group != "1") {
+		return nil, ErrInvalidCursor
+	}
+	cur, err := decodeCursor(rest)
+	if err != nil || cur == nil {
+		return nil, ErrInvalidCursor
+	}
+	return &repository.LikerCursor{Followed: group == "1", Cursor: *cur}, nil
+}
+
+// likersPage loads one page of likers through load, which gets limit+1 so
+// that has_more is exact.
+func likersPage(limit int, cursor string, load func(limit int, cur *repository.LikerCursor) ([]repository.Liker, error)) (*dto.LikersResponse, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	cur, err := decodeLikerCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := load(limit+1, cur)
+	if err != nil {
+		return nil, fmt.Errorf("load likers: %w", err)
+	}
+
+	hasMore := len(rows) > limit
+	if hasMore {
+		rows = rows[:limit]
+	}
+
+	likers := make([]dto.LikerDTO, 0, len(rows))
+	for _, l := range rows {
+		likers = append(likers, dto.LikerDTO{
+			User:        dto.UserShortDTO{ID: l.UserID, Nickname: l.Nickname, Avatar: l.AvatarURL},
+			Reaction:    l.Reaction,
+			IsFollowing: l.Followed,
+			LikedAt:     l.CreatedAt,
+		})
+	}
+
+	var nextCursor *string
+	if hasMore {
+		c := encodeLikerCursor(rows[len(rows)-1])
+		nextCursor = &c
+	}
+
+	return &dto.LikersResponse{
+		Likers:     likers,
+		NextCursor: nextCursor,
+		HasMore:    hasMore,
+	}, nil
+}
@@ -0,0 +1,486 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"backend/internal/dto"
+	"backend/internal/mapper"
+	"backend/internal/model"
+	"backend/internal/repository"
+)
+
+const (
+	MaxGroupMembers  = 10
+	MaxMessageLength = 4000
+)
+
+type MessageService interface {
+	CreateConversation(userID uint, req dto.CreateConversationRequest) (*dto.ConversationDTO, error)
+	ListConversations(userID uint, limit int, cursor string) (*dto.ConversationListResponse, error)
+	GetConversation(conversationID, userID uint) (*dto.ConversationDTO, error)
+
+	SendMessage(conversationID, userID uint, req dto.SendMessageRequest, files []*multipart.FileHeader) (*dto.MessageDTO, error)
+	ReplyToStory(userID, authorID, storyID uint, text string) (*dto.MessageDTO, error)
+	ListMessages(conversationID, userID uint, limit int, cursor string) (*dto.MessageListResponse, error)
+	MarkRead(conversationID, userID, messageID uint) error
+	DeleteMessage(conversationID, messageID, userID uint, forEveryone bool) error
+	FilePath(conversationID, userID uint, name string) (string, error)
+}
+
+// messageService keeps attachments in its own FileService, outside the
+// public uploads directory: they are served only to conversation members
+// through FilePath.
+type messageService struct {
+	repo      repository.MessageRepository
+	userRepo  repository.UserRepository
+	fileSvc   *FileService
+	streamSvc StreamService
+}
+
+func NewMessageService(
+	repo repository.MessageRepository,
+	userRepo repository.UserRepository,
+	fileSvc *FileService,
+	streamSvc StreamService,
+) MessageService {
+	return &messageService{
+		repo:      repo,
+		userRepo:  userRepo,
+		fileSvc:   fileSvc,
+		streamSvc: streamSvc,
+	}
+}
+
+// canMessage checks blocks in both directions and the recipient's
+// "only people I follow" setting.
+func (s *messageService) canMessage(senderID, recipientID uint) error {
+	recipient, err := s.userRepo.GetByID(recipientID)
+	if err != nil {
+		return err
+	}
+
+	blocked, err := s.userRepo.IsBlockedEither(senderID, recipientID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return ErrDMNotAllowed
+	}
+
+	if recipient.DMFollowingOnly {
+		follows, err := s.userRepo.IsFollowing(recipientID, senderID)
+		if err != nil {
+			return err
+		}
+		if !follows {
+			return ErrDMNotAllowed
+		}
+	}
+	return nil
+}
+
+func directKey(a, b uint) string {
+	if a > b {
+		a, b = b, a
+	}
+	return fmt.Sprintf("%d:%d", a, b)
+}
+
+func (s *messageService) CreateConversation(userID uint, req dto.CreateConversationRequest) (*dto.ConversationDTO, error) {
+	if userID == 0 {
+		return nil, fmt.Errorf("unauthorized")
+	}
+
+	seen := map[uint]bool{userID: true}
+	var others []uint
+	for _, id := range req.UserIDs {
+		if id == 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		others = append(others, id)
+	}
+	if len(others) == 0 {
+		return nil, fmt.Errorf("user_ids is required")
+	}
+	if len(others)+1 > MaxGroupMembers {
+		return nil, fmt.Errorf("a conversation can have at most %d members", MaxGroupMembers)
+	}
+
+	isGroup := len(others) > 1 || req.Title != nil
+
+	var key *string
+	if !isGroup {
+		k := directKey(userID, others[0])
+		if existing, err := s.repo.FindDirect(k); err == nil {
+			resp := mapper.MapConversationToDTO(*existing, nil, 0)
+			return &resp, nil
+		} else if !errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+		key = &k
+	}
+
+	for _, id := range others {
+		if err := s.canMessage(userID, id); err != nil {
+			return nil, err
+		}
+	}
+
+	var title *string
+	if req.Title != nil {
+		t := strings.TrimSpace(*req.Title)
+		if t != "" {
+			title = &t
+		}
+	}
+
+	conv := model.Conversation{
+		IsGroup:   isGroup,
+		Title:     title,
+		CreatedBy: &userID,
+		DirectKey: key,
+	}
+	if err := s.repo.CreateConversation(&conv, append([]uint{userID}, others...)); err != nil {
+		if !errors.Is(err, repository.ErrDirectExists) {
+			return nil, err
+		}
+		// the same pair created it concurrently; hand back theirs
+		existing, err := s.repo.FindDirect(*key)
+		if err != nil {
+			return nil, err
+		}
+		resp := mapper.MapConversationToDTO(*existing, nil, 0)
+		return &resp, nil
+	}
+
+	return s.GetConversation(conv.ID, userID)
+}
+
+func (s *messageService) ListConversations(userID uint, limit int, cursor string) (*dto.ConversationListResponse, error) {
+	if userID == 0 {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	cur, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	convs, err := s.repo.ListConversations(userID, limit+1, cur)
+	if err != nil {
+		return nil, err
+	}
+
+	hasMore := len(convs) > limit
+	if hasMore {
+		convs = convs[:limit]
+	}
+
+	ids := make([]uint, 0, len(convs))
+	for _, c := range convs {
+		ids = append(ids, c.ID)
+	}
+	unread, err := s.repo.UnreadCounts(userID, ids)
+	if err != nil {
+		return nil, err
+	}
+	last, err := s.repo.LastMessages(userID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]dto.ConversationDTO, 0, len(convs))
+	for _, c := range convs {
+		var lm *model.Message
+		if m, ok := last[c.ID]; ok {
+			lm = &m
+		}
+		items = append(items, mapper.MapConversationToDTO(c, lm, unread[c.ID]))
+	}
+
+	var nextCursor *string
+	if hasMore {
+		lastConv := convs[len(convs)-1]
+		c := encodeCursor(lastConv.LastMessageAt, lastConv.ID)
+		nextCursor = &c
+	}
+
+	return &dto.ConversationListResponse{
+		Conversations: items,
+		NextCursor:    nextCursor,
+		HasMore:       hasMore,
+	}, nil
+}
+
+func (s *messageService) GetConversation(conversationID, userID uint) (*dto.ConversationDTO, error) {
+	conv, err := s.memberConversation(conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	unread, err := s.repo.UnreadCounts(userID, []uint{conv.ID})
+	if err != nil {
+		return nil, err
+	}
+	last, err := s.repo.LastMessages(userID, []uint{conv.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	var lm *model.Message
+	if m, ok := last[conv.ID]; ok {
+		lm = &m
+	}
+	resp := mapper.MapConversationToDTO(*conv, lm, unread[conv.ID])
+	return &resp, nil
+}
+
+// memberConversation loads the conversation and hides it from non-members.
+func (s *messageService) memberConversation(conversationID, userID uint) (*model.Conversation, error) {
+	if conversationID == 0 || userID == 0 {
+		return nil, fmt.Errorf("invalid ids")
+	}
+	conv, err := s.repo.GetConversation(conversationID)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range conv.Members {
+		if m.UserID == userID {
+			return conv, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func memberIDs(conv *model.Conversation) []uint {
+	ids := make([]uint, 0, len(conv.Members))
+	for _, m := range conv.Members {
+		ids = append(ids, m.UserID)
+	}
+	return ids
+}
+
+func (s *messageService) SendMessage(conversationID, userID uint, req dto.SendMessageRequest, files []*multipart.FileHeader) (*dto.MessageDTO, error) {
+	conv, err := s.memberConversation(conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.send(conv, userID, req.Text, nil, files)
+}
+
+// ReplyToStory sends text to the story author's direct conversation,
+// starting one if needed.
+func (s *messageService) ReplyToStory(userID, authorID, storyID uint, text string) (*dto.MessageDTO, error) {
+	direct, err := s.CreateConversation(userID, dto.CreateConversationRequest{UserIDs: []uint{authorID}})
+	if err != nil {
+		return nil, err
+	}
+	conv, err := s.memberConversation(direct.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.send(conv, userID, text, &storyID, nil)
+}
+
+func (s *messageService) send(conv *model.Conversation, userID uint, text string, storyID *uint, files []*multipart.FileHeader) (*dto.MessageDTO, error) {
+	text = strings.TrimSpace(text)
+	if text == "" && len(files) == 0 {
+		return nil, fmt.Errorf("message is empty")
+	}
+	if len([]rune(text)) > MaxMessageLength {
+		return nil, fmt.Errorf("message is too long, max %d characters", MaxMessageLength)
+	}
+
+	// в личке блок или настройки собеседника действуют и на уже начатый диалог
+	if !conv.IsGroup {
+		for _, m := range conv.Members {
+			if m.UserID == userID {
+				continue
+			}
+			if err := s.canMessage(userID, m.UserID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	msg := model.Message{
+		ConversationID: conv.ID,
+		SenderID:       userID,
+		Text:           text,
+		StoryID:        storyID,
+	}
+
+	if len(files) > 0 {
+		urls, err := s.fileSvc.SaveFilesTo(messageFilesDir(conv.ID), files)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range urls {
+			msg.Files = append(msg.Files, model.MessageFile{URL: u})
+		}
+	}
+
+	if err := s.repo.CreateMessage(&msg); err != nil {
+		s.removeFiles(msg.Files)
+		return nil, err
+	}
+
+	created, err := s.repo.GetMessage(msg.ID)
+	if err != nil {
+		return nil, err
+	}
+	resp := mapper.MapMessageToDTO(*created)
+
+	s.streamSvc.PublishToUsers(memberIDs(conv), EventMessage, resp)
+	return &resp, nil
+}
+
+func (s *messageService) ListMessages(conversationID, userID uint, limit int, cursor string) (*dto.MessageListResponse, error) {
+	if _, err := s.memberConversation(conversationID, userID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 30
+	}
+
+	var beforeID uint
+	if cursor != "" {
+		v, err := strconv.ParseUint(cursor, 10, 64)
+		if err != nil || v == 0 {
+			return nil, ErrInvalidCursor
+		}
+		beforeID = uint(v)
+	}
+
+	msgs, err := s.repo.ListMessages(conversationID, userID, limit+1, beforeID)
+	if err != nil {
+		return nil, err
+	}
+
+	hasMore := len(msgs) > limit
+	if hasMore {
+		msgs = msgs[:limit]
+	}
+
+	var nextCursor *string
+	if hasMore {
+		c := strconv.FormatUint(uint64(msgs[len(msgs)-1].ID), 10)
+		nextCursor = &c
+	}
+
+	return &dto.MessageListResponse{
+		Messages:   mapper.MapMessagesToDTO(msgs),
+		NextCursor: nextCursor,
+		HasMore:    hasMore,
+	}, nil
+}
+
+func (s *messageService) MarkRead(conversationID, userID, messageID uint) error {
+	conv, err := s.memberConversation(conversationID, userID)
+	if err != nil {
+		return err
+	}
+
+	if messageID == 0 {
+		messageID, err = s.repo.LatestMessageID(conv.ID)
+		if err != nil {
+			return err
+		}
+		if messageID == 0 {
+			return nil
+		}
+	} else {
+		msg, err := s.repo.GetMessage(messageID)
+		if err != nil {
+			return err
+		}
+		if msg.ConversationID != conv.ID {
+			return repository.ErrNotFound
+		}
+	}
+
+	if err := s.repo.MarkRead(conv.ID, userID, messageID); err != nil {
+		return err
+	}
+
+	s.streamSvc.PublishToUsers(memberIDs(conv), EventMessageRead, dto.MessageReadEvent{
+		ConversationID: conv.ID,
+		UserID:         userID,
+		MessageID:      messageID,
+	})
+	return nil
+}
+
+func (s *messageService) DeleteMessage(conversationID, messageID, userID uint, forEveryone bool) error {
+	conv, err := s.memberConversation(conversationID, userID)
+	if err != nil {
+		return err
+	}
+
+	msg, err := s.repo.GetMessage(messageID)
+	if err != nil {
+		return err
+	}
+	if msg.ConversationID != conv.ID {
+		return repository.ErrNotFound
+	}
+
+	if !forEveryone {
+		return s.repo.DeleteForMe(msg.ID, userID)
+	}
+
+	if msg.SenderID != userID {
+		return ErrForbidden
+	}
+	if err := s.repo.DeleteForEveryone(msg.ID); err != nil {
+		return err
+	}
+	s.removeFiles(msg.Files)
+
+	s.streamSvc.PublishToUsers(memberIDs(conv), EventMessageDeleted, dto.MessageDeletedEvent{
+		ConversationID: conv.ID,
+		MessageID:      msg.ID,
+	})
+	return nil
+}
+
+func messageFilesDir(conversationID uint) string {
+	return filepath.Join(fmt.Sprint(conversationID), "files")
+}
+
+// removeFiles deletes attachments whose rows are gone or were never
+// written; failures are only logged.
+func (s *messageService) removeFiles(files []model.MessageFile) {
+	for _, f := range files {
+		if err := s.fileSvc.Remove(f.URL); err != nil {
+			log.Printf("remove message file %s: %v", f.URL, err)
+		}
+	}
+}
+
+// FilePath returns where an attachment of the conversation is stored, for
+// members only. Files of messages deleted for everyone are already gone.
+func (s *messageService) FilePath(conversationID, userID uint, name string) (string, error) {
+	if _, err := s.memberConversation(conversationID, userID); err != nil {
+		return "", err
+	}
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return "", repository.ErrNotFound
+	}
+	url := filepath.ToSlash(filepath.Join(s.fileSvc.BaseURL, messageFilesDir(conversationID), name))
+	path, err := s.fileSvc.Path(url)
+	if err != nil {
+		return "", repository.ErrNotFound
+	}
+	return path, nil
+}
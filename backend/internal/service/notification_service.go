@@ -0,0 +1,190 @@
+package service
+
+import (
+	"fmt"
+	"log"
+
+	"backend/internal/dto"
+	"backend/internal/mapper"
+	"backend/internal/model"
+	"backend/internal/repository"
+)
+
+type NotificationService interface {
+	NotifyPostLike(postID, actorID uint)
+	NotifyComment(comment *model.Comment)
+	NotifyCommentLike(commentID, actorID uint)
+	NotifyFollow(targetID, actorID uint)
+	NotifyRepost(postID, actorID uint)
+
+	List(userID uint, limit int, cursor string) (*dto.NotificationListResponse, error)
+	UnreadCount(userID uint) (int64, error)
+	MarkRead(userID, id uint) error
+	MarkAllRead(userID uint) error
+}
+
+type notificationService struct {
+	repo        repository.NotificationRepository
+	postRepo    repository.PostRepository
+	commentRepo repository.CommentRepository
+	streamSvc   StreamService
+}
+
+func NewNotificationService(
+	repo repository.NotificationRepository,
+	postRepo repository.PostRepository,
+	commentRepo repository.CommentRepository,
+	streamSvc StreamService,
+) NotificationService {
+	return &notificationService{
+		repo:        repo,
+		postRepo:    postRepo,
+		commentRepo: commentRepo,
+		streamSvc:   streamSvc,
+	}
+}
+
+// Notify* methods are called from the like/comment/follow paths. A failed
+// notification must never fail the action itself, so errors are only logged.
+
+func (s *notificationService) NotifyPostLike(postID, actorID uint) {
+	post, err := s.postRepo.FindByID(postID, actorID)
+	if err != nil {
+		log.Printf("notify post like: find post %d: %v", postID, err)
+		return
+	}
+	s.push(post.UserID, actorID, model.NotificationPostLike, &post.ID, nil)
+}
+
+func (s *notificationService) NotifyComment(comment *model.Comment) {
+	if comment == nil {
+		return
+	}
+
+	postID := comment.PostID
+
+	var parentAuthor uint
+	if comment.ParentID != nil {
+		parent, err := s.commentRepo.GetByID(*comment.ParentID)
+		if err != nil {
+			log.Printf("notify comment: find parent %d: %v", *comment.ParentID, err)
+		} else {
+			parentAuthor = parent.UserID
+			parentID := parent.ID
+			s.push(parent.UserID, comment.UserID, model.NotificationReply, &postID, &parentID)
+		}
+	}
+
+	post, err := s.postRepo.FindByID(postID, comment.UserID)
+	if err != nil {
+		log.Printf("notify comment: find post %d: %v", postID, err)
+		return
+	}
+	// автор родительского коммента уже получил "reply", второй раз не шумим
+	if post.UserID == parentAuthor {
+		return
+	}
+	s.push(post.UserID, comment.UserID, model.NotificationComment, &postID, nil)
+}
+
+func (s *notificationService) NotifyCommentLike(commentID, actorID uint) {
+	comment, err := s.commentRepo.GetByID(commentID)
+	if err != nil {
+		log.Printf("notify comment like: find comment %d: %v", commentID, err)
+		return
+	}
+	postID := comment.PostID
+	s.push(comment.UserID, actorID, model.NotificationCommentLike, &postID, &comment.ID)
+}
+
+func (s *notificationService) NotifyFollow(targetID, actorID uint) {
+	s.push(targetID, actorID, model.NotificationFollow, nil, nil)
+}
+
+func (s *notificationService) NotifyRepost(postID, actorID uint) {
+	post, err := s.postRepo.FindByID(postID, actorID)
+	if err != nil {
+		log.Printf("notify repost: find post %d: %v", postID, err)
+		return
+	}
+	s.push(post.UserID, actorID, model.NotificationRepost, &post.ID, nil)
+}
+
+func (s *notificationService) push(userID, actorID uint, typ string, postID, commentID *uint) {
+	if userID == 0 || actorID == 0 || userID == actorID {
+		return
+	}
+	n, err := s.repo.Push(userID, actorID, typ, postID, commentID)
+	if err != nil {
+		log.Printf("push %s notification to user %d: %v", typ, userID, err)
+		return
+	}
+	if n != nil {
+		s.streamSvc.PublishNotification(userID, mapper.MapNotificationToDTO(*n))
+	}
+}
+
+func (s *notificationService) List(userID uint, limit int, cursor string) (*dto.NotificationListResponse, error) {
+	if userID == 0 {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	cur, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	// берём на один больше, чтобы честно посчитать has_more
+	items, err := s.repo.List(userID, limit+1, cur)
+	if err != nil {
+		return nil, fmt.Errorf("list notifications: %w", err)
+	}
+
+	hasMore := len(items) > limit
+	if hasMore {
+		items = items[:limit]
+	}
+
+	unread, err := s.repo.UnreadCount(userID)
+	if err != nil {
+		return nil, fmt.Errorf("unread count: %w", err)
+	}
+
+	var nextCursor *string
+	if hasMore {
+		last := items[len(items)-1]
+		c := encodeCursor(last.UpdatedAt, last.ID)
+		nextCursor = &c
+	}
+
+	return &dto.NotificationListResponse{
+		Notifications: mapper.MapNotificationsToDTO(items),
+		UnreadCount:   unread,
+		NextCursor:    nextCursor,
+		HasMore:       hasMore,
+	}, nil
+}
+
+func (s *notificationService) UnreadCount(userID uint) (int64, error) {
+	if userID == 0 {
+		return 0, fmt.Errorf("unauthorized")
+	}
+	return s.repo.UnreadCount(userID)
+}
+
+func (s *notificationService) MarkRead(userID, id uint) error {
+	if userID == 0 || id == 0 {
+		return fmt.Errorf("invalid ids")
+	}
+	return s.repo.MarkRead(userID, id)
+}
+
+func (s *notificationService) MarkAllRead(userID uint) error {
+	if userID == 0 {
+		return fmt.Errorf("unauthorized")
+	}
+	return s.repo.MarkAllRead(userID)
+}
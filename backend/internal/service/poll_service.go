@@ -0,0 +1,206 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"backend/internal/dto"
+	"backend/internal/mapper"
+	"backend/internal/model"
+	"backend/internal/repository"
+)
+
+const (
+	MaxPollOptions      = 10
+	minPollOptions      = 2
+	maxPollOptionLength = 100
+)
+
+var (
+	ErrInvalidPoll = fmt.Errorf("poll needs %d-%d distinct options of up to %d characters and a future ends_at",
+		minPollOptions, MaxPollOptions, maxPollOptionLength)
+	ErrInvalidVote   = errors.New("pick one option of this poll, or several if it is multiple choice")
+	ErrPollClosed    = errors.New("poll is closed")
+	ErrPollAnonymous = errors.New("poll results are anonymous")
+)
+
+type PollService interface {
+	Vote(postID, userID uint, req dto.PollVoteRequest) (*dto.PollDTO, error)
+	Retract(postID, userID uint) (*dto.PollDTO, error)
+	Voters(postID, optionID, viewerID uint, limit int, cursor string) (*dto.PollVotersResponse, error)
+}
+
+type pollService struct {
+	repo     repository.PollRepository
+	postRepo repository.PostRepository
+}
+
+func NewPollService(repo repository.PollRepository, postRepo repository.PostRepository) PollService {
+	return &pollService{repo: repo, postRepo: postRepo}
+}
+
+// buildPoll validates a poll sent with a new post; PostID is filled in by
+// the caller once the post exists.
+func buildPoll(req *dto.CreatePollRequest) (*model.Poll, error) {
+	if req == nil {
+		return nil, nil
+	}
+	if len(req.Options) < minPollOptions || len(req.Options) > MaxPollOptions {
+		return nil, ErrInvalidPoll
+	}
+
+	poll := &model.Poll{Multiple: req.Multiple, Anonymous: req.Anonymous}
+
+	seen := make(map[string]bool, len(req.Options))
+	for i, text := range req.Options {
+		text = strings.TrimSpace(text)
+		if text == "" || len([]rune(text)) > maxPollOptionLength || seen[text] {
+			return nil, ErrInvalidPoll
+		}
+		seen[text] = true
+		poll.Options = append(poll.Options, model.PollOption{Position: i, Text: text})
+	}
+
+	if req.EndsAt != nil {
+		t, err := time.Parse(time.RFC3339, *req.EndsAt)
+		if err != nil || !t.After(time.Now()) {
+			return nil, ErrInvalidPoll
+		}
+		poll.EndsAt = &t
+	}
+	return poll, nil
+}
+
+func pollClosed(poll *model.Poll) bool {
+	return poll.EndsAt != nil && !time.Now().Before(*poll.EndsAt)
+}
+
+// pollOf loads the poll of a post the user can see.
+func (s *pollService) pollOf(postID, userID uint) (*model.Poll, error) {
+	if postID == 0 || userID == 0 {
+		return nil, fmt.Errorf("invalid ids")
+	}
+	if _, err := s.postRepo.FindByID(postID, userID); err != nil {
+		return nil, fmt.Errorf("find post: %w", err)
+	}
+	return s.repo.GetByPostID(postID)
+}
+
+func (s *pollService) Vote(postID, userID uint, req dto.PollVoteRequest) (*dto.PollDTO, error) {
+	poll, err := s.pollOf(postID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if pollClosed(poll) {
+		return nil, ErrPollClosed
+	}
+
+	valid := make(map[uint]bool, len(poll.Options))
+	for _, o := range poll.Options {
+		valid[o.ID] = true
+	}
+	picked := make(map[uint]bool, len(req.OptionIDs))
+	for _, id := range req.OptionIDs {
+		if !valid[id] || picked[id] {
+			return nil, ErrInvalidVote
+		}
+		picked[id] = true
+	}
+	if len(picked) == 0 || (!poll.Multiple && len(picked) > 1) {
+		return nil, ErrInvalidVote
+	}
+
+	if err := s.repo.Vote(poll.ID, userID, req.OptionIDs); err != nil {
+		return nil, err
+	}
+	return s.results(poll, userID)
+}
+
+func (s *pollService) Retract(postID, userID uint) (*dto.PollDTO, error) {
+	poll, err := s.pollOf(postID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if pollClosed(poll) {
+		return nil, ErrPollClosed
+	}
+	if err := s.repo.Retract(poll.ID, userID); err != nil {
+		return nil, err
+	}
+	return s.results(poll, userID)
+}
+
+func (s *pollService) results(poll *model.Poll, userID uint) (*dto.PollDTO, error) {
+	ids := []uint{poll.ID}
+	votes, err := s.repo.OptionCounts(ids)
+	if err != nil {
+		return nil, err
+	}
+	voters, err := s.repo.VoterCounts(ids)
+	if err != nil {
+		return nil, err
+	}
+	choices, err := s.repo.Choices(ids, userID)
+	if err != nil {
+		return nil, err
+	}
+	return mapper.MapPoll(*poll, votes, voters[poll.ID], choices[poll.ID]), nil
+}
+
+func (s *pollService) Voters(postID, optionID, viewerID uint, limit int, cursor string) (*dto.PollVotersResponse, error) {
+	poll, err := s.pollOf(postID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if poll.Anonymous {
+		return nil, ErrPollAnonymous
+	}
+	found := false
+	for _, o := range poll.Options {
+		if o.ID == optionID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, repository.ErrNotFound
+	}
+
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	cur, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	votes, err := s.repo.Voters(optionID, limit+1, cur)
+	if err != nil {
+		return nil, err
+	}
+
+	hasMore := len(votes) > limit
+	if hasMore {
+		votes = votes[:limit]
+	}
+
+	users := make([]model.User, 0, len(votes))
+	for _, v := range votes {
+		users = append(users, v.User)
+	}
+
+	var nextCursor *string
+	if hasMore {
+		last := votes[len(votes)-1]
+		c := encodeCursor(last.UpdatedAt, last.ID)
+		nextCursor = &c
+	}
+
+	return &dto.PollVotersResponse{
+		Voters:     mapper.MapUsersToShortDTO(users),
+		NextCursor: nextCursor,
+		HasMore:    hasMore,
+	}, nil
+}
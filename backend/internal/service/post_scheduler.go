@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"backend/internal/repository"
+)
+
+const (
+	// SchedulerInterval is how often due scheduled posts are looked for.
+	SchedulerInterval = 15 * time.Second
+	schedulerBatch    = 100
+)
+
+// PostScheduler publishes scheduled posts when their time comes. State lives
+// in Postgres, so posts that came due while no replica was running go out on
+// the next start.
+type PostScheduler interface {
+	Run(ctx context.Context)
+}
+
+type postScheduler struct {
+	repo      repository.PostRepository
+	streamSvc StreamService
+	timelines TimelineService
+}
+
+func NewPostScheduler(repo repository.PostRepository, streamSvc StreamService, timelines TimelineService) PostScheduler {
+	return &postScheduler{repo: repo, streamSvc: streamSvc, timelines: timelines}
+}
+
+func (s *postScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(SchedulerInterval)
+	defer ticker.Stop()
+
+	for {
+		s.publishDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *postScheduler) publishDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		posts, err := s.repo.PublishDue(schedulerBatch)
+		if err != nil {
+			log.Printf("scheduler: %v", err)
+			return
+		}
+		for i := range posts {
+			p := &posts[i]
+			s.streamSvc.PublishFeedItem(p.UserID, p.ID, p.Visibility)
+			s.timelines.PostPublished(p)
+		}
+		if len(posts) < schedulerBatch {
+			return
+		}
+	}
+}
@@ -1,8 +1,11 @@
 package service
 
 import (
+	"encoding/json"
 	"fmt"
+	"log"
 	"mime/multipart"
+	"time"
 
 	"backend/internal/dto"
 	"backend/internal/mapper"
@@ -17,16 +20,32 @@ type PostService interface {
 	AddFiles(postID uint, urls []string) error
 	LikePost(postID, userID uint) error
 	UnlikePost(postID, userID uint) error
+	React(postID, userID uint, req dto.ReactionRequest) (*dto.ReactionResponse, error)
+	Unreact(postID, userID uint) (*dto.ReactionResponse, error)
+	GetLikers(postID, viewerID uint, limit int, cursor string) (*dto.LikersResponse, error)
 	GetPost(postID, userID uint) (*dto.PostWithCommentsResponse, error)
 	CreatePostWithFiles(userID uint, req dto.CreatePostRequestMultipart, files []*multipart.FileHeader) (uint, error)
 	GetUserPosts(targetUserID, viewerID uint) ([]dto.PostResponse, error)
+	GetRevisions(postID, viewerID uint) (*dto.PostRevisionsResponse, error)
+
+	GetDrafts(userID uint) ([]dto.PostResponse, error)
+	Schedule(postID, userID uint, req dto.SchedulePostRequest) error
+	Unschedule(postID, userID uint) error
+	Publish(postID, userID uint) error
+	Repost(postID, userID uint) (*dto.RepostResponse, error)
+	Unrepost(postID, userID uint) (*dto.RepostResponse, error)
 }
 
 type postService struct {
-	repo        repository.PostRepository
-	commentSvc  CommentService
-	commentTree CommentTreeService
-	fileSvc     *FileService
+	repo         repository.PostRepository
+	commentSvc   CommentService
+	fileSvc      *FileService
+	notifySvc    NotificationService
+	streamSvc    StreamService
+	views        *PostViewBuilder
+	viewSvc      ViewService
+	analyticsSvc AnalyticsService
+	timelines    TimelineService
 }
 
 func (s *postService) CreatePostWithFiles(userID uint, req dto.CreatePostRequestMultipart, files []*multipart.FileHeader) (uint, error) {
@@ -34,18 +53,46 @@ func (s *postService) CreatePostWithFiles(userID uint, req dto.CreatePostRequest
 		return 0, fmt.Errorf("unauthorized")
 	}
 
+	if err := s.checkQuoted(req.QuotedPostID, userID); err != nil {
+		return 0, err
+	}
+	status, publishAt, err := parsePostStatus(req.Status, req.PublishAt)
+	if err != nil {
+		return 0, err
+	}
+	visibility, err := parseVisibility(req.Visibility)
+	if err != nil {
+		return 0, err
+	}
+	var pollReq *dto.CreatePollRequest
+	if req.Poll != "" {
+		pollReq = &dto.CreatePollRequest{}
+		if err := json.Unmarshal([]byte(req.Poll), pollReq); err != nil {
+			return 0, ErrInvalidPoll
+		}
+	}
+	poll, err := buildPoll(pollReq)
+	if err != nil {
+		return 0, err
+	}
+
 	post := model.Post{
-		UserID:      userID,
-		Description: req.Description,
+		UserID:       userID,
+		Description:  req.Description,
+		QuotedPostID: req.QuotedPostID,
+		Status:       status,
+		PublishAt:    publishAt,
+		Visibility:   visibility,
 	}
 
 	// создаём сам пост
-	if err := s.repo.CreatePost(&post); err != nil {
+	if err := s.repo.CreatePost(&post, poll); err != nil {
 		return 0, err
 	}
 
 	// если файлов нет — return
 	if len(files) == 0 {
+		s.published(&post)
 		return post.ID, nil
 	}
 
@@ -67,20 +114,89 @@ func (s *postService) CreatePostWithFiles(userID uint, req dto.CreatePostRequest
 		return 0, err
 	}
 
+	s.published(&post)
 	return post.ID, nil
 }
 
+// parsePostStatus validates the status a post is created with.
+func parsePostStatus(status string, publishAt *string) (string, *time.Time, error) {
+	switch status {
+	case "", model.PostStatusPublished:
+		return model.PostStatusPublished, nil, nil
+	case model.PostStatusDraft:
+		return model.PostStatusDraft, nil, nil
+	case model.PostStatusScheduled:
+		if publishAt == nil {
+			return "", nil, ErrInvalidSchedule
+		}
+		t, err := parsePublishAt(*publishAt)
+		if err != nil {
+			return "", nil, err
+		}
+		return model.PostStatusScheduled, &t, nil
+	}
+	return "", nil, ErrInvalidPostStatus
+}
+
+func parseVisibility(v string) (string, error) {
+	switch v {
+	case "":
+		return model.PostVisibilityPublic, nil
+	case model.PostVisibilityPublic, model.PostVisibilityFollowers,
+		model.PostVisibilityCloseFriends, model.PostVisibilityOnlyMe:
+		return v, nil
+	}
+	return "", ErrInvalidVisibility
+}
+
+func parseReaction(r string) (string, error) {
+	switch r {
+	case model.ReactionLike, model.ReactionLove, model.ReactionHaha,
+		model.ReactionWow, model.ReactionSad, model.ReactionAngry:
+		return r, nil
+	}
+	return "", ErrInvalidReaction
+}
+
+func parsePublishAt(s string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil || !t.After(time.Now()) {
+		return time.Time{}, ErrInvalidSchedule
+	}
+	return t, nil
+}
+
+// published fires the side effects of a post going public; drafts and
+// scheduled posts get them from Publish or the scheduler instead.
+func (s *postService) published(post *model.Post) {
+	if post.Status != model.PostStatusPublished {
+		return
+	}
+	s.streamSvc.PublishFeedItem(post.UserID, post.ID, post.Visibility)
+	s.timelines.PostPublished(post)
+}
+
 func NewPostService(
 	postRepo repository.PostRepository,
 	commentSvc CommentService,
-	commentTree CommentTreeService,
 	fileSvc *FileService,
+	notifySvc NotificationService,
+	streamSvc StreamService,
+	views *PostViewBuilder,
+	viewSvc ViewService,
+	analyticsSvc AnalyticsService,
+	timelines TimelineService,
 ) PostService {
 	return &postService{
-		repo:        postRepo,
-		commentSvc:  commentSvc,
-		commentTree: commentTree,
-		fileSvc:     fileSvc,
+		repo:         postRepo,
+		commentSvc:   commentSvc,
+		fileSvc:      fileSvc,
+		notifySvc:    notifySvc,
+		streamSvc:    streamSvc,
+		views:        views,
+		viewSvc:      viewSvc,
+		analyticsSvc: analyticsSvc,
+		timelines:    timelines,
 	}
 }
 
@@ -88,39 +204,102 @@ func (s *postService) CreatePost(userID uint, req dto.CreatePostRequest) error {
 	if userID == 0 {
 		return fmt.Errorf("unauthorized")
 	}
+	if err := s.checkQuoted(req.QuotedPostID, userID); err != nil {
+		return err
+	}
+	status, publishAt, err := parsePostStatus(req.Status, req.PublishAt)
+	if err != nil {
+		return err
+	}
+	visibility, err := parseVisibility(req.Visibility)
+	if err != nil {
+		return err
+	}
+	poll, err := buildPoll(req.Poll)
+	if err != nil {
+		return err
+	}
 	post := model.Post{
-		UserID:      userID,
-		Description: req.Description,
+		UserID:       userID,
+		Description:  req.Description,
+		QuotedPostID: req.QuotedPostID,
+		Status:       status,
+		PublishAt:    publishAt,
+		Visibility:   visibility,
 	}
-	return s.repo.CreatePost(&post)
+	if err := s.repo.CreatePost(&post, poll); err != nil {
+		return err
+	}
+	s.published(&post)
+	return nil
 }
 
 func (s *postService) UpdatePost(postID uint, userID uint, req dto.UpdatePostRequest) error {
 	if postID == 0 || userID == 0 {
 		return fmt.Errorf("invalid ids")
 	}
-	post, err := s.repo.FindByID(postID)
+	post, err := s.repo.FindByID(postID, userID)
 	if err != nil {
 		return fmt.Errorf("find post: %w", err)
 	}
 	if post.UserID != userID {
 		return fmt.Errorf("forbidden")
 	}
-	updates := map[string]interface{}{}
-	if req.Description != nil {
-		updates["description"] = *req.Description
+	if req.Visibility != nil && *req.Visibility != post.Visibility {
+		visibility, err := parseVisibility(*req.Visibility)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.UpdateFields(postID, map[string]interface{}{"visibility": visibility}); err != nil {
+			return err
+		}
+		// пост «только для себя» не раскладывался по лентам — раскладываем,
+		// как только его видит кто-то ещё
+		if post.Status == model.PostStatusPublished && post.Visibility == model.PostVisibilityOnlyMe {
+			shared := *post
+			shared.Visibility = visibility
+			s.timelines.PostPublished(&shared)
+		}
+		post.Visibility = visibility
 	}
-	if len(updates) == 0 {
+	if req.Description == nil || sameText(post.Description, req.Description) {
 		return nil
 	}
-	return s.repo.UpdateFields(postID, updates)
+	// правки черновика не считаются редактированием — историю ведём только для опубликованных
+	if post.Status != model.PostStatusPublished {
+		return s.repo.UpdateFields(postID, map[string]interface{}{"description": req.Description})
+	}
+	return s.repo.EditDescription(post, req.Description)
+}
+
+func sameText(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func (s *postService) GetRevisions(postID, viewerID uint) (*dto.PostRevisionsResponse, error) {
+	if postID == 0 || viewerID == 0 {
+		return nil, fmt.Errorf("invalid ids")
+	}
+	post, err := s.repo.FindByID(postID, viewerID)
+	if err != nil {
+		return nil, fmt.Errorf("find post: %w", err)
+	}
+	revs, err := s.repo.ListRevisions(postID)
+	if err != nil {
+		return nil, err
+	}
+	res := mapper.MapPostRevisions(post, revs)
+	return &res, nil
 }
 
 func (s *postService) DeletePost(postID uint, userID uint) error {
 	if postID == 0 || userID == 0 {
 		return fmt.Errorf("invalid ids")
 	}
-	post, err := s.repo.FindByID(postID)
+	post, err := s.repo.FindByID(postID, userID)
 	if err != nil {
 		return fmt.Errorf("find post: %w", err)
 	}
@@ -148,31 +327,107 @@ func (s *postService) LikePost(postID, userID uint) error {
 	if postID == 0 || userID == 0 {
 		return fmt.Errorf("invalid ids")
 	}
-	return s.repo.LikePost(postID, userID)
+	if _, err := s.repo.FindByID(postID, userID); err != nil {
+		return fmt.Errorf("find post: %w", err)
+	}
+	created, err := s.repo.LikePost(postID, userID)
+	if err != nil {
+		return err
+	}
+	if created {
+		s.analyticsSvc.TrackLike(postID, userID)
+	}
+	s.notifySvc.NotifyPostLike(postID, userID)
+	s.publishLikes(postID)
+	return nil
 }
 
 func (s *postService) UnlikePost(postID, userID uint) error {
 	if postID == 0 || userID == 0 {
 		return fmt.Errorf("invalid ids")
 	}
-	return s.repo.UnlikePost(postID, userID)
+	if _, err := s.repo.FindByID(postID, userID); err != nil {
+		return fmt.Errorf("find post: %w", err)
+	}
+	if err := s.repo.UnlikePost(postID, userID); err != nil {
+		return err
+	}
+	s.publishLikes(postID)
+	return nil
+}
+
+// React sets or changes the user's reaction. Only the first reaction counts
+// as a like for notifications and insights.
+func (s *postService) React(postID, userID uint, req dto.ReactionRequest) (*dto.ReactionResponse, error) {
+	if postID == 0 || userID == 0 {
+		return nil, fmt.Errorf("invalid ids")
+	}
+	reaction, err := parseReaction(req.Reaction)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.FindByID(postID, userID); err != nil {
+		return nil, fmt.Errorf("find post: %w", err)
+	}
+	created, err := s.repo.React(postID, userID, reaction)
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		s.analyticsSvc.TrackLike(postID, userID)
+		s.notifySvc.NotifyPostLike(postID, userID)
+	}
+
+	counts, err := s.repo.ReactionCounts(postID)
+	if err != nil {
+		return nil, err
+	}
+	s.streamSvc.PublishPostLikes(postID, counts)
+	return &dto.ReactionResponse{Reactions: counts, MyReaction: &reaction}, nil
+}
+
+func (s *postService) Unreact(postID, userID uint) (*dto.ReactionResponse, error) {
+	if err := s.UnlikePost(postID, userID); err != nil {
+		return nil, err
+	}
+	counts, err := s.repo.ReactionCounts(postID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.ReactionResponse{Reactions: counts}, nil
+}
+
+func (s *postService) GetLikers(postID, viewerID uint, limit int, cursor string) (*dto.LikersResponse, error) {
+	if postID == 0 || viewerID == 0 {
+		return nil, fmt.Errorf("invalid ids")
+	}
+	if _, err := s.repo.FindByID(postID, viewerID); err != nil {
+		return nil, fmt.Errorf("find post: %w", err)
+	}
+	return likersPage(limit, cursor, func(limit int, cur *repository.LikerCursor) ([]repository.Liker, error) {
+		return s.repo.Likers(postID, viewerID, limit, cur)
+	})
+}
+
+func (s *postService) publishLikes(postID uint) {
+	counts, err := s.repo.ReactionCounts(postID)
+	if err != nil {
+		log.Printf("publish post likes: count %d: %v", postID, err)
+		return
+	}
+	s.streamSvc.PublishPostLikes(postID, counts)
 }
 
 func (s *postService) GetPost(postID, userID uint) (*dto.PostWithCommentsResponse, error) {
 	if postID == 0 {
 		return nil, fmt.Errorf("invalid id")
 	}
-	post, err := s.repo.FindByID(postID)
+	post, err := s.repo.FindByID(postID, userID)
 	if err != nil {
 		return nil, fmt.Errorf("find post: %w", err)
 	}
-
-	isLiked := false
-	for _, l := range post.Likes {
-		if l.UserID == userID {
-			isLiked = true
-			break
-		}
+	if post.Status == model.PostStatusPublished {
+		s.viewSvc.RecordView(post.ID, userID)
 	}
 
 	var files []dto.FileResponse
@@ -180,18 +435,41 @@ func (s *postService) GetPost(postID, userID uint) (*dto.PostWithCommentsRespons
 		files = append(files, dto.FileResponse{ID: f.ID, URL: f.URL})
 	}
 
-	tree, err := s.commentTree.GetCommentTree(postID, userID)
+	threads, err := s.commentSvc.ListThreads(postID, userID, "", false, 0, "")
+	if err != nil {
+		return nil, fmt.Errorf("comment threads: %w", err)
+	}
+
+	views, err := s.views.Build([]model.Post{*post}, userID)
 	if err != nil {
-		return nil, fmt.Errorf("comment tree: %w", err)
+		return nil, err
 	}
+	view := views[0]
 
 	return &dto.PostWithCommentsResponse{
-		ID:          post.ID,
-		Description: post.Description,
-		Files:       files,
-		LikesCount:  len(post.Likes),
-		IsLiked:     isLiked,
-		Comments:    tree,
+		ID:             post.ID,
+		Description:    post.Description,
+		Files:          files,
+		LikesCount:     view.LikesCount,
+		IsLiked:        view.IsLiked,
+		Reactions:      view.Reactions,
+		MyReaction:     view.MyReaction,
+		IsBookmarked:   view.IsBookmarked,
+		RepostsCount:   view.RepostsCount,
+		IsReposted:     view.IsReposted,
+		ViewsCount:     post.ViewsCount,
+		EditedAt:       mapper.FormatTimePtr(post.EditedAt),
+		Status:         post.Status,
+		PublishAt:      mapper.FormatTimePtr(post.PublishAt),
+		Visibility:     post.Visibility,
+		Comments:       threads.Comments,
+		CommentsCursor: threads.NextCursor,
+		CommentPolicy:  threads.CommentPolicy,
+		CanComment:     threads.CanComment,
+
+		QuotedPost:       view.QuotedPost,
+		QuoteUnavailable: view.QuoteUnavailable,
+		Poll:             view.Poll,
 	}, nil
 }
 
@@ -203,10 +481,147 @@ func (s *postService) GetUserPosts(targetUserID, viewerID uint) ([]dto.PostRespo
 		return nil, fmt.Errorf("unauthorized")
 	}
 
-	posts, err := s.repo.GetByUser(targetUserID)
+	posts, err := s.repo.GetByUser(targetUserID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.views.Build(posts, viewerID)
+}
+
+// checkQuoted makes sure a quote post points at a post the author can see
+// and that may be shared.
+func (s *postService) checkQuoted(quotedPostID *uint, userID uint) error {
+	if quotedPostID == nil {
+		return nil
+	}
+	quoted, err := s.repo.FindByID(*quotedPostID, userID)
+	if err != nil {
+		return fmt.Errorf("quoted post: %w", err)
+	}
+	if !shareable(quoted) {
+		return ErrNotShareable
+	}
+	return nil
+}
+
+// shareable: reposting or quoting must not widen a post's audience.
+func shareable(post *model.Post) bool {
+	return post.Status == model.PostStatusPublished && post.Visibility == model.PostVisibilityPublic
+}
+
+func (s *postService) Repost(postID, userID uint) (*dto.RepostResponse, error) {
+	if postID == 0 || userID == 0 {
+		return nil, fmt.Errorf("invalid ids")
+	}
+	post, err := s.repo.FindByID(postID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("find post: %w", err)
+	}
+	if !shareable(post) {
+		return nil, ErrNotShareable
+	}
+
+	created, err := s.repo.Repost(postID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		s.notifySvc.NotifyRepost(postID, userID)
+		s.streamSvc.PublishFeedItem(userID, postID, post.Visibility)
+		s.timelines.Reposted(userID, post)
+	}
+
+	return s.repostResponse(postID, true)
+}
+
+func (s *postService) Unrepost(postID, userID uint) (*dto.RepostResponse, error) {
+	if postID == 0 || userID == 0 {
+		return nil, fmt.Errorf("invalid ids")
+	}
+	if err := s.repo.Unrepost(postID, userID); err != nil {
+		return nil, err
+	}
+	// автор нужен, чтобы найти запись в лентах; пост, который уже не виден,
+	// из лент всё равно отфильтруется при чтении
+	if post, err := s.repo.FindByID(postID, userID); err == nil {
+		s.timelines.Unreposted(userID, post)
+	}
+	return s.repostResponse(postID, false)
+}
+
+func (s *postService) repostResponse(postID uint, reposted bool) (*dto.RepostResponse, error) {
+	counts, err := s.repo.RepostCounts([]uint{postID})
+	if err != nil {
+		return nil, err
+	}
+	return &dto.RepostResponse{
+		PostID:       postID,
+		Reposted:     reposted,
+		RepostsCount: counts[postID],
+	}, nil
+}
+
+func (s *postService) GetDrafts(userID uint) ([]dto.PostResponse, error) {
+	if userID == 0 {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	posts, err := s.repo.GetDrafts(userID)
 	if err != nil {
 		return nil, err
 	}
+	return s.views.Build(posts, userID)
+}
 
-	return mapper.MapPostsToDTO(posts, viewerID), nil
+// ownDraft loads a post of userID that isn't published yet.
+func (s *postService) ownDraft(postID, userID uint) (*model.Post, error) {
+	if postID == 0 || userID == 0 {
+		return nil, fmt.Errorf("invalid ids")
+	}
+	post, err := s.repo.FindByID(postID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("find post: %w", err)
+	}
+	if post.UserID != userID {
+		return nil, ErrForbidden
+	}
+	if post.Status == model.PostStatusPublished {
+		return nil, ErrPostPublished
+	}
+	return post, nil
+}
+
+func (s *postService) Schedule(postID, userID uint, req dto.SchedulePostRequest) error {
+	if _, err := s.ownDraft(postID, userID); err != nil {
+		return err
+	}
+	publishAt, err := parsePublishAt(req.PublishAt)
+	if err != nil {
+		return err
+	}
+	return s.repo.SetStatus(postID, model.PostStatusScheduled, &publishAt)
+}
+
+func (s *postService) Unschedule(postID, userID uint) error {
+	if _, err := s.ownDraft(postID, userID); err != nil {
+		return err
+	}
+	return s.repo.SetStatus(postID, model.PostStatusDraft, nil)
+}
+
+func (s *postService) Publish(postID, userID uint) error {
+	post, err := s.ownDraft(postID, userID)
+	if err != nil {
+		return err
+	}
+	done, err := s.repo.Publish(postID)
+	if err != nil {
+		return err
+	}
+	if done {
+		post.Status = model.PostStatusPublished
+		post.CreatedAt = time.Now()
+		s.published(post)
+	}
+	return nil
 }
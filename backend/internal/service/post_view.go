@@ -0,0 +1,123 @@
+package service
+
+import (
+	"fmt"
+
+	"backend/internal/dto"
+	"backend/internal/mapper"
+	"backend/internal/model"
+	"backend/internal/repository"
+)
+
+// PostViewBuilder turns posts into PostResponse for a particular viewer,
+// loading counts and the per-viewer state in batch instead of per post or
+// through preloaded rows.
+type PostViewBuilder struct {
+	postRepo     repository.PostRepository
+	bookmarkRepo repository.BookmarkRepository
+	pollRepo     repository.PollRepository
+}
+
+func NewPostViewBuilder(
+	postRepo repository.PostRepository,
+	bookmarkRepo repository.BookmarkRepository,
+	pollRepo repository.PollRepository,
+) *PostViewBuilder {
+	return &PostViewBuilder{postRepo: postRepo, bookmarkRepo: bookmarkRepo, pollRepo: pollRepo}
+}
+
+func (b *PostViewBuilder) ViewerData(posts []model.Post, viewerID uint) (mapper.PostViewerData, error) {
+	ids := make([]uint, 0, len(posts))
+	var quotedIDs []uint
+	for _, p := range posts {
+		ids = append(ids, p.ID)
+		if p.QuotedPostID != nil {
+			quotedIDs = append(quotedIDs, *p.QuotedPostID)
+		}
+	}
+
+	bookmarked, err := b.bookmarkRepo.BookmarkedByUser(ids, viewerID)
+	if err != nil {
+		return mapper.PostViewerData{}, fmt.Errorf("bookmarked by user: %w", err)
+	}
+	repostCounts, err := b.postRepo.RepostCounts(ids)
+	if err != nil {
+		return mapper.PostViewerData{}, fmt.Errorf("repost counts: %w", err)
+	}
+	reposted, err := b.postRepo.RepostedByUser(ids, viewerID)
+	if err != nil {
+		return mapper.PostViewerData{}, fmt.Errorf("reposted by user: %w", err)
+	}
+
+	reactions, err := b.postRepo.ReactionCountsByPost(ids)
+	if err != nil {
+		return mapper.PostViewerData{}, fmt.Errorf("reaction counts: %w", err)
+	}
+	mine, err := b.postRepo.ReactionsByUser(ids, viewerID)
+	if err != nil {
+		return mapper.PostViewerData{}, fmt.Errorf("reactions by user: %w", err)
+	}
+	commentCounts, err := b.postRepo.CommentCounts(ids)
+	if err != nil {
+		return mapper.PostViewerData{}, fmt.Errorf("comment counts: %w", err)
+	}
+
+	quotedPosts, err := b.postRepo.FindByIDs(quotedIDs, viewerID)
+	if err != nil {
+		return mapper.PostViewerData{}, fmt.Errorf("quoted posts: %w", err)
+	}
+	quoted := make(map[uint]model.Post, len(quotedPosts))
+	for _, q := range quotedPosts {
+		quoted[q.ID] = q
+	}
+
+	data := mapper.PostViewerData{
+		Bookmarked:    bookmarked,
+		RepostCounts:  repostCounts,
+		Reposted:      reposted,
+		Reactions:     reactions,
+		MyReactions:   mine,
+		CommentCounts: commentCounts,
+		Quoted:        quoted,
+	}
+	if err := b.loadPolls(&data, ids, viewerID); err != nil {
+		return mapper.PostViewerData{}, err
+	}
+	return data, nil
+}
+
+func (b *PostViewBuilder) loadPolls(data *mapper.PostViewerData, postIDs []uint, viewerID uint) error {
+	polls, err := b.pollRepo.ForPosts(postIDs)
+	if err != nil {
+		return err
+	}
+	data.Polls = make(map[uint]model.Poll, len(polls))
+	if len(polls) == 0 {
+		return nil
+	}
+
+	pollIDs := make([]uint, 0, len(polls))
+	for _, p := range polls {
+		data.Polls[p.PostID] = p
+		pollIDs = append(pollIDs, p.ID)
+	}
+
+	if data.PollVotes, err = b.pollRepo.OptionCounts(pollIDs); err != nil {
+		return err
+	}
+	if data.PollVoters, err = b.pollRepo.VoterCounts(pollIDs); err != nil {
+		return err
+	}
+	if data.PollChoices, err = b.pollRepo.Choices(pollIDs, viewerID); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (b *PostViewBuilder) Build(posts []model.Post, viewerID uint) ([]dto.PostResponse, error) {
+	viewer, err := b.ViewerData(posts, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	return mapper.MapPostsToDTO(posts, viewerID, viewer), nil
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"backend/internal/repository"
+)
+
+const (
+	// StoryCleanupInterval is how often expired stories are removed.
+	StoryCleanupInterval = 10 * time.Minute
+	storyCleanupBatch    = 100
+)
+
+// StoryCleaner deletes expired stories and their media files. Expired
+// stories are already hidden by queries, so the cleanup may lag safely.
+type StoryCleaner interface {
+	Run(ctx context.Context)
+}
+
+type storyCleaner struct {
+	repo    repository.StoryRepository
+	fileSvc *FileService
+}
+
+func NewStoryCleaner(repo repository.StoryRepository, fileSvc *FileService) StoryCleaner {
+	return &storyCleaner{repo: repo, fileSvc: fileSvc}
+}
+
+func (s *storyCleaner) Run(ctx context.Context) {
+	ticker := time.NewTicker(StoryCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		s.cleanup(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *storyCleaner) cleanup(ctx context.Context) {
+	for ctx.Err() == nil {
+		urls, err := s.repo.DeleteExpired(storyCleanupBatch)
+		if err != nil {
+			log.Printf("story cleanup: %v", err)
+			return
+		}
+		for _, u := range urls {
+			if err := s.fileSvc.Remove(u); err != nil {
+				log.Printf("story cleanup: %v", err)
+			}
+		}
+		if len(urls) < storyCleanupBatch {
+			return
+		}
+	}
+}
@@ -0,0 +1,332 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"backend/internal/dto"
+	"backend/internal/mapper"
+	"backend/internal/model"
+	"backend/internal/repository"
+)
+
+const (
+	StoryLifetime         = 24 * time.Hour
+	MaxStoryCaptionLength = 200
+	storyTrayLimit        = 100
+	storyReplyAsMessage   = "message"
+)
+
+var storyMediaTypes = map[string]string{
+	"jpg":  model.StoryMediaImage,
+	"jpeg": model.StoryMediaImage,
+	"png":  model.StoryMediaImage,
+	"gif":  model.StoryMediaImage,
+	"mp4":  model.StoryMediaVideo,
+}
+
+type StoryService interface {
+	Create(userID uint, req dto.CreateStoryRequestMultipart, file *multipart.FileHeader) (*dto.StoryDTO, error)
+	Delete(storyID, userID uint) error
+
+	Tray(userID uint) (*dto.StoryTrayResponse, error)
+	UserStories(targetID, viewerID uint) ([]dto.StoryDTO, error)
+
+	View(storyID, userID uint) error
+	Viewers(storyID, userID uint, limit int, cursor string) (*dto.StoryViewersResponse, error)
+	Reply(storyID, userID uint, req dto.StoryReplyRequest) (*dto.StoryReplyResponse, error)
+}
+
+type storyService struct {
+	repo       repository.StoryRepository
+	userRepo   repository.UserRepository
+	fileSvc    *FileService
+	messageSvc MessageService
+}
+
+func NewStoryService(
+	repo repository.StoryRepository,
+	userRepo repository.UserRepository,
+	fileSvc *FileService,
+	messageSvc MessageService,
+) StoryService {
+	return &storyService{
+		repo:       repo,
+		userRepo:   userRepo,
+		fileSvc:    fileSvc,
+		messageSvc: messageSvc,
+	}
+}
+
+func (s *storyService) Create(userID uint, req dto.CreateStoryRequestMultipart, file *multipart.FileHeader) (*dto.StoryDTO, error) {
+	if userID == 0 {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	if file == nil {
+		return nil, fmt.Errorf("file is required")
+	}
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(file.Filename)), ".")
+	mediaType, ok := storyMediaTypes[ext]
+	if !ok {
+		return nil, ErrInvalidStoryMedia
+	}
+	caption := strings.TrimSpace(req.Caption)
+	if len([]rune(caption)) > MaxStoryCaptionLength {
+		return nil, fmt.Errorf("caption is too long, max %d characters", MaxStoryCaptionLength)
+	}
+
+	urls, err := s.fileSvc.SaveFilesTo(filepath.Join("stories", fmt.Sprint(userID)), []*multipart.FileHeader{file})
+	if err != nil {
+		return nil, err
+	}
+
+	story := model.Story{
+		UserID:    userID,
+		MediaURL:  urls[0],
+		MediaType: mediaType,
+		Caption:   caption,
+		ExpiresAt: time.Now().Add(StoryLifetime),
+	}
+	if err := s.repo.Create(&story); err != nil {
+		if rmErr := s.fileSvc.Remove(story.MediaURL); rmErr != nil {
+			log.Printf("remove story media %s: %v", story.MediaURL, rmErr)
+		}
+		return nil, err
+	}
+
+	created, err := s.repo.GetActive(story.ID)
+	if err != nil {
+		return nil, err
+	}
+	zero := 0
+	resp := mapper.MapStoryToDTO(*created, true, &zero)
+	return &resp, nil
+}
+
+// visibleStory hides expired stories and stories of users on either side of
+// a block behind ErrNotFound.
+func (s *storyService) visibleStory(storyID, viewerID uint) (*model.Story, error) {
+	if storyID == 0 || viewerID == 0 {
+		return nil, fmt.Errorf("invalid ids")
+	}
+	story, err := s.repo.GetActive(storyID)
+	if err != nil {
+		return nil, err
+	}
+	if story.UserID == viewerID {
+		return story, nil
+	}
+	blocked, err := s.userRepo.IsBlockedEither(viewerID, story.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, repository.ErrNotFound
+	}
+	return story, nil
+}
+
+func (s *storyService) Delete(storyID, userID uint) error {
+	story, err := s.visibleStory(storyID, userID)
+	if err != nil {
+		return err
+	}
+	if story.UserID != userID {
+		return ErrForbidden
+	}
+	if err := s.repo.Delete(story.ID); err != nil {
+		return err
+	}
+	// строка уже удалена, так что неудачу с файлом только логируем
+	if err := s.fileSvc.Remove(story.MediaURL); err != nil {
+		log.Printf("remove story media %s: %v", story.MediaURL, err)
+	}
+	return nil
+}
+
+func (s *storyService) mapStories(stories []model.Story, viewerID uint) ([]dto.StoryDTO, error) {
+	ids := make([]uint, 0, len(stories))
+	var ownIDs []uint
+	for _, st := range stories {
+		ids = append(ids, st.ID)
+		if st.UserID == viewerID {
+			ownIDs = append(ownIDs, st.ID)
+		}
+	}
+
+	seen, err := s.repo.SeenByUser(ids, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.repo.ViewerCounts(ownIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dto.StoryDTO, 0, len(stories))
+	for _, st := range stories {
+		var viewers *int
+		if st.UserID == viewerID {
+			n := counts[st.ID]
+			viewers = &n
+		}
+		res = append(res, mapper.MapStoryToDTO(st, seen[st.ID] || st.UserID == viewerID, viewers))
+	}
+	return res, nil
+}
+
+func (s *storyService) Tray(userID uint) (*dto.StoryTrayResponse, error) {
+	if userID == 0 {
+		return nil, fmt.Errorf("unauthorized")
+	}
+
+	own, err := s.repo.ActiveByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	ownDTO, err := s.mapStories(own, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.repo.Tray(userID, storyTrayLimit)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, e.UserID)
+	}
+	users, err := s.userRepo.GetByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]model.User, len(users))
+	for _, u := range users {
+		byID[u.ID] = u
+	}
+
+	items := make([]dto.StoryTrayItemDTO, 0, len(entries))
+	for _, e := range entries {
+		u, ok := byID[e.UserID]
+		if !ok {
+			continue
+		}
+		items = append(items, dto.StoryTrayItemDTO{
+			User:         dto.UserShortDTO{ID: u.ID, Nickname: u.Nickname, Avatar: u.AvatarURL},
+			StoriesCount: e.StoriesCount,
+			HasUnseen:    e.HasUnseen,
+			LatestAt:     e.LatestAt,
+		})
+	}
+
+	return &dto.StoryTrayResponse{Own: ownDTO, Users: items}, nil
+}
+
+func (s *storyService) UserStories(targetID, viewerID uint) ([]dto.StoryDTO, error) {
+	if targetID == 0 || viewerID == 0 {
+		return nil, fmt.Errorf("invalid ids")
+	}
+	if targetID != viewerID {
+		blocked, err := s.userRepo.IsBlockedEither(viewerID, targetID)
+		if err != nil {
+			return nil, err
+		}
+		if blocked {
+			return []dto.StoryDTO{}, nil
+		}
+	}
+
+	stories, err := s.repo.ActiveByUser(targetID)
+	if err != nil {
+		return nil, err
+	}
+	return s.mapStories(stories, viewerID)
+}
+
+func (s *storyService) View(storyID, userID uint) error {
+	story, err := s.visibleStory(storyID, userID)
+	if err != nil {
+		return err
+	}
+	if story.UserID == userID {
+		return nil
+	}
+	_, err = s.repo.MarkViewed(story.ID, userID)
+	return err
+}
+
+func (s *storyService) Viewers(storyID, userID uint, limit int, cursor string) (*dto.StoryViewersResponse, error) {
+	story, err := s.visibleStory(storyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if story.UserID != userID {
+		return nil, ErrForbidden
+	}
+
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	cur, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	views, err := s.repo.Viewers(story.ID, limit+1, cur)
+	if err != nil {
+		return nil, err
+	}
+
+	hasMore := len(views) > limit
+	if hasMore {
+		views = views[:limit]
+	}
+
+	viewers := make([]dto.StoryViewerDTO, 0, len(views))
+	for _, v := range views {
+		viewers = append(viewers, dto.StoryViewerDTO{
+			User:     dto.UserShortDTO{ID: v.User.ID, Nickname: v.User.Nickname, Avatar: v.User.AvatarURL},
+			ViewedAt: v.ViewedAt,
+		})
+	}
+
+	var nextCursor *string
+	if hasMore {
+		last := views[len(views)-1]
+		c := encodeCursor(last.ViewedAt, last.UserID)
+		nextCursor = &c
+	}
+
+	return &dto.StoryViewersResponse{
+		Viewers:    viewers,
+		NextCursor: nextCursor,
+		HasMore:    hasMore,
+	}, nil
+}
+
+// Reply sends the text to the author as a direct message. If the author
+// doesn't accept messages from the caller, the reply is refused with
+// ErrDMNotAllowed rather than delivered some other way.
+func (s *storyService) Reply(storyID, userID uint, req dto.StoryReplyRequest) (*dto.StoryReplyResponse, error) {
+	story, err := s.visibleStory(storyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if story.UserID == userID {
+		return nil, fmt.Errorf("cannot reply to your own story")
+	}
+	if strings.TrimSpace(req.Text) == "" {
+		return nil, fmt.Errorf("text is required")
+	}
+
+	msg, err := s.messageSvc.ReplyToStory(userID, story.UserID, story.ID, req.Text)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.StoryReplyResponse{DeliveredAs: storyReplyAsMessage, Message: msg}, nil
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"backend/internal/dto"
+	"backend/internal/mapper"
+	"backend/internal/model"
+	"backend/internal/pubsub"
+	"backend/internal/repository"
+)
+
+const (
+	EventNotification   = "notification"
+	EventComment        = "comment"
+	EventCommentUpdated = "comment_updated"
+	EventCommentDeleted = "comment_deleted"
+	EventPostLikes      = "post_likes"
+	EventCommentLikes   = "comment_likes"
+	EventFeedItem       = "feed_item"
+
+	EventMessage        = "message"
+	EventMessageRead    = "message_read"
+	EventMessageDeleted = "message_deleted"
+
+	// MaxWatchedPosts caps how many post topics one connection may follow.
+	MaxWatchedPosts = 20
+
+	publishTimeout = 3 * time.Second
+)
+
+func UserTopic(userID uint) string { return fmt.Sprintf("user:%d", userID) }
+func PostTopic(postID uint) string { return fmt.Sprintf("post:%d", postID) }
+
+type StreamService interface {
+	Subscribe(ctx context.Context, userID uint, postIDs []uint, lastEventID string) (<-chan pubsub.Event, error)
+
+	PublishNotification(userID uint, n dto.NotificationDTO)
+	PublishComment(c dto.CommentDTO)
+	PublishCommentUpdated(c dto.CommentDTO)
+	PublishCommentDeleted(postID, commentID uint, placeholder bool)
+	PublishPostLikes(postID uint, reactions map[string]int)
+	PublishCommentLikes(postID, commentID uint, reactions map[string]int)
+	PublishFeedItem(authorID, postID uint, visibility string)
+	PublishToUsers(userIDs []uint, typ string, data interface{})
+}
+
+type streamService struct {
+	broker   pubsub.Broker
+	userRepo repository.UserRepository
+	postRepo repository.PostRepository
+}
+
+func NewStreamService(broker pubsub.Broker, userRepo repository.UserRepository, postRepo repository.PostRepository) StreamService {
+	return &streamService{broker: broker, userRepo: userRepo, postRepo: postRepo}
+}
+
+// Subscribe follows the viewer's own topic (notifications, feed items) plus
+// the topics of the posts currently open on the client.
+func (s *streamService) Subscribe(ctx context.Context, userID uint, postIDs []uint, lastEventID string) (<-chan pubsub.Event, error) {
+	if userID == 0 {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	if len(postIDs) > MaxWatchedPosts {
+		return nil, fmt.Errorf("too many posts, max %d", MaxWatchedPosts)
+	}
+	if err := pubsub.ValidateEventID(lastEventID); err != nil {
+		return nil, err
+	}
+
+	// посты, которые зрителю не видны, молча пропускаем
+	postIDs, err := s.postRepo.VisibleIDs(postIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	topics := []string{UserTopic(userID)}
+	for _, id := range postIDs {
+		topics = append(topics, PostTopic(id))
+	}
+	return s.broker.Subscribe(ctx, topics, lastEventID)
+}
+
+func (s *streamService) PublishNotification(userID uint, n dto.NotificationDTO) {
+	s.publish(UserTopic(userID), EventNotification, n)
+}
+
+func (s *streamService) PublishComment(c dto.CommentDTO) {
+	s.publish(PostTopic(c.PostID), EventComment, c)
+}
+
+func (s *streamService) PublishCommentUpdated(c dto.CommentDTO) {
+	s.publish(PostTopic(c.PostID), EventCommentUpdated, c)
+}
+
+func (s *streamService) PublishCommentDeleted(postID, commentID uint, placeholder bool) {
+	s.publish(PostTopic(postID), EventCommentDeleted, dto.CommentDeletedEvent{
+		PostID:      postID,
+		CommentID:   commentID,
+		Placeholder: placeholder,
+	})
+}
+
+func (s *streamService) PublishPostLikes(postID uint, reactions map[string]int) {
+	s.publish(PostTopic(postID), EventPostLikes, dto.PostLikesEvent{
+		PostID:     postID,
+		LikesCount: mapper.ReactionsTotal(reactions),
+		Reactions:  reactions,
+	})
+}
+
+func (s *streamService) PublishCommentLikes(postID, commentID uint, reactions map[string]int) {
+	s.publish(PostTopic(postID), EventCommentLikes, dto.CommentLikesEvent{
+		PostID:     postID,
+		CommentID:  commentID,
+		LikesCount: mapper.ReactionsTotal(reactions),
+		Reactions:  reactions,
+	})
+}
+
+// PublishFeedItem pushes a new post to the followers of its author who are
+// in the post's audience. It runs in the background so that post creation
+// doesn't wait for the fan-out.
+func (s *streamService) PublishFeedItem(authorID, postID uint, visibility string) {
+	if visibility == model.PostVisibilityOnlyMe {
+		return
+	}
+	go func() {
+		followers, err := s.userRepo.GetFollowerIDs(authorID)
+		if err != nil {
+			log.Printf("stream: follower ids of %d: %v", authorID, err)
+			return
+		}
+		if visibility == model.PostVisibilityCloseFriends {
+			if followers, err = s.closeFriendsAmong(authorID, followers); err != nil {
+				log.Printf("stream: close friends of %d: %v", authorID, err)
+				return
+			}
+		}
+		item := dto.FeedItemEvent{PostID: postID, UserID: authorID}
+		for _, id := range followers {
+			s.publish(UserTopic(id), EventFeedItem, item)
+		}
+	}()
+}
+
+func (s *streamService) closeFriendsAmong(authorID uint, ids []uint) ([]uint, error) {
+	friends, err := s.userRepo.GetCloseFriendIDs(authorID)
+	if err != nil {
+		return nil, err
+	}
+	isFriend := make(map[uint]bool, len(friends))
+	for _, id := range friends {
+		isFriend[id] = true
+	}
+	res := make([]uint, 0, len(friends))
+	for _, id := range ids {
+		if isFriend[id] {
+			res = append(res, id)
+		}
+	}
+	return res, nil
+}
+
+func (s *streamService) PublishToUsers(userIDs []uint, typ string, data interface{}) {
+	for _, id := range userIDs {
+		s.publish(UserTopic(id), typ, data)
+	}
+}
+
+func (s *streamService) publish(topic, typ string, data interface{}) {
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+	if err := s.broker.Publish(ctx, topic, typ, data); err != nil {
+		log.Printf("stream: publish %s to %s: %v", typ, topic, err)
+	}
+}
@@ -0,0 +1,542 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"backend/internal/model"
+	"backend/internal/repository"
+	"backend/internal/timeline"
+)
+
+const (
+	// CelebrityFollowers is the follower count from which an author's posts
+	// are no longer pushed to followers but merged in when a feed is read.
+	CelebrityFollowers = 10000
+	// TimelineMaxLen is how many entries a home timeline keeps; the feed
+	// ends there.
+	TimelineMaxLen = 800
+	// TimelineTrimInterval is how often oversized timelines are cut and
+	// celebrities are recomputed.
+	TimelineTrimInterval = 10 * time.Minute
+
+	timelineWorkers    = 4
+	timelineQueueSize  = 1024
+	timelineFanoutSize = 1000
+	// timelineEnqueueWait is how long a request waits for room in a full
+	// queue before its write is dropped.
+	timelineEnqueueWait = 100 * time.Millisecond
+	// timelineReadRounds bounds the timeline reads behind one page when
+	// filtered-out posts leave it short.
+	timelineReadRounds = 4
+	// timelineBackfill is how much of a new followee's activity is copied
+	// into the follower's timeline.
+	timelineBackfill = 50
+)
+
+// TimelineService keeps materialized home timelines up to date. Writes are
+// queued and applied by background workers; reads merge the timeline with
+// the activity of followed celebrities, whose posts are never fanned out.
+// The timeline only holds candidates: visibility is checked when a page is
+// read.
+type TimelineService interface {
+	Run(ctx context.Context)
+	// Flush applies the writes still queued once Run has stopped.
+	Flush(ctx context.Context) error
+
+	PostPublished(post *model.Post)
+	Reposted(userID uint, post *model.Post)
+	Unreposted(userID uint, post *model.Post)
+	Followed(userID, targetID uint)
+	Unfollowed(userID, targetID uint)
+
+	// Page returns up to limit items older than cursor and the cursor of
+	// the next page, nil at the end. Items without the wanted media are
+	// left out before the page is cut; a page comes back short only when
+	// the timeline is mostly filtered out.
+	Page(userID uint, media string, limit int, cursor *repository.Cursor) ([]repository.FeedItem, *repository.Cursor, error)
+}
+
+type timelineJob func(ctx context.Context)
+
+type timelineService struct {
+	store    timeline.Store
+	feedRepo repository.FeedRepository
+	userRepo repository.UserRepository
+	jobs     chan timelineJob
+	workers  sync.WaitGroup
+}
+
+func NewTimelineService(store timeline.Store, feedRepo repository.FeedRepository, userRepo repository.UserRepository) TimelineService {
+	return &timelineService{
+		store:    store,
+		feedRepo: feedRepo,
+		userRepo: userRepo,
+		jobs:     make(chan timelineJob, timelineQueueSize),
+	}
+}
+
+// Run applies queued writes, recomputes celebrities and trims timelines
+// until ctx is done. A write that is running when ctx is done still
+// completes: jobs get a context of their own, as a half-applied fan-out is
+// never repaired.
+func (s *timelineService) Run(ctx context.Context) {
+	s.workers.Add(timelineWorkers)
+	for i := 0; i < timelineWorkers; i++ {
+		go func() {
+			defer s.workers.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case job := <-s.jobs:
+					job(context.Background())
+				}
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(TimelineTrimInterval)
+	defer ticker.Stop()
+	for {
+		if err := s.feedRepo.RefreshCelebrities(CelebrityFollowers); err != nil {
+			log.Printf("timeline: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.store.Trim(ctx); err != nil {
+				log.Printf("timeline: %v", err)
+			}
+		}
+	}
+}
+
+// Flush waits for the workers to stop and then runs the queued jobs until
+// the queue is empty or ctx is done.
+func (s *timelineService) Flush(ctx context.Context) error {
+	s.workers.Wait()
+	for {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("timeline: %d queued writes dropped: %w", len(s.jobs), err)
+		}
+		select {
+		case job := <-s.jobs:
+			job(ctx)
+		default:
+			return nil
+		}
+	}
+}
+
+// enqueue waits a little for room when the workers fall behind, then drops
+// and logs the write rather than pile up goroutines.
+func (s *timelineService) enqueue(job timelineJob) {
+	select {
+	case s.jobs <- job:
+		return
+	default:
+	}
+	timer := time.NewTimer(timelineEnqueueWait)
+	defer timer.Stop()
+	select {
+	case s.jobs <- job:
+	case <-timer.C:
+		log.Printf("timeline: queue full, write dropped")
+	}
+}
+
+// isCelebrity reads the flag Run keeps up to date, the same one reads use,
+// so an author is either pushed or pulled, never neither.
+func (s *timelineService) isCelebrity(userID uint) (bool, error) {
+	return s.feedRepo.IsCelebrity(userID)
+}
+
+// fanout pushes e to the followers of userID, in chunks. Celebrities are
+// skipped: their followers pull.
+func (s *timelineService) fanout(ctx context.Context, userID uint, e timeline.Entry) {
+	celebrity, err := s.isCelebrity(userID)
+	if err != nil {
+		log.Printf("timeline: followers count of %d: %v", userID, err)
+		return
+	}
+	if celebrity {
+		return
+	}
+	followers, err := s.userRepo.GetFollowerIDs(userID)
+	if err != nil {
+		log.Printf("timeline: follower ids of %d: %v", userID, err)
+		return
+	}
+	for start := 0; start < len(followers); start += timelineFanoutSize {
+		end := min(start+timelineFanoutSize, len(followers))
+		if err := s.store.Fanout(ctx, followers[start:end], e); err != nil {
+			log.Printf("timeline: %v", err)
+			return
+		}
+	}
+}
+
+// PostPublished fans a post out to its author's followers. Posts only the
+// author sees are left out; close friends posts go to everyone and are
+// filtered on read like any other visibility.
+func (s *timelineService) PostPublished(post *model.Post) {
+	if post.Visibility == model.PostVisibilityOnlyMe {
+		return
+	}
+	e := timeline.Entry{PostID: post.ID, AuthorID: post.UserID, At: post.CreatedAt}
+	s.enqueue(func(ctx context.Context) { s.fanout(ctx, post.UserID, e) })
+}
+
+func (s *timelineService) Reposted(userID uint, post *model.Post) {
+	e := timeline.Entry{PostID: post.ID, AuthorID: post.UserID, RepostedBy: userID, At: time.Now()}
+	s.enqueue(func(ctx context.Context) { s.fanout(ctx, userID, e) })
+}
+
+// Unreposted drops the repost from the reposter's followers. The repost had
+// replaced the post's entry, so those who still reach the post through its
+// author or another reposter get that entry back.
+func (s *timelineService) Unreposted(userID uint, post *model.Post) {
+	e := timeline.Entry{PostID: post.ID, AuthorID: post.UserID, RepostedBy: userID}
+	s.enqueue(func(ctx context.Context) {
+		followers, err := s.userRepo.GetFollowerIDs(userID)
+		if err != nil {
+			log.Printf("timeline: follower ids of %d: %v", userID, err)
+			return
+		}
+		for start := 0; start < len(followers); start += timelineFanoutSize {
+			end := min(start+timelineFanoutSize, len(followers))
+			chunk := followers[start:end]
+			if err := s.store.RemoveRepost(ctx, chunk, e); err != nil {
+				log.Printf("timeline: %v", err)
+				return
+			}
+			if err := s.restore(ctx, post.ID, chunk); err != nil {
+				log.Printf("timeline: %v", err)
+				return
+			}
+		}
+	})
+}
+
+// restore puts post back into the timelines of userIDs at its latest
+// activity among each user's followees; users who no longer reach it are
+// left alone. Users with the same entry are written in one fan-out.
+func (s *timelineService) restore(ctx context.Context, postID uint, userIDs []uint) error {
+	activity, err := s.feedRepo.FollowersActivity(postID, userIDs)
+	if err != nil {
+		return err
+	}
+	// the source and time identify an entry; time.Time itself makes a poor
+	// map key
+	type entryKey struct {
+		repostedBy uint
+		at         int64
+	}
+	entries := make(map[entryKey]timeline.Entry)
+	users := make(map[entryKey][]uint)
+	for _, a := range activity {
+		e := timelineEntry(a.FeedEntry)
+		k := entryKey{repostedBy: e.RepostedBy, at: e.At.UnixMicro()}
+		entries[k] = e
+		users[k] = append(users[k], a.UserID)
+	}
+	for k, e := range entries {
+		if err := s.store.Fanout(ctx, users[k], e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Followed backfills the follower's timeline with the new followee's recent
+// activity, unless the followee is pulled on read anyway.
+func (s *timelineService) Followed(userID, targetID uint) {
+	s.enqueue(func(ctx context.Context) {
+		celebrity, err := s.isCelebrity(targetID)
+		if err != nil {
+			log.Printf("timeline: followers count of %d: %v", targetID, err)
+			return
+		}
+		if celebrity {
+			return
+		}
+		entries, err := s.feedRepo.FollowingEntries(userID, []uint{targetID}, timelineBackfill, nil)
+		if err != nil {
+			log.Printf("timeline: backfill %d from %d: %v", userID, targetID, err)
+			return
+		}
+		if err := s.store.Add(ctx, userID, timelineEntries(entries)); err != nil {
+			log.Printf("timeline: %v", err)
+		}
+	})
+}
+
+// Unfollowed drops the followee's posts and reposts. A dropped post that
+// the user still reaches through another followee comes back at its latest
+// activity from them.
+func (s *timelineService) Unfollowed(userID, targetID uint) {
+	s.enqueue(func(ctx context.Context) {
+		removed, err := s.store.RemoveAuthor(ctx, userID, targetID)
+		if err != nil {
+			log.Printf("timeline: %v", err)
+			return
+		}
+		entries, err := s.feedRepo.FollowingActivity(userID, nil, removed)
+		if err != nil {
+			log.Printf("timeline: restore %d after unfollowing %d: %v", userID, targetID, err)
+			return
+		}
+		if err := s.store.Add(ctx, userID, timelineEntries(entries)); err != nil {
+			log.Printf("timeline: %v", err)
+		}
+	})
+}
+
+func (s *timelineService) Page(userID uint, media string, limit int, cursor *repository.Cursor) ([]repository.FeedItem, *repository.Cursor, error) {
+	ctx := context.Background()
+
+	// лента ещё не собиралась (новый пользователь, деплой, сброс Redis) —
+	// строим её из подписок; пустота не признак, fan-out мог уже что-то
+	// положить
+	if cursor == nil {
+		built, err := s.store.Built(ctx, userID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !built {
+			if err := s.rebuild(ctx, userID); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	celebrities, err := s.feedRepo.FollowedCelebrities(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the media filter and visibility are applied when entries are loaded,
+	// so a read can lose most of its entries; reads go on until the page is
+	// full or the timeline ends, at most timelineReadRounds times
+	q := repository.FeedQuery{ViewerID: userID, Media: media}
+	items := make([]repository.FeedItem, 0, limit)
+	next := cursor
+	for round := 0; round < timelineReadRounds; round++ {
+		entries, after, err := s.entries(ctx, userID, celebrities, limit, next)
+		if err != nil {
+			return nil, nil, err
+		}
+		got, err := s.feedRepo.Hydrate(q, entries)
+		if err != nil {
+			return nil, nil, err
+		}
+		items = append(items, got...)
+		next = after
+		if len(items) >= limit || next == nil {
+			break
+		}
+	}
+	if len(items) > limit {
+		items = items[:limit]
+		last := items[limit-1]
+		next = &repository.Cursor{Time: last.ActivityAt, ID: last.Post.ID}
+	}
+	return items, next, nil
+}
+
+// entries reads up to limit timeline entries older than cursor, merged with
+// the followed celebrities' activity, and the cursor after them, nil at the
+// end.
+func (s *timelineService) entries(
+	ctx context.Context,
+	userID uint,
+	celebrities []uint,
+	limit int,
+	cursor *repository.Cursor,
+) ([]repository.FeedEntry, *repository.Cursor, error) {
+	var before *timeline.Entry
+	if cursor != nil {
+		before = &timeline.Entry{PostID: cursor.ID, At: cursor.Time}
+	}
+
+	pushed, err := s.store.Page(ctx, userID, limit+1, before)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var pulled []repository.FeedEntry
+	if len(celebrities) > 0 {
+		if pulled, err = s.feedRepo.FollowingEntries(userID, celebrities, limit+1, cursor); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// each source was asked for one entry more than a page; a source that
+	// filled up may hold more entries older than its last one, so nothing
+	// older than the newest such bound can be served yet
+	var bound *repository.Cursor
+	if len(pushed) > limit {
+		last := pushed[len(pushed)-1]
+		bound = &repository.Cursor{Time: last.At, ID: last.PostID}
+	}
+	if len(pulled) > limit {
+		last := pulled[len(pulled)-1]
+		if bound == nil || olderThan(bound.Time, bound.ID, &repository.Cursor{Time: last.ActivityAt, ID: last.PostID}) {
+			bound = &repository.Cursor{Time: last.ActivityAt, ID: last.PostID}
+		}
+	}
+
+	if len(celebrities) > 0 {
+		if pushed, pulled, err = s.latestOnly(ctx, userID, celebrities, pushed, pulled); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	entries := mergeTimeline(pushed, pulled)
+	if bound != nil {
+		kept := entries[:0]
+		for _, e := range entries {
+			if !olderThan(e.ActivityAt, e.PostID, bound) {
+				kept = append(kept, e)
+			}
+		}
+		entries = kept
+	}
+	var next *repository.Cursor
+	if len(entries) > limit {
+		entries = entries[:limit]
+		last := entries[limit-1]
+		next = &repository.Cursor{Time: last.ActivityAt, ID: last.PostID}
+	} else if bound != nil {
+		next = bound
+	}
+
+	return entries, next, nil
+}
+
+// latestOnly drops the entries of posts that the other source holds at a
+// later activity (the pushed one on a tie), so that a post reachable both
+// ways is served once across all pages, not once per page.
+func (s *timelineService) latestOnly(
+	ctx context.Context,
+	userID uint,
+	celebrities []uint,
+	pushed []timeline.Entry,
+	pulled []repository.FeedEntry,
+) ([]timeline.Entry, []repository.FeedEntry, error) {
+
+	pushedIDs := make([]uint, 0, len(pushed))
+	for _, e := range pushed {
+		pushedIDs = append(pushedIDs, e.PostID)
+	}
+	pulledIDs := make([]uint, 0, len(pulled))
+	for _, e := range pulled {
+		pulledIDs = append(pulledIDs, e.PostID)
+	}
+
+	elsewhere, err := s.feedRepo.FollowingActivity(userID, celebrities, pushedIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	pulledAt := make(map[uint]time.Time, len(elsewhere))
+	for _, e := range elsewhere {
+		pulledAt[e.PostID] = e.ActivityAt
+	}
+	pushedAt, err := s.store.Activity(ctx, userID, pulledIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keptPushed := pushed[:0]
+	for _, e := range pushed {
+		if at, ok := pulledAt[e.PostID]; ok && at.After(e.At) {
+			continue
+		}
+		keptPushed = append(keptPushed, e)
+	}
+	keptPulled := pulled[:0]
+	for _, e := range pulled {
+		if at, ok := pushedAt[e.PostID]; ok && !at.Before(e.ActivityAt) {
+			continue
+		}
+		keptPulled = append(keptPulled, e)
+	}
+	return keptPushed, keptPulled, nil
+}
+
+// olderThan reports whether the entry (at, id) sorts after c in the feed.
+func olderThan(at time.Time, id uint, c *repository.Cursor) bool {
+	if !at.Equal(c.Time) {
+		return at.Before(c.Time)
+	}
+	return id < c.ID
+}
+
+// rebuild fills a timeline that was never built from the following feed.
+// Entries already there stay; the store keeps the latest one of each post.
+func (s *timelineService) rebuild(ctx context.Context, userID uint) error {
+	entries, err := s.feedRepo.FollowingEntries(userID, nil, TimelineMaxLen, nil)
+	if err != nil {
+		return err
+	}
+	if err := s.store.Add(ctx, userID, timelineEntries(entries)); err != nil {
+		return err
+	}
+	return s.store.MarkBuilt(ctx, userID)
+}
+
+func timelineEntry(e repository.FeedEntry) timeline.Entry {
+	te := timeline.Entry{PostID: e.PostID, AuthorID: e.AuthorID, At: e.ActivityAt}
+	if e.RepostedBy != nil {
+		te.RepostedBy = *e.RepostedBy
+	}
+	return te
+}
+
+func timelineEntries(entries []repository.FeedEntry) []timeline.Entry {
+	res := make([]timeline.Entry, 0, len(entries))
+	for _, e := range entries {
+		res = append(res, timelineEntry(e))
+	}
+	return res
+}
+
+// mergeTimeline orders pushed and pulled entries newest first, keeping a
+// post once should both sources still hold it at the same time.
+func mergeTimeline(pushed []timeline.Entry, pulled []repository.FeedEntry) []repository.FeedEntry {
+	all := make([]repository.FeedEntry, 0, len(pushed)+len(pulled))
+	for _, e := range pushed {
+		fe := repository.FeedEntry{PostID: e.PostID, AuthorID: e.AuthorID, ActivityAt: e.At}
+		if e.RepostedBy != 0 {
+			reposter := e.RepostedBy
+			fe.RepostedBy = &reposter
+		}
+		all = append(all, fe)
+	}
+	all = append(all, pulled...)
+
+	sort.SliceStable(all, func(i, j int) bool {
+		if !all[i].ActivityAt.Equal(all[j].ActivityAt) {
+			return all[i].ActivityAt.After(all[j].ActivityAt)
+		}
+		return all[i].PostID > all[j].PostID
+	})
+
+	seen := make(map[uint]bool, len(all))
+	res := all[:0]
+	for _, e := range all {
+		if seen[e.PostID] {
+			continue
+		}
+		seen[e.PostID] = true
+		res = append(res, e)
+	}
+	return res
+}
@@ -21,14 +21,23 @@ type UserService interface {
 	GetFollowers(userID uint) ([]dto.UserResponse, error)
 	GetFollowing(userID uint) ([]dto.UserResponse, error)
 	IsFollowing(userID uint, targetID uint) (bool, error)
+	Block(userID uint, targetID uint) error
+	Unblock(userID uint, targetID uint) error
+	GetBlocked(userID uint) ([]dto.UserShortDTO, error)
+	AddCloseFriend(userID uint, friendID uint) error
+	RemoveCloseFriend(userID uint, friendID uint) error
+	GetCloseFriends(userID uint) ([]dto.UserShortDTO, error)
 }
 
 type userService struct {
-	repo repository.UserRepository
+	repo         repository.UserRepository
+	notifySvc    NotificationService
+	analyticsSvc AnalyticsService
+	timelines    TimelineService
 }
 
-func NewUserService(repo repository.UserRepository) UserService {
-	return &userService{repo: repo}
+func NewUserService(repo repository.UserRepository, notifySvc NotificationService, analyticsSvc AnalyticsService, timelines TimelineService) UserService {
+	return &userService{repo: repo, notifySvc: notifySvc, analyticsSvc: analyticsSvc, timelines: timelines}
 }
 func (s *userService) IsFollowing(userID uint, targetID uint) (bool, error) {
 	if userID == 0 || targetID == 0 {
@@ -134,14 +143,34 @@ func (s *userService) Follow(userID uint, targetID uint) error {
 	if userID == targetID {
 		return fmt.Errorf("cannot follow yourself")
 	}
-	return s.repo.Follow(userID, targetID)
+	blocked, err := s.repo.IsBlockedEither(userID, targetID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return ErrForbidden
+	}
+	created, err := s.repo.Follow(userID, targetID)
+	if err != nil {
+		return err
+	}
+	if created {
+		s.analyticsSvc.TrackFollow(userID, targetID)
+		s.timelines.Followed(userID, targetID)
+	}
+	s.notifySvc.NotifyFollow(targetID, userID)
+	return nil
 }
 
 func (s *userService) Unfollow(userID uint, targetID uint) error {
 	if userID == 0 || targetID == 0 {
 		return fmt.Errorf("invalid ids")
 	}
-	return s.repo.Unfollow(userID, targetID)
+	if err := s.repo.Unfollow(userID, targetID); err != nil {
+		return err
+	}
+	s.timelines.Unfollowed(userID, targetID)
+	return nil
 }
 
 func (s *userService) GetFollowers(userID uint) ([]dto.UserResponse, error) {
@@ -187,3 +216,69 @@ func (s *userService) GetFollowing(userID uint) ([]dto.UserResponse, error) {
 
 	return resp, nil
 }
+
+func (s *userService) Block(userID uint, targetID uint) error {
+	if userID == 0 || targetID == 0 {
+		return fmt.Errorf("invalid ids")
+	}
+	if err := s.repo.Block(userID, targetID); err != nil {
+		return err
+	}
+	// блокировка снимает подписки в обе стороны
+	s.timelines.Unfollowed(userID, targetID)
+	s.timelines.Unfollowed(targetID, userID)
+	return nil
+}
+
+func (s *userService) Unblock(userID uint, targetID uint) error {
+	if userID == 0 || targetID == 0 {
+		return fmt.Errorf("invalid ids")
+	}
+	return s.repo.Unblock(userID, targetID)
+}
+
+func (s *userService) GetBlocked(userID uint) ([]dto.UserShortDTO, error) {
+	if userID == 0 {
+		return nil, fmt.Errorf("invalid id")
+	}
+	users, err := s.repo.GetBlocked(userID)
+	if err != nil {
+		return nil, fmt.Errorf("get blocked: %w", err)
+	}
+	return mapper.MapUsersToShortDTO(users), nil
+}
+
+func (s *userService) AddCloseFriend(userID uint, friendID uint) error {
+	if userID == 0 || friendID == 0 {
+		return fmt.Errorf("invalid ids")
+	}
+	if userID == friendID {
+		return fmt.Errorf("cannot add yourself")
+	}
+	blocked, err := s.repo.IsBlockedEither(userID, friendID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return ErrForbidden
+	}
+	return s.repo.AddCloseFriend(userID, friendID)
+}
+
+func (s *userService) RemoveCloseFriend(userID uint, friendID uint) error {
+	if userID == 0 || friendID == 0 {
+		return fmt.Errorf("invalid ids")
+	}
+	return s.repo.RemoveCloseFriend(userID, friendID)
+}
+
+func (s *userService) GetCloseFriends(userID uint) ([]dto.UserShortDTO, error) {
+	if userID == 0 {
+		return nil, fmt.Errorf("invalid id")
+	}
+	users, err := s.repo.GetCloseFriends(userID)
+	if err != nil {
+		return nil, fmt.Errorf("get close friends: %w", err)
+	}
+	return mapper.MapUsersToShortDTO(users), nil
+}
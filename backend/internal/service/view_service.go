@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"backend/internal/repository"
+	"backend/internal/viewcount"
+)
+
+const (
+	// ViewDedupWindow is how long a viewer's repeated views of one post count once.
+	ViewDedupWindow = time.Hour
+	// ViewFlushInterval is how often buffered views are written to Postgres.
+	ViewFlushInterval = 30 * time.Second
+	// MaxImpressionsBatch caps post ids in one impressions report.
+	MaxImpressionsBatch = 100
+)
+
+var ErrTooManyImpressions = errors.New("too many post ids in one batch")
+
+type ViewService interface {
+	// RecordView counts an open of the post page.
+	RecordView(postID, viewerID uint)
+	// RecordImpressions counts posts the client has shown in a feed.
+	RecordImpressions(viewerID uint, postIDs []uint) error
+	// Run flushes buffered views periodically until ctx is done.
+	Run(ctx context.Context)
+	Flush(ctx context.Context) error
+}
+
+type viewService struct {
+	store        viewcount.Store
+	postRepo     repository.PostRepository
+	analyticsSvc AnalyticsService
+}
+
+func NewViewService(store viewcount.Store, postRepo repository.PostRepository, analyticsSvc AnalyticsService) ViewService {
+	return &viewService{store: store, postRepo: postRepo, analyticsSvc: analyticsSvc}
+}
+
+func (s *viewService) RecordView(postID, viewerID uint) {
+	if postID == 0 || viewerID == 0 {
+		return
+	}
+	s.record(context.Background(), postID, viewerID)
+}
+
+func (s *viewService) RecordImpressions(viewerID uint, postIDs []uint) error {
+	if viewerID == 0 {
+		return ErrForbidden
+	}
+	if len(postIDs) > MaxImpressionsBatch {
+		return ErrTooManyImpressions
+	}
+
+	// просмотры недоступных зрителю постов не считаем
+	postIDs, err := s.postRepo.VisibleIDs(postIDs, viewerID)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	seen := make(map[uint]bool, len(postIDs))
+	for _, id := range postIDs {
+		if id == 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		s.record(ctx, id, viewerID)
+	}
+	return nil
+}
+
+// record doesn't fail the request: a lost view isn't worth an error response.
+func (s *viewService) record(ctx context.Context, postID, viewerID uint) {
+	fresh, err := s.store.MarkSeen(ctx, postID, viewerID)
+	if err != nil {
+		log.Printf("views: mark seen %d/%d: %v", postID, viewerID, err)
+		return
+	}
+	if !fresh {
+		return
+	}
+	if err := s.store.Incr(ctx, postID, 1); err != nil {
+		log.Printf("views: incr %d: %v", postID, err)
+	}
+	s.analyticsSvc.TrackView(postID, viewerID)
+}
+
+func (s *viewService) Run(ctx context.Context) {
+	ticker := time.NewTicker(ViewFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Flush(ctx); err != nil {
+				log.Printf("views: flush: %v", err)
+			}
+		}
+	}
+}
+
+func (s *viewService) Flush(ctx context.Context) error {
+	counts, err := s.store.Drain(ctx)
+	if err != nil {
+		return err
+	}
+	if len(counts) == 0 {
+		return nil
+	}
+
+	if err := s.postRepo.IncrementViews(counts); err != nil {
+		// возвращаем в буфер, чтобы не потерять просмотры до следующего flush
+		for postID, n := range counts {
+			if rerr := s.store.Incr(context.Background(), postID, n); rerr != nil {
+				log.Printf("views: requeue %d: %v", postID, rerr)
+			}
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package timeline
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	postgresTable       = "home_timelines"
+	postgresBuildsTable = "home_timeline_builds"
+)
+
+// fanoutBatch bounds the rows of one INSERT.
+const fanoutBatch = 1000
+
+type postgresRow struct {
+	UserID     uint
+	PostID     uint
+	AuthorID   uint
+	RepostedBy uint
+	ActivityAt time.Time
+}
+
+// PostgresStore keeps timelines in the home_timelines table, for setups
+// without Redis. The primary key (user_id, post_id) keeps a post once per
+// timeline. Writes don't trim; Trim is run periodically instead.
+type PostgresStore struct {
+	db     *gorm.DB
+	maxLen int
+}
+
+func NewPostgresStore(db *gorm.DB, maxLen int) *PostgresStore {
+	return &PostgresStore{db: db, maxLen: maxLen}
+}
+
+// insert upserts rows; an existing entry of the post is replaced only by a
+// newer one.
+func (s *PostgresStore) insert(ctx context.Context, rows []postgresRow) error {
+	return s.db.WithContext(ctx).Table(postgresTable).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "post_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"author_id", "reposted_by", "activity_at"}),
+			Where: clause.Where{Exprs: []clause.Expression{
+				clause.Expr{SQL: "home_timelines.activity_at < EXCLUDED.activity_at"},
+			}},
+		}).
+		CreateInBatches(rows, fanoutBatch).Error
+}
+
+func (s *PostgresStore) Add(ctx context.Context, userID uint, entries []Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	// one INSERT can't touch a row twice, so duplicates go first
+	entries = latestPerPost(entries)
+	rows := make([]postgresRow, 0, len(entries))
+	for _, e := range entries {
+		rows = append(rows, postgresRow{
+			UserID:     userID,
+			PostID:     e.PostID,
+			AuthorID:   e.AuthorID,
+			RepostedBy: e.RepostedBy,
+			ActivityAt: e.At,
+		})
+	}
+	if err := s.insert(ctx, rows); err != nil {
+		return fmt.Errorf("add to timeline %d: %w", userID, err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) Fanout(ctx context.Context, userIDs []uint, e Entry) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	rows := make([]postgresRow, 0, len(userIDs))
+	for _, id := range userIDs {
+		rows = append(rows, postgresRow{
+			UserID:     id,
+			PostID:     e.PostID,
+			AuthorID:   e.AuthorID,
+			RepostedBy: e.RepostedBy,
+			ActivityAt: e.At,
+		})
+	}
+	if err := s.insert(ctx, rows); err != nil {
+		return fmt.Errorf("fan out post %d: %w", e.PostID, err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) Page(ctx context.Context, userID uint, limit int, before *Entry) ([]Entry, error) {
+	q := s.db.WithContext(ctx).Table(postgresTable).
+		Where("user_id = ?", userID).
+		Order("activity_at DESC, post_id DESC").
+		Limit(limit)
+	if before != nil {
+		q = q.Where("(activity_at, post_id) < (?, ?)", before.At, before.PostID)
+	}
+
+	var rows []postgresRow
+	if err := q.Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("read timeline %d: %w", userID, err)
+	}
+	entries := make([]Entry, 0, len(rows))
+	for _, r := range rows {
+		entries = append(entries, Entry{
+			PostID:     r.PostID,
+			AuthorID:   r.AuthorID,
+			RepostedBy: r.RepostedBy,
+			At:         r.ActivityAt,
+		})
+	}
+	return entries, nil
+}
+
+func (s *PostgresStore) Activity(ctx context.Context, userID uint, postIDs []uint) (map[uint]time.Time, error) {
+	res := make(map[uint]time.Time, len(postIDs))
+	if len(postIDs) == 0 {
+		return res, nil
+	}
+	var rows []postgresRow
+	if err := s.db.WithContext(ctx).Table(postgresTable).
+		Select("post_id, activity_at").
+		Where("user_id = ? AND post_id IN ?", userID, postIDs).
+		Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("read timeline %d: %w", userID, err)
+	}
+	for _, r := range rows {
+		res[r.PostID] = r.ActivityAt
+	}
+	return res, nil
+}
+
+func (s *PostgresStore) RemoveAuthor(ctx context.Context, userID, authorID uint) ([]uint, error) {
+	var postIDs []uint
+	if err := s.db.WithContext(ctx).Raw(`
+		DELETE FROM home_timelines
+		WHERE user_id = ? AND ((author_id = ? AND reposted_by = 0) OR reposted_by = ?)
+		RETURNING post_id`,
+		userID, authorID, authorID,
+	).Scan(&postIDs).Error; err != nil {
+		return nil, fmt.Errorf("remove author %d from timeline %d: %w", authorID, userID, err)
+	}
+	return postIDs, nil
+}
+
+func (s *PostgresStore) RemoveRepost(ctx context.Context, userIDs []uint, e Entry) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	if err := s.db.WithContext(ctx).Exec(`
+		DELETE FROM home_timelines
+		WHERE user_id IN ? AND post_id = ? AND reposted_by = ?`,
+		userIDs, e.PostID, e.RepostedBy,
+	).Error; err != nil {
+		return fmt.Errorf("remove repost of %d: %w", e.PostID, err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) Trim(ctx context.Context) error {
+	if err := s.db.WithContext(ctx).Exec(`
+		DELETE FROM home_timelines h
+		USING (
+			SELECT user_id, post_id FROM (
+				SELECT user_id, post_id,
+				       ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY activity_at DESC, post_id DESC) AS rn
+				FROM home_timelines
+			) ranked
+			WHERE rn > ?
+		) old
+		WHERE h.user_id = old.user_id AND h.post_id = old.post_id`,
+		s.maxLen,
+	).Error; err != nil {
+		return fmt.Errorf("trim timelines: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) Built(ctx context.Context, userID uint) (bool, error) {
+	var count int64
+	if err := s.db.WithContext(ctx).Table(postgresBuildsTable).
+		Where("user_id = ?", userID).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("read timeline %d: %w", userID, err)
+	}
+	return count > 0, nil
+}
+
+func (s *PostgresStore) MarkBuilt(ctx context.Context, userID uint) error {
+	if err := s.db.WithContext(ctx).Exec(`
+		INSERT INTO home_timeline_builds (user_id, built_at) VALUES (?, NOW())
+		ON CONFLICT (user_id) DO NOTHING`,
+		userID,
+	).Error; err != nil {
+		return fmt.Errorf("mark timeline %d built: %w", userID, err)
+	}
+	return nil
+}
@@ -0,0 +1,314 @@
+package timeline
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/redis"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	redisKeyPrefix   = "timeline:"
+	redisIndexSuffix = ":posts"
+	redisBuiltSuffix = ":built"
+)
+
+// redisAddScript puts post/score/member triples into a timeline, replacing
+// the post's older member and ignoring entries that aren't newer, then trims
+// the set to its maximum length. KEYS: the set and its post index; ARGV:
+// the maximum length followed by the triples.
+var redisAddScript = goredis.NewScript(`
+local max = tonumber(ARGV[1])
+for i = 2, #ARGV, 3 do
+	local post, score, member = ARGV[i], tonumber(ARGV[i + 1]), ARGV[i + 2]
+	local old = redis.call('HGET', KEYS[2], post)
+	local newer = true
+	if old then
+		local cur = redis.call('ZSCORE', KEYS[1], old)
+		if cur and tonumber(cur) >= score then
+			newer = false
+		else
+			redis.call('ZREM', KEYS[1], old)
+		end
+	end
+	if newer then
+		redis.call('ZADD', KEYS[1], score, member)
+		redis.call('HSET', KEYS[2], post, member)
+	end
+end
+local n = redis.call('ZCARD', KEYS[1])
+if n > max then
+	for _, m in ipairs(redis.call('ZRANGE', KEYS[1], 0, n - max - 1)) do
+		redis.call('ZREM', KEYS[1], m)
+		local post = string.match(m, '^(%d+):')
+		if post and redis.call('HGET', KEYS[2], post) == m then
+			redis.call('HDEL', KEYS[2], post)
+		end
+	end
+end
+return 0`)
+
+// redisRemoveScript drops members from a timeline and from its post index.
+// KEYS: the set and its post index; ARGV: the members.
+var redisRemoveScript = goredis.NewScript(`
+for _, m in ipairs(ARGV) do
+	redis.call('ZREM', KEYS[1], m)
+	local post = string.match(m, '^(%d+):')
+	if post and redis.call('HGET', KEYS[2], post) == m then
+		redis.call('HDEL', KEYS[2], post)
+	end
+end
+return 0`)
+
+// RedisStore keeps each timeline in a sorted set scored by the entry time
+// in microseconds, which a float64 holds exactly. Members are
+// "<post id>:<author id>:<reposted by>"; a hash next to the set maps each
+// post to its member, so a post has one member at a time. Both are only
+// changed by the scripts above.
+type RedisStore struct {
+	rdb    *redis.Client
+	maxLen int
+}
+
+func NewRedisStore(rdb *redis.Client, maxLen int) *RedisStore {
+	return &RedisStore{rdb: rdb, maxLen: maxLen}
+}
+
+func redisKey(userID uint) string {
+	return redisKeyPrefix + strconv.FormatUint(uint64(userID), 10)
+}
+
+func redisKeys(userID uint) []string {
+	key := redisKey(userID)
+	return []string{key, key + redisIndexSuffix}
+}
+
+func redisMember(e Entry) string {
+	return fmt.Sprintf("%d:%d:%d", e.PostID, e.AuthorID, e.RepostedBy)
+}
+
+func parseRedisEntry(z goredis.Z) (Entry, bool) {
+	member, _ := z.Member.(string)
+	parts := strings.Split(member, ":")
+	if len(parts) != 3 {
+		return Entry{}, false
+	}
+	var ids [3]uint64
+	for i, p := range parts {
+		v, err := strconv.ParseUint(p, 10, 64)
+		if err != nil {
+			return Entry{}, false
+		}
+		ids[i] = v
+	}
+	return Entry{
+		PostID:     uint(ids[0]),
+		AuthorID:   uint(ids[1]),
+		RepostedBy: uint(ids[2]),
+		At:         time.UnixMicro(int64(z.Score)),
+	}, true
+}
+
+func (s *RedisStore) addArgs(entries []Entry) []interface{} {
+	args := make([]interface{}, 0, 1+3*len(entries))
+	args = append(args, s.maxLen)
+	for _, e := range entries {
+		args = append(args, e.PostID, e.At.UnixMicro(), redisMember(e))
+	}
+	return args
+}
+
+func (s *RedisStore) Add(ctx context.Context, userID uint, entries []Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	if err := redisAddScript.Run(ctx, s.rdb, redisKeys(userID), s.addArgs(entries)...).Err(); err != nil {
+		return fmt.Errorf("add to timeline %d: %w", userID, err)
+	}
+	return nil
+}
+
+// Fanout loads the script once and runs it by hash in a pipeline, so the
+// script body isn't sent once per follower.
+func (s *RedisStore) Fanout(ctx context.Context, userIDs []uint, e Entry) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	if err := redisAddScript.Load(ctx, s.rdb).Err(); err != nil {
+		return fmt.Errorf("fan out post %d: %w", e.PostID, err)
+	}
+	args := s.addArgs([]Entry{e})
+	pipe := s.rdb.Pipeline()
+	for _, id := range userIDs {
+		redisAddScript.EvalSha(ctx, pipe, redisKeys(id), args...)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("fan out post %d: %w", e.PostID, err)
+	}
+	return nil
+}
+
+// Page reads by score and then adds the entries sharing a score with the
+// cursor or with the last row read: Redis orders those by member text, not
+// by post id, so a page could otherwise cut such a group in the wrong place.
+func (s *RedisStore) Page(ctx context.Context, userID uint, limit int, before *Entry) ([]Entry, error) {
+	key := redisKey(userID)
+
+	max := "+inf"
+	if before != nil {
+		max = "(" + strconv.FormatInt(before.At.UnixMicro(), 10)
+	}
+	rows, err := s.rdb.ZRevRangeByScoreWithScores(ctx, key, &goredis.ZRangeBy{
+		Min:   "-inf",
+		Max:   max,
+		Count: int64(limit),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("read timeline %d: %w", userID, err)
+	}
+
+	var tieScores []int64
+	if before != nil {
+		tieScores = append(tieScores, before.At.UnixMicro())
+	}
+	if len(rows) > 0 {
+		tieScores = append(tieScores, int64(rows[len(rows)-1].Score))
+	}
+	for _, score := range tieScores {
+		v := strconv.FormatInt(score, 10)
+		ties, err := s.rdb.ZRangeByScoreWithScores(ctx, key, &goredis.ZRangeBy{Min: v, Max: v}).Result()
+		if err != nil {
+			return nil, fmt.Errorf("read timeline %d: %w", userID, err)
+		}
+		rows = append(rows, ties...)
+	}
+
+	seen := make(map[string]bool, len(rows))
+	entries := make([]Entry, 0, len(rows))
+	for _, z := range rows {
+		e, ok := parseRedisEntry(z)
+		if !ok || seen[redisMember(e)] {
+			continue
+		}
+		seen[redisMember(e)] = true
+		if before != nil && !Before(e, *before) {
+			continue
+		}
+		entries = append(entries, e)
+	}
+
+	sortNewestFirst(entries)
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+func (s *RedisStore) Activity(ctx context.Context, userID uint, postIDs []uint) (map[uint]time.Time, error) {
+	res := make(map[uint]time.Time, len(postIDs))
+	if len(postIDs) == 0 {
+		return res, nil
+	}
+	keys := redisKeys(userID)
+	fields := make([]string, 0, len(postIDs))
+	for _, id := range postIDs {
+		fields = append(fields, strconv.FormatUint(uint64(id), 10))
+	}
+	members, err := s.rdb.HMGet(ctx, keys[1], fields...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("read timeline %d: %w", userID, err)
+	}
+
+	pipe := s.rdb.Pipeline()
+	scores := make(map[uint]*goredis.FloatCmd, len(members))
+	for i, m := range members {
+		if member, ok := m.(string); ok {
+			scores[postIDs[i]] = pipe.ZScore(ctx, keys[0], member)
+		}
+	}
+	if len(scores) == 0 {
+		return res, nil
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != goredis.Nil {
+		return nil, fmt.Errorf("read timeline %d: %w", userID, err)
+	}
+	for id, cmd := range scores {
+		if score, err := cmd.Result(); err == nil {
+			res[id] = time.UnixMicro(int64(score))
+		}
+	}
+	return res, nil
+}
+
+func (s *RedisStore) RemoveAuthor(ctx context.Context, userID, authorID uint) ([]uint, error) {
+	keys := redisKeys(userID)
+	rows, err := s.rdb.ZRangeWithScores(ctx, keys[0], 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("read timeline %d: %w", userID, err)
+	}
+
+	var members []interface{}
+	var postIDs []uint
+	for _, z := range rows {
+		e, ok := parseRedisEntry(z)
+		if !ok {
+			continue
+		}
+		if (e.AuthorID == authorID && e.RepostedBy == 0) || e.RepostedBy == authorID {
+			members = append(members, z.Member)
+			postIDs = append(postIDs, e.PostID)
+		}
+	}
+	if len(members) == 0 {
+		return nil, nil
+	}
+	if err := redisRemoveScript.Run(ctx, s.rdb, keys, members...).Err(); err != nil {
+		return nil, fmt.Errorf("remove author %d from timeline %d: %w", authorID, userID, err)
+	}
+	return postIDs, nil
+}
+
+func (s *RedisStore) RemoveRepost(ctx context.Context, userIDs []uint, e Entry) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	if err := redisRemoveScript.Load(ctx, s.rdb).Err(); err != nil {
+		return fmt.Errorf("remove repost of %d: %w", e.PostID, err)
+	}
+	member := redisMember(e)
+	pipe := s.rdb.Pipeline()
+	for _, id := range userIDs {
+		redisRemoveScript.EvalSha(ctx, pipe, redisKeys(id), member)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("remove repost of %d: %w", e.PostID, err)
+	}
+	return nil
+}
+
+// Built checks a marker key kept without a TTL, so a flush that empties the
+// timelines drops it too and the next read rebuilds.
+func (s *RedisStore) Built(ctx context.Context, userID uint) (bool, error) {
+	n, err := s.rdb.Exists(ctx, redisKey(userID)+redisBuiltSuffix).Result()
+	if err != nil {
+		return false, fmt.Errorf("read timeline %d: %w", userID, err)
+	}
+	return n > 0, nil
+}
+
+func (s *RedisStore) MarkBuilt(ctx context.Context, userID uint) error {
+	if err := s.rdb.Set(ctx, redisKey(userID)+redisBuiltSuffix, 1, 0).Err(); err != nil {
+		return fmt.Errorf("mark timeline %d built: %w", userID, err)
+	}
+	return nil
+}
+
+// Trim has nothing to do: every write trims its timeline.
+func (s *RedisStore) Trim(ctx context.Context) error {
+	return nil
+}
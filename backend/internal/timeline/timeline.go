@@ -0,0 +1,74 @@
+package timeline
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// Entry is one item of a materialized home timeline.
+type Entry struct {
+	PostID   uint
+	AuthorID uint
+	// RepostedBy is the followee whose repost brought the post in, 0 when
+	// it came from the author.
+	RepostedBy uint
+	At         time.Time
+}
+
+// Store keeps per-user home timelines, newest first, cut to a maximum
+// length. Entries are ordered by (At, PostID) and a post is there once, at
+// its latest activity: adding an entry for a post already in the timeline
+// replaces the older one and is ignored if it isn't newer.
+type Store interface {
+	// Add puts entries into one user's timeline.
+	Add(ctx context.Context, userID uint, entries []Entry) error
+	// Fanout puts one entry into the timelines of many users.
+	Fanout(ctx context.Context, userIDs []uint, e Entry) error
+	// Page returns up to limit entries older than before, or the newest
+	// ones when before is nil.
+	Page(ctx context.Context, userID uint, limit int, before *Entry) ([]Entry, error)
+	// Activity returns the time the given posts have in a timeline; posts
+	// that aren't there are left out.
+	Activity(ctx context.Context, userID uint, postIDs []uint) (map[uint]time.Time, error)
+	// RemoveAuthor drops the author's posts and reposts from a timeline and
+	// returns the ids of the posts it dropped.
+	RemoveAuthor(ctx context.Context, userID, authorID uint) ([]uint, error)
+	// RemoveRepost drops the repost entry e, matched on post, author and
+	// reposter, from the given timelines.
+	RemoveRepost(ctx context.Context, userIDs []uint, e Entry) error
+	// Trim cuts timelines that grew past the maximum length.
+	Trim(ctx context.Context) error
+	// Built reports whether the user's timeline has been filled from the
+	// following feed since the store was last emptied.
+	Built(ctx context.Context, userID uint) (bool, error)
+	// MarkBuilt records that the user's timeline has been filled.
+	MarkBuilt(ctx context.Context, userID uint) error
+}
+
+// Before reports whether a sorts after b in a timeline, i.e. is older.
+func Before(a, b Entry) bool {
+	if !a.At.Equal(b.At) {
+		return a.At.Before(b.At)
+	}
+	return a.PostID < b.PostID
+}
+
+// latestPerPost keeps the newest entry of every post, in no particular order.
+func latestPerPost(entries []Entry) []Entry {
+	latest := make(map[uint]Entry, len(entries))
+	for _, e := range entries {
+		if cur, ok := latest[e.PostID]; !ok || e.At.After(cur.At) {
+			latest[e.PostID] = e
+		}
+	}
+	res := make([]Entry, 0, len(latest))
+	for _, e := range latest {
+		res = append(res, e)
+	}
+	return res
+}
+
+func sortNewestFirst(entries []Entry) {
+	sort.SliceStable(entries, func(i, j int) bool { return Before(entries[j], entries[i]) })
+}
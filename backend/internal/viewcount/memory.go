@@ -0,0 +1,68 @@
+package viewcount
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type seenKey struct {
+	postID   uint
+	viewerID uint
+}
+
+// MemoryStore keeps everything in process; fine for a single replica.
+type MemoryStore struct {
+	window time.Duration
+
+	mu      sync.Mutex
+	seen    map[seenKey]time.Time
+	pending map[uint]int64
+}
+
+func NewMemoryStore(window time.Duration) *MemoryStore {
+	return &MemoryStore{
+		window:  window,
+		seen:    make(map[seenKey]time.Time),
+		pending: make(map[uint]int64),
+	}
+}
+
+func (s *MemoryStore) MarkSeen(_ context.Context, postID, viewerID uint) (bool, error) {
+	now := time.Now()
+	key := seenKey{postID: postID, viewerID: viewerID}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if exp, ok := s.seen[key]; ok && now.Before(exp) {
+		return false, nil
+	}
+	s.seen[key] = now.Add(s.window)
+	return true, nil
+}
+
+func (s *MemoryStore) Incr(_ context.Context, postID uint, n int64) error {
+	s.mu.Lock()
+	s.pending[postID] += n
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MemoryStore) Drain(_ context.Context) (map[uint]int64, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// заодно чистим истёкшие отметки, чтобы map не рос бесконечно
+	for k, exp := range s.seen {
+		if !now.Before(exp) {
+			delete(s.seen, k)
+		}
+	}
+
+	res := s.pending
+	s.pending = make(map[uint]int64)
+	return res, nil
+}
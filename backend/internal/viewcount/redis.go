@@ -0,0 +1,78 @@
+package viewcount
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/redis"
+
+	"github.com/google/uuid"
+)
+
+const (
+	redisSeenPrefix  = "views:seen:"
+	redisPendingKey  = "views:pending"
+	redisDrainPrefix = "views:draining:"
+)
+
+// RedisStore shares the dedup window and the pending counters between
+// replicas, so any of them can flush.
+type RedisStore struct {
+	rdb    *redis.Client
+	window time.Duration
+}
+
+func NewRedisStore(rdb *redis.Client, window time.Duration) *RedisStore {
+	return &RedisStore{rdb: rdb, window: window}
+}
+
+func (s *RedisStore) MarkSeen(ctx context.Context, postID, viewerID uint) (bool, error) {
+	key := fmt.Sprintf("%s%d:%d", redisSeenPrefix, postID, viewerID)
+	ok, err := s.rdb.SetNX(ctx, key, 1, s.window).Result()
+	if err != nil {
+		return false, fmt.Errorf("setnx %s: %w", key, err)
+	}
+	return ok, nil
+}
+
+func (s *RedisStore) Incr(ctx context.Context, postID uint, n int64) error {
+	if err := s.rdb.HIncrBy(ctx, redisPendingKey, strconv.FormatUint(uint64(postID), 10), n).Err(); err != nil {
+		return fmt.Errorf("hincrby views: %w", err)
+	}
+	return nil
+}
+
+// Drain renames the pending hash first, so increments that arrive while we
+// read it go to a fresh hash and aren't lost.
+func (s *RedisStore) Drain(ctx context.Context) (map[uint]int64, error) {
+	res := make(map[uint]int64)
+
+	key := redisDrainPrefix + uuid.New().String()
+	if err := s.rdb.Rename(ctx, redisPendingKey, key).Err(); err != nil {
+		if strings.Contains(err.Error(), "no such key") {
+			return res, nil
+		}
+		return nil, fmt.Errorf("rename pending views: %w", err)
+	}
+	defer s.rdb.Del(ctx, key)
+
+	raw, err := s.rdb.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("hgetall pending views: %w", err)
+	}
+	for k, v := range raw {
+		id, err := strconv.ParseUint(k, 10, 64)
+		if err != nil {
+			continue
+		}
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			continue
+		}
+		res[uint(id)] = n
+	}
+	return res, nil
+}
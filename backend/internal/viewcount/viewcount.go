@@ -0,0 +1,14 @@
+package viewcount
+
+import "context"
+
+// Store buffers post views between flushes to Postgres and remembers who has
+// already been counted recently.
+type Store interface {
+	// MarkSeen reports whether viewerID hasn't been counted for postID within
+	// the dedup window, and marks them as counted.
+	MarkSeen(ctx context.Context, postID, viewerID uint) (bool, error)
+	Incr(ctx context.Context, postID uint, n int64) error
+	// Drain takes all pending increments out of the buffer.
+	Drain(ctx context.Context) (map[uint]int64, error)
+}
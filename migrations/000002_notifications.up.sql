@@ -0,0 +1,32 @@
+CREATE TABLE notifications (
+                               id SERIAL PRIMARY KEY,
+                               user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                               actor_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+
+                               type TEXT NOT NULL,
+                               post_id INT REFERENCES posts(id) ON DELETE CASCADE,
+                               comment_id INT REFERENCES comments(id) ON DELETE CASCADE,
+
+                               actors_count INT NOT NULL DEFAULT 0,
+                               is_read BOOLEAN NOT NULL DEFAULT FALSE,
+
+                               created_at TIMESTAMPTZ DEFAULT NOW(),
+                               updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_notifications_user_updated ON notifications(user_id, updated_at DESC, id DESC);
+CREATE INDEX idx_notifications_user_unread ON notifications(user_id) WHERE is_read = FALSE;
+
+-- одна непрочитанная группа на (получатель, тип, цель) — в неё складываются новые акторы
+CREATE UNIQUE INDEX uniq_notifications_unread_group
+    ON notifications(user_id, type, COALESCE(post_id, 0), COALESCE(comment_id, 0))
+    WHERE is_read = FALSE;
+
+CREATE TABLE notification_actors (
+                                     id SERIAL PRIMARY KEY,
+                                     notification_id INT NOT NULL REFERENCES notifications(id) ON DELETE CASCADE,
+                                     actor_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                     created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX uniq_notification_actors ON notification_actors(notification_id, actor_id);
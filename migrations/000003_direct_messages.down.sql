@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS message_deletions;
+DROP TABLE IF EXISTS message_files;
+DROP TABLE IF EXISTS messages;
+DROP TABLE IF EXISTS conversation_members;
+DROP TABLE IF EXISTS conversations;
+DROP TABLE IF EXISTS blocks;
+ALTER TABLE users DROP COLUMN IF EXISTS dm_following_only;
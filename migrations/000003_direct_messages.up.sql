@@ -0,0 +1,66 @@
+ALTER TABLE users ADD COLUMN dm_following_only BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE blocks (
+                        id SERIAL PRIMARY KEY,
+                        user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                        blocked_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                        created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX uniq_blocks ON blocks(user_id, blocked_id);
+CREATE INDEX idx_blocks_blocked_id ON blocks(blocked_id);
+
+CREATE TABLE conversations (
+                               id SERIAL PRIMARY KEY,
+                               is_group BOOLEAN NOT NULL DEFAULT FALSE,
+                               title TEXT,
+                               created_by INT REFERENCES users(id) ON DELETE SET NULL,
+
+                               -- "<min user id>:<max user id>" для личных диалогов, чтобы не плодить дубли
+                               direct_key TEXT,
+
+                               last_message_at TIMESTAMPTZ DEFAULT NOW(),
+                               created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX uniq_conversations_direct_key ON conversations(direct_key) WHERE direct_key IS NOT NULL;
+
+CREATE TABLE conversation_members (
+                                      id SERIAL PRIMARY KEY,
+                                      conversation_id INT NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+                                      user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                      last_read_message_id INT NOT NULL DEFAULT 0,
+                                      joined_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX uniq_conversation_members ON conversation_members(conversation_id, user_id);
+CREATE INDEX idx_conversation_members_user_id ON conversation_members(user_id);
+
+CREATE TABLE messages (
+                          id SERIAL PRIMARY KEY,
+                          conversation_id INT NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+                          sender_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+
+                          text TEXT NOT NULL DEFAULT '',
+                          deleted_for_everyone BOOLEAN NOT NULL DEFAULT FALSE,
+
+                          created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_messages_conversation_id ON messages(conversation_id, id DESC);
+
+CREATE TABLE message_files (
+                               id SERIAL PRIMARY KEY,
+                               message_id INT NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+                               url TEXT NOT NULL
+);
+
+CREATE INDEX idx_message_files_message_id ON message_files(message_id);
+
+CREATE TABLE message_deletions (
+                                   id SERIAL PRIMARY KEY,
+                                   message_id INT NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+                                   user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX uniq_message_deletions ON message_deletions(message_id, user_id);
@@ -0,0 +1,20 @@
+CREATE TABLE bookmark_collections (
+                                      id SERIAL PRIMARY KEY,
+                                      user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                      name TEXT NOT NULL,
+                                      created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX uniq_bookmark_collections ON bookmark_collections(user_id, name);
+
+CREATE TABLE bookmarks (
+                           id SERIAL PRIMARY KEY,
+                           user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                           post_id INT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+                           collection_id INT REFERENCES bookmark_collections(id) ON DELETE SET NULL,
+                           created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX uniq_bookmarks ON bookmarks(user_id, post_id);
+CREATE INDEX idx_bookmarks_user_created ON bookmarks(user_id, created_at DESC, id DESC);
+CREATE INDEX idx_bookmarks_collection_id ON bookmarks(collection_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS reposts;
+ALTER TABLE posts DROP COLUMN IF EXISTS quoted_post_id;
@@ -0,0 +1,13 @@
+-- без внешнего ключа: цитата переживает удаление оригинала и показывается как недоступная
+ALTER TABLE posts ADD COLUMN quoted_post_id INT;
+CREATE INDEX idx_posts_quoted_post_id ON posts(quoted_post_id);
+
+CREATE TABLE reposts (
+                         id SERIAL PRIMARY KEY,
+                         post_id INT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+                         user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                         created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX uniq_reposts ON reposts(post_id, user_id);
+CREATE INDEX idx_reposts_user_created ON reposts(user_id, created_at DESC);
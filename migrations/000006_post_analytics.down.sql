@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS post_viewers;
+DROP TABLE IF EXISTS post_stats;
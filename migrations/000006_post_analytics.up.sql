@@ -0,0 +1,25 @@
+-- почасовые агрегаты по посту, пишутся пачками из AnalyticsService
+CREATE TABLE post_stats (
+                            post_id INT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+                            bucket TIMESTAMPTZ NOT NULL,
+                            views INT NOT NULL DEFAULT 0,
+                            likes INT NOT NULL DEFAULT 0,
+                            comments INT NOT NULL DEFAULT 0,
+                            bookmarks INT NOT NULL DEFAULT 0,
+                            follows_gained INT NOT NULL DEFAULT 0,
+                            PRIMARY KEY (post_id, bucket)
+);
+
+CREATE INDEX idx_post_stats_bucket ON post_stats(bucket);
+
+-- уникальные зрители; last_seen_at нужен, чтобы приписать подписку посту
+CREATE TABLE post_viewers (
+                              post_id INT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+                              user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                              first_seen_at TIMESTAMPTZ NOT NULL,
+                              last_seen_at TIMESTAMPTZ NOT NULL,
+                              PRIMARY KEY (post_id, user_id)
+);
+
+CREATE INDEX idx_post_viewers_post_first_seen ON post_viewers(post_id, first_seen_at);
+CREATE INDEX idx_post_viewers_user_last_seen ON post_viewers(user_id, last_seen_at DESC);
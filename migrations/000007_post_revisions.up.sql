@@ -0,0 +1,11 @@
+ALTER TABLE posts ADD COLUMN edited_at TIMESTAMPTZ;
+
+-- каждая сохранённая версия текста поста; исходная версия пишется при первой правке
+CREATE TABLE post_revisions (
+                                id SERIAL PRIMARY KEY,
+                                post_id INT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+                                description TEXT,
+                                created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_post_revisions_post_id ON post_revisions(post_id, created_at DESC, id DESC);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_posts_user_status;
+DROP INDEX IF EXISTS idx_posts_scheduled;
+ALTER TABLE posts DROP COLUMN IF EXISTS publish_at;
+ALTER TABLE posts DROP COLUMN IF EXISTS status;
@@ -0,0 +1,6 @@
+ALTER TABLE posts ADD COLUMN status TEXT NOT NULL DEFAULT 'published';
+ALTER TABLE posts ADD COLUMN publish_at TIMESTAMPTZ;
+
+-- планировщик выбирает только запланированные посты, индекс маленький
+CREATE INDEX idx_posts_scheduled ON posts(publish_at) WHERE status = 'scheduled';
+CREATE INDEX idx_posts_user_status ON posts(user_id, status, created_at DESC);
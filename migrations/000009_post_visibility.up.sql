@@ -0,0 +1,11 @@
+ALTER TABLE posts ADD COLUMN visibility TEXT NOT NULL DEFAULT 'public';
+
+CREATE TABLE close_friends (
+                               id SERIAL PRIMARY KEY,
+                               user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                               friend_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                               created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX uniq_close_friends ON close_friends(user_id, friend_id);
+CREATE INDEX idx_close_friends_friend_id ON close_friends(friend_id);
@@ -0,0 +1,38 @@
+CREATE TABLE polls (
+                       id SERIAL PRIMARY KEY,
+                       post_id INT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+                       multiple BOOLEAN NOT NULL DEFAULT FALSE,
+                       anonymous BOOLEAN NOT NULL DEFAULT FALSE,
+                       ends_at TIMESTAMPTZ,
+                       created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX uniq_polls_post_id ON polls(post_id);
+
+CREATE TABLE poll_options (
+                              id SERIAL PRIMARY KEY,
+                              poll_id INT NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+                              position INT NOT NULL,
+                              text TEXT NOT NULL
+);
+
+CREATE UNIQUE INDEX uniq_poll_options_position ON poll_options(poll_id, position);
+
+-- один бюллетень на пользователя; выбранные варианты лежат в poll_vote_options
+CREATE TABLE poll_votes (
+                            id SERIAL PRIMARY KEY,
+                            poll_id INT NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+                            user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                            created_at TIMESTAMPTZ DEFAULT NOW(),
+                            updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX uniq_poll_votes ON poll_votes(poll_id, user_id);
+
+CREATE TABLE poll_vote_options (
+                                   vote_id INT NOT NULL REFERENCES poll_votes(id) ON DELETE CASCADE,
+                                   option_id INT NOT NULL REFERENCES poll_options(id) ON DELETE CASCADE,
+                                   PRIMARY KEY (vote_id, option_id)
+);
+
+CREATE INDEX idx_poll_vote_options_option_id ON poll_vote_options(option_id);
@@ -0,0 +1,25 @@
+CREATE TABLE stories (
+                         id SERIAL PRIMARY KEY,
+                         user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                         media_url TEXT NOT NULL,
+                         media_type TEXT NOT NULL,
+                         caption TEXT NOT NULL DEFAULT '',
+                         expires_at TIMESTAMPTZ NOT NULL,
+                         created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_stories_user_expires ON stories(user_id, expires_at);
+-- для фоновой чистки просроченных историй
+CREATE INDEX idx_stories_expires_at ON stories(expires_at);
+
+CREATE TABLE story_views (
+                             story_id INT NOT NULL REFERENCES stories(id) ON DELETE CASCADE,
+                             user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                             viewed_at TIMESTAMPTZ DEFAULT NOW(),
+                             PRIMARY KEY (story_id, user_id)
+);
+
+CREATE INDEX idx_story_views_user_id ON story_views(user_id);
+
+-- ответ на историю приходит в личку; после удаления истории сообщение остаётся
+ALTER TABLE messages ADD COLUMN story_id INT REFERENCES stories(id) ON DELETE SET NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE comment_likes DROP COLUMN IF EXISTS reaction;
+ALTER TABLE post_likes DROP COLUMN IF EXISTS reaction;
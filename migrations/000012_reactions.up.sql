@@ -0,0 +1,4 @@
+-- лайк становится одной из реакций: существующие строки получают 'like',
+-- и старые клиенты со своими /like продолжают работать
+ALTER TABLE post_likes ADD COLUMN reaction TEXT NOT NULL DEFAULT 'like';
+ALTER TABLE comment_likes ADD COLUMN reaction TEXT NOT NULL DEFAULT 'like';
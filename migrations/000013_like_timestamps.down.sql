@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_comment_likes_comment_created;
+DROP INDEX IF EXISTS idx_post_likes_post_created;
+ALTER TABLE comment_likes DROP COLUMN IF EXISTS created_at;
+ALTER TABLE post_likes DROP COLUMN IF EXISTS created_at;
@@ -0,0 +1,6 @@
+-- у старых лайков настоящего времени нет, они получают время миграции
+ALTER TABLE post_likes ADD COLUMN created_at TIMESTAMPTZ DEFAULT NOW();
+ALTER TABLE comment_likes ADD COLUMN created_at TIMESTAMPTZ DEFAULT NOW();
+
+CREATE INDEX idx_post_likes_post_created ON post_likes(post_id, created_at DESC, id DESC);
+CREATE INDEX idx_comment_likes_comment_created ON comment_likes(comment_id, created_at DESC, id DESC);
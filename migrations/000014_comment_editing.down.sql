@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN IF EXISTS is_moderator;
+ALTER TABLE comments DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE comments DROP COLUMN IF EXISTS edited_at;
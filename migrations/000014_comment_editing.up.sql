@@ -0,0 +1,5 @@
+ALTER TABLE comments ADD COLUMN edited_at TIMESTAMPTZ;
+-- комментарий с ответами не удаляется, а остаётся заглушкой "[deleted]"
+ALTER TABLE comments ADD COLUMN deleted_at TIMESTAMPTZ;
+
+ALTER TABLE users ADD COLUMN is_moderator BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_comments_parent_created;
+DROP INDEX IF EXISTS idx_comments_post_roots;
@@ -0,0 +1,3 @@
+-- keyset-пагинация корневых комментариев и ответов
+CREATE INDEX idx_comments_post_roots ON comments(post_id, created_at DESC, id DESC) WHERE parent_id IS NULL;
+CREATE INDEX idx_comments_parent_created ON comments(parent_id, created_at, id);
@@ -0,0 +1,3 @@
+ALTER TABLE comments DROP COLUMN IF EXISTS hidden_at;
+ALTER TABLE posts DROP COLUMN IF EXISTS pinned_comment_id;
+ALTER TABLE posts DROP COLUMN IF EXISTS comment_policy;
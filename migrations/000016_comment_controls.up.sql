@@ -0,0 +1,6 @@
+-- everyone, followers или off; проверяется при добавлении комментария
+ALTER TABLE posts ADD COLUMN comment_policy TEXT NOT NULL DEFAULT 'everyone';
+ALTER TABLE posts ADD COLUMN pinned_comment_id INT REFERENCES comments(id) ON DELETE SET NULL;
+
+-- скрытый комментарий видят только его автор и автор поста
+ALTER TABLE comments ADD COLUMN hidden_at TIMESTAMPTZ;
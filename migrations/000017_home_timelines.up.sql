@@ -0,0 +1,13 @@
+-- материализованная домашняя лента: fan-out при публикации кладёт сюда пост
+-- всем подписчикам; используется, когда TIMELINE_STORE=postgres.
+-- reposted_by = 0, если пост пришёл от самого автора, а не через репост
+CREATE TABLE home_timelines (
+                                user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                post_id INT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+                                author_id INT NOT NULL,
+                                reposted_by INT NOT NULL DEFAULT 0,
+                                activity_at TIMESTAMPTZ NOT NULL,
+                                PRIMARY KEY (user_id, post_id, reposted_by)
+);
+
+CREATE INDEX idx_home_timelines_user_activity ON home_timelines(user_id, activity_at DESC, post_id DESC);
@@ -0,0 +1,12 @@
+-- предрасчитанный набор трендовых постов для explore; фоновая задача
+-- пересобирает его целиком, запросы страниц только читают
+-- category: photo, video или text
+CREATE TABLE trending_posts (
+                                post_id INT PRIMARY KEY REFERENCES posts(id) ON DELETE CASCADE,
+                                category TEXT NOT NULL,
+                                score DOUBLE PRECISION NOT NULL,
+                                computed_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_trending_posts_score ON trending_posts(score DESC, post_id DESC);
+CREATE INDEX idx_trending_posts_category_score ON trending_posts(category, score DESC, post_id DESC);
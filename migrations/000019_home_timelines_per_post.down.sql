@@ -0,0 +1,2 @@
+ALTER TABLE home_timelines DROP CONSTRAINT home_timelines_pkey;
+ALTER TABLE home_timelines ADD PRIMARY KEY (user_id, post_id, reposted_by);
@@ -0,0 +1,10 @@
+-- пост попадает в ленту один раз, с последней активностью (пост или репост),
+-- иначе он повторялся бы на разных страницах
+DELETE FROM home_timelines h
+USING home_timelines newer
+WHERE newer.user_id = h.user_id
+  AND newer.post_id = h.post_id
+  AND (newer.activity_at, newer.reposted_by) > (h.activity_at, h.reposted_by);
+
+ALTER TABLE home_timelines DROP CONSTRAINT home_timelines_pkey;
+ALTER TABLE home_timelines ADD PRIMARY KEY (user_id, post_id);
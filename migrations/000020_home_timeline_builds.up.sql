@@ -0,0 +1,7 @@
+-- пользователи, чья лента уже собрана из подписок (TIMELINE_STORE=postgres).
+-- Без отметки лента пересобирается при первом чтении, даже если fan-out
+-- успел что-то в неё положить
+CREATE TABLE home_timeline_builds (
+                                      user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+                                      built_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,10 @@
+-- каждая сохранённая версия текста комментария; исходная версия пишется при
+-- первой правке, как и у постов
+CREATE TABLE comment_revisions (
+                                   id SERIAL PRIMARY KEY,
+                                   comment_id INT NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+                                   text TEXT NOT NULL,
+                                   created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_comment_revisions_comment_id ON comment_revisions(comment_id, created_at DESC, id DESC);
@@ -0,0 +1,4 @@
+-- знаменитости (от CelebrityFollowers подписчиков) не раскладывают посты по
+-- лентам, их подмешивают при чтении. Флаг пересчитывается фоном, чтобы
+-- чтение ленты не считало подписчиков всех, на кого подписан пользователь
+ALTER TABLE users ADD COLUMN is_celebrity BOOLEAN NOT NULL DEFAULT FALSE;